@@ -0,0 +1,41 @@
+package main
+
+import (
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Off by default, as it tells where the accents
+// of the answer are once its letters are typed
+var accentCompletion bool
+
+// Letter without its accents, ß and the like stay as they are
+func baseLetter(letter rune) rune {
+	decomposed := []rune(norm.NFD.String(string(letter)))
+	if len(decomposed) > 1 && unicode.Is(unicode.Mn, decomposed[1]) {
+		return decomposed[0]
+	}
+	return letter
+}
+
+// Puts the accent on the letter just typed when everything
+// before it matches the answer and only the accent is missing
+func (screen *quizScreen) completeAccentBeforeCursor() {
+	if !accentCompletion {
+		return
+	}
+	value := []rune(screen.inputField.Value())
+	answer := []rune(norm.NFC.String(screen.question.correctAnswer))
+	position := screen.inputField.Position()
+	if position == 0 || position > len(answer) {
+		return
+	}
+	typed, expected := value[position-1], answer[position-1]
+	if typed == expected || baseLetter(expected) != typed || string(value[:position-1]) != string(answer[:position-1]) {
+		return
+	}
+	value[position-1] = expected
+	screen.inputField.SetValue(string(value))
+	screen.inputField.SetCursor(position)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+const (
+	hundredCorrectAnswers = 100
+	monthStreakDays       = 30
+	// Shorter sessions without mistakes are too easy to count
+	perfectSessionAnswers = 25
+)
+
+type achievement struct {
+	// Key in the achievements file
+	name        string
+	title       string
+	description string
+	// Checked after every answer until unlocked
+	reached func(screen quizScreen) bool
+}
+
+var achievements = [...]achievement{
+	{
+		name:        "first_hundred",
+		title:       "First hundred",
+		description: "answer 100 questions correctly",
+		reached: func(screen quizScreen) bool {
+			var correct uint32
+			for _, record := range screen.history.days {
+				correct += record.correct
+			}
+			return correct >= hundredCorrectAnswers
+		},
+	},
+	{
+		name:        "month_streak",
+		title:       "Month streak",
+		description: "practice 30 days in a row",
+		reached: func(screen quizScreen) bool {
+			return screen.history.dailyStreak(time.Now()) >= monthStreakDays
+		},
+	},
+	{
+		name:        "verb_mastered",
+		title:       "Verb mastered",
+		description: "master every form of a verb",
+		reached: func(screen quizScreen) bool {
+			for _, form := range screen.statistics.conjugation(screen.question.prompt.Verb) {
+				if screen.statistics.Record(form.prompt).Streak < masteredStreak {
+					return false
+				}
+			}
+			return true
+		},
+	},
+	{
+		name:        "perfect_session",
+		title:       "Perfect session",
+		description: "answer 25 questions in a session without a mistake",
+		reached: func(screen quizScreen) bool {
+			return screen.wrongAnswers == 0 && screen.correctAnswers >= perfectSessionAnswers
+		},
+	},
+}
+
+type achievementsTOML struct {
+	Unlocked map[string]time.Time
+}
+
+// When each achievement was unlocked, none until the session is loaded
+var unlockedAchievements = map[string]time.Time{}
+
+func parseAchievements(bytes []byte) (map[string]time.Time, error) {
+	var achievementsTOML achievementsTOML
+	if err := toml.Unmarshal(bytes, &achievementsTOML); err != nil {
+		return nil, err
+	}
+	if achievementsTOML.Unlocked == nil {
+		return map[string]time.Time{}, nil
+	}
+	return achievementsTOML.Unlocked, nil
+}
+
+// Nil when the file can not be parsed, so that it is not overwritten
+func readAchievements() map[string]time.Time {
+	bytes, err := os.ReadFile(achievementsPath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Error("Failed to read achievements file", "path", achievementsPath, "error", err)
+		}
+		return map[string]time.Time{}
+	}
+	unlocked, err := parseAchievements(bytes)
+	if err != nil {
+		slog.Error("Failed to parse achievements file", "path", achievementsPath, "error", err)
+		return nil
+	}
+	return unlocked
+}
+
+func saveAchievements() {
+	if readOnly {
+		return
+	}
+	// Another session may have unlocked some meanwhile
+	saved := readAchievements()
+	if saved == nil {
+		slog.Error("Achievements not saved, the file can not be parsed", "path", achievementsPath)
+		return
+	}
+	for name, unlockedAt := range saved {
+		if _, exists := unlockedAchievements[name]; !exists {
+			unlockedAchievements[name] = unlockedAt
+		}
+	}
+	bytes, err := toml.Marshal(achievementsTOML{unlockedAchievements})
+	if err != nil {
+		logFatal("Unachievable TOML encoding error", "error", err)
+	}
+	if err := writeFileAtomic(achievementsPath, bytes, 0); err != nil {
+		slog.Error("Failed to save achievements", "path", achievementsPath, "error", err)
+	}
+}
+
+// Called after every answer, a toast announces what was unlocked
+func (screen quizScreen) unlockAchievements() {
+	unlocked := false
+	for _, achievement := range achievements {
+		if _, done := unlockedAchievements[achievement.name]; done || !achievement.reached(screen) {
+			continue
+		}
+		unlockedAchievements[achievement.name] = time.Now().Truncate(time.Second)
+		slog.Info("Unlocked achievement", "achievement", achievement.name)
+		notify("unlocked: " + achievement.title)
+		unlocked = true
+	}
+	if unlocked {
+		saveAchievements()
+	}
+}
+
+type achievementsScreen struct {
+	listPosition
+}
+
+func (screen achievementsScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen achievementsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back):
+			return screen, popScreen
+		case key.Matches(msg, keys.Down):
+			screen.scrollDown(len(achievements), listShownRows())
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.scrollUp()
+			return screen, nil
+		}
+	case tea.MouseMsg:
+		screen.listPosition.handleMouse(msg, len(achievements), listShownRows())
+		return screen, nil
+	}
+	return screen, nil
+}
+
+// Locked ones are muted, unlocked ones show the day they were unlocked
+func (screen achievementsScreen) renderAchievementEntry(achievement achievement, selected bool) string {
+	style := promptStatsEntryStyle
+	status := questionStatsStyle.Render("locked")
+	if unlockedAt, done := unlockedAchievements[achievement.name]; done {
+		status = background.Bold(selected).Foreground(accentColor).Render(formatTimeAgo(unlockedAt, time.Now()))
+	} else {
+		style = style.Foreground(mutedColor)
+	}
+	entry := achievement.title
+	if selected {
+		entry = "> " + entry
+	}
+	return style.
+		Bold(selected).
+		Italic(selected).
+		Width(boxWidth-lipgloss.Width(status)).
+		AlignHorizontal(lipgloss.Left).
+		Render(entry) +
+		status
+}
+
+var achievementsScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Up}, action: "up"},
+	{bindings: []*key.Binding{&keys.Down}, action: "down"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen achievementsScreen) View() string {
+	screen.fit(listShownRows())
+	footer := renderHelpRow(achievementsScreenHelp[:])
+	unlocked := 0
+	for _, achievement := range achievements {
+		if _, done := unlockedAchievements[achievement.name]; done {
+			unlocked++
+		}
+	}
+	title := fmt.Sprintf("Achievements, %d unlocked", unlocked)
+	lines := []string{renderListTitle(title, screen.listPosition, len(achievements)), ""}
+	for row := 0; row < listShownRows(); row++ {
+		index := screen.firstShownIndex + row
+		if index >= len(achievements) {
+			break
+		}
+		lines = append(lines, screen.renderAchievementEntry(achievements[index], row == screen.selectedRow))
+	}
+	footer = lipgloss.JoinVertical(
+		lipgloss.Left,
+		questionStatsAlignStyle.Render(questionStatsStyle.Inline(true).MaxWidth(boxWidth).Render(
+			achievements[screen.selectedIndex()].description,
+		)),
+		footer,
+	)
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), footer)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Answers given before a question answered wrong is asked again
+const againGap = 3
+
+// Question answered wrong this session and not answered right since
+type againCard struct {
+	prompt prompt
+	// Answers of the session given when it was last answered wrong
+	missedAt uint32
+	misses   int
+}
+
+func (session *session) answers() uint32 {
+	return session.correctAnswers + session.wrongAnswers
+}
+
+func (session *session) pileAgain(prompt prompt) {
+	for i := range session.againPile {
+		if session.againPile[i].prompt == prompt {
+			session.againPile[i].missedAt = session.answers()
+			session.againPile[i].misses++
+			return
+		}
+	}
+	session.againPile = append(session.againPile, againCard{prompt, session.answers(), 1})
+}
+
+// True when the answer took the last card off the pile
+func (session *session) clearAgain(prompt prompt) bool {
+	for i, card := range session.againPile {
+		if card.prompt == prompt {
+			session.againPile = append(session.againPile[:i:i], session.againPile[i+1:]...)
+			session.againCleared = append(session.againCleared, card)
+			return len(session.againPile) == 0
+		}
+	}
+	return false
+}
+
+// Card waiting the longest, once enough other answers were given,
+// or at once when the session is otherwise over
+func (session *session) nextAgain(otherwiseOver bool) (prompt, bool) {
+	for _, card := range session.againPile {
+		if otherwiseOver || session.answers()-card.missedAt >= againGap {
+			return card.prompt, true
+		}
+	}
+	return prompt{}, false
+}
+
+// Shown once the pile is cleared, with every card that was on it
+type againSummaryScreen struct {
+	cards []againCard
+}
+
+func (screen againSummaryScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen againSummaryScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Submit, keys.Back) {
+			return screen, popScreen
+		}
+	}
+	return screen, nil
+}
+
+var againSummaryHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit, &keys.Back}, action: "continue"},
+}
+
+func (screen againSummaryScreen) View() string {
+	lines := []string{renderMenuTitle("Again pile cleared"), ""}
+	for i, card := range screen.cards {
+		if i == listShownRows() {
+			lines = append(lines, questionStatsStyle.Render(fmt.Sprintf("and %d more", len(screen.cards)-i)))
+			break
+		}
+		misses := strconv.Itoa(card.misses) + " wrong"
+		lines = append(lines, promptStatsEntryStyle.Width(boxWidth-lipgloss.Width(misses)).Render(card.prompt.String())+questionStatsStyle.Render(misses))
+	}
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), renderHelpRow(againSummaryHelp[:]))
+}
+
+func renderAgainPile(session *session) string {
+	if len(session.againPile) == 0 {
+		return ""
+	}
+	return strconv.Itoa(len(session.againPile)) + " again" + symbols.helpSeparator
+}
+
+func againPileDetail(cards int) string {
+	if cards == 1 {
+		return "1 question is still on the again pile."
+	}
+	return fmt.Sprintf("%d questions are still on the again pile.", cards)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+const animationFrameDuration = 50 * time.Millisecond
+
+// Border pulses on correct answers, and the box shakes
+// from side to side on wrong ones while pulsing too
+var shakeOffsets = [...]int{-1, 1, -1, 1, -1, 1, 0, 0}
+
+var (
+	animations = true
+	// Nil when nothing is animated
+	answerAnimation *animation
+	// Frames of an animation replaced by a newer one are dropped
+	animationCount int
+)
+
+type animation struct {
+	correct bool
+	frame   int
+}
+
+type animationFrameMessage struct {
+	animation int
+}
+
+func nextAnimationFrame() tea.Cmd {
+	current := animationCount
+	return tea.Tick(animationFrameDuration, func(time.Time) tea.Msg { return animationFrameMessage{current} })
+}
+
+func startAnimation(correct bool) tea.Cmd {
+	if !animations {
+		return nil
+	}
+	animationCount++
+	answerAnimation = &animation{correct: correct}
+	return nextAnimationFrame()
+}
+
+func advanceAnimation(msg animationFrameMessage) tea.Cmd {
+	if answerAnimation == nil || msg.animation != animationCount {
+		return nil
+	}
+	answerAnimation.frame++
+	if answerAnimation.frame >= len(shakeOffsets) || !animations {
+		answerAnimation = nil
+		return nil
+	}
+	return nextAnimationFrame()
+}
+
+// Every other frame has the border in the color of the answer
+func (animation animation) borderColor() lipgloss.TerminalColor {
+	if animation.frame%2 == 1 {
+		return accentColor
+	}
+	if animation.correct {
+		return highlightColor
+	}
+	return wrongColor
+}
+
+// Margins on both sides keep the width the same, so
+// that centering in the terminal does not move the box
+func (animation animation) apply(box lipgloss.Style) lipgloss.Style {
+	box = box.BorderForeground(animation.borderColor())
+	if animation.correct {
+		return box
+	}
+	offset := shakeOffsets[animation.frame]
+	return box.MarginLeft(1 + offset).MarginRight(1 - offset).MarginBackground(backgroundColor)
+}
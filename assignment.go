@@ -0,0 +1,511 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Fixed set of questions a teacher hands out. Students get it
+// without the answers, the teacher keeps a copy with them to grade
+// by, so results are graded by the teacher's deck and nobody else's.
+type assignment struct {
+	// Made up for every assignment, so that results
+	// of another one are not graded against it
+	ID        string               `toml:"id"`
+	Title     string               `toml:"title"`
+	Due       toml.LocalDate       `toml:"due"`
+	Questions []assignmentQuestion `toml:"questions"`
+}
+
+type assignmentQuestion struct {
+	FormClue string `toml:"form_clue"`
+	Verb     string `toml:"verb"`
+	// Only in the teacher's copy
+	Answer string `toml:"answer,omitempty"`
+}
+
+// What a student sends back, a summary and every answer as it was
+// typed, signed with the key of the student, see signResult. The
+// answers are checked against the teacher's copy when grading, and
+// submitting late is judged by the time of submission.
+type assignmentResult struct {
+	Assignment string    `toml:"assignment"`
+	Title      string    `toml:"title"`
+	Student    string    `toml:"student"`
+	Submitted  time.Time `toml:"submitted"`
+	// Questions given an answer rather than left blank
+	Answered  int                `toml:"answered"`
+	Seconds   int                `toml:"seconds"`
+	Answers   []assignmentAnswer `toml:"answers"`
+	PublicKey string             `toml:"public_key"`
+	Signature string             `toml:"signature"`
+}
+
+type assignmentAnswer struct {
+	FormClue string `toml:"form_clue"`
+	Verb     string `toml:"verb"`
+	Given    string `toml:"given"`
+}
+
+const defaultAssignmentDays = 7
+
+func localDate(t time.Time) toml.LocalDate {
+	return toml.LocalDate{Year: t.Year(), Month: int(t.Month()), Day: t.Day()}
+}
+
+func (question assignmentQuestion) prompt() prompt {
+	return prompt{FormClue: question.FormClue, Verb: question.Verb}
+}
+
+// Copy handed out to students, which is the teacher's without the answers
+func (task assignment) withoutAnswers() assignment {
+	task.Questions = slices.Clone(task.Questions)
+	for i := range task.Questions {
+		task.Questions[i].Answer = ""
+	}
+	return task
+}
+
+func parseAssignment(data []byte, withAnswers bool) (assignment, error) {
+	var task assignment
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&task); err != nil {
+		return assignment{}, err
+	}
+	switch {
+	case len(task.Questions) == 0:
+		return assignment{}, errors.New("assignment has no questions")
+	case task.ID == "":
+		return assignment{}, errors.New("id is not set")
+	}
+	for i, question := range task.Questions {
+		switch {
+		case question.FormClue == "" || question.Verb == "":
+			return assignment{}, fmt.Errorf("question %d is incomplete", i+1)
+		case withAnswers && question.Answer == "":
+			return assignment{}, fmt.Errorf("question %d has no answer, grading needs the key of the assignment", i+1)
+		}
+	}
+	return task, nil
+}
+
+func loadAssignment(path string, withAnswers bool) (assignment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return assignment{}, err
+	}
+	task, err := parseAssignment(data, withAnswers)
+	if err != nil {
+		return assignment{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return task, nil
+}
+
+func parseAssignmentResult(data []byte) (assignmentResult, error) {
+	var result assignmentResult
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return assignmentResult{}, err
+	}
+	return result, nil
+}
+
+// Result as the teacher's copy has it. A question answered more
+// than once counts with its first answer, a missing one is wrong.
+type gradedResult struct {
+	correct  int
+	late     bool
+	mistakes []assignmentMistake
+}
+
+type assignmentMistake struct {
+	question assignmentQuestion
+	given    string
+}
+
+func (task assignment) grade(result assignmentResult) gradedResult {
+	given := map[prompt]string{}
+	for _, answer := range result.Answers {
+		prompt := prompt{FormClue: answer.FormClue, Verb: answer.Verb}
+		if _, exists := given[prompt]; !exists {
+			given[prompt] = answer.Given
+		}
+	}
+	var graded gradedResult
+	for _, question := range task.Questions {
+		answer, answered := given[question.prompt()]
+		if answered && sameText(strings.TrimSpace(question.Answer), strings.TrimSpace(answer)) {
+			graded.correct++
+			continue
+		}
+		graded.mistakes = append(graded.mistakes, assignmentMistake{question, answer})
+	}
+	submitted := localDate(result.Submitted.In(time.Local))
+	graded.late = submitted.AsTime(time.Local).After(task.Due.AsTime(time.Local))
+	return graded
+}
+
+// Made on the first assignment and kept with the state, the teacher
+// learns the public half from the first result graded
+func studentKeyPath() string {
+	return filepath.Join(stateDirectory(), "student_key")
+}
+
+func loadStudentKey() (ed25519.PrivateKey, error) {
+	path := studentKeyPath()
+	data, err := os.ReadFile(path)
+	if err == nil {
+		seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("%s is not a key, delete it to make a new one", path)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key.Seed())+"\n"), 0600); err != nil {
+		return nil, err
+	}
+	slog.Info("Made student key", "path", path)
+	return key, nil
+}
+
+// Signature of the result as it is encoded without it. Only the
+// student has the private key, so a result can neither be edited
+// nor made up in the name of a student the teacher graded before.
+func signResult(result assignmentResult, key ed25519.PrivateKey) (string, error) {
+	result.PublicKey = hex.EncodeToString(key.Public().(ed25519.PublicKey))
+	result.Signature = ""
+	data, err := toml.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(ed25519.Sign(key, data)), nil
+}
+
+func verifyResult(result assignmentResult) bool {
+	publicKey, err := hex.DecodeString(result.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	signature, err := hex.DecodeString(result.Signature)
+	if err != nil {
+		return false
+	}
+	result.Signature = ""
+	data, err := toml.Marshal(result)
+	return err == nil && ed25519.Verify(publicKey, data, signature)
+}
+
+// Public keys of the students graded so far, by name. The first
+// result of a student registers their key, like the first key of an
+// SSH user, forgetting it is done by removing it from the file.
+func studentKeysPath() string {
+	return filepath.Join(stateDirectory(), "students.toml")
+}
+
+func loadStudentKeys() (map[string]string, error) {
+	keys := map[string]string{}
+	data, err := os.ReadFile(studentKeysPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return keys, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := toml.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("%s: %w", studentKeysPath(), err)
+	}
+	return keys, nil
+}
+
+// Given to students who have no profile, their account name
+func studentName() string {
+	if profileName != "" {
+		return profileName
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return "anonymous"
+}
+
+// Spaces and path separators would make an awkward file name
+func assignmentResultPath(path string, student string) string {
+	name := strings.Map(func(character rune) rune {
+		if strings.ContainsRune(` /\:`, character) {
+			return '-'
+		}
+		return character
+	}, student)
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + "-" + name + ".toml"
+}
+
+// Questions are picked the way worksheets pick random ones, from the
+// deck of the teacher. The file is handed out, the copy with the
+// answers next to it, named like the key of a worksheet, is kept.
+func runAssign(args []string) {
+	flags, options := newFlagSet("assign", "file")
+	count := flags.Int("count", defaultWorksheetQuestions, "number of `questions` in the assignment")
+	title := flags.String("title", "", "`title` students see, the file name by default")
+	due := flags.String("due", "", "due `date` as YYYY-MM-DD, a week from today by default")
+	parseFlags(flags, args)
+	expectArguments(flags, 1)
+	options.readsOnly = true
+	options.apply()
+	path := flags.Arg(0)
+	if *count <= 0 {
+		fmt.Fprintln(os.Stderr, "Count must be positive")
+		exit(usageError)
+	}
+	dueDate := localDate(time.Now().AddDate(0, 0, defaultAssignmentDays))
+	if *due != "" {
+		parsed, err := time.ParseInLocation(historyDateLayout, *due, time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid due date %q, expected YYYY-MM-DD\n", *due)
+			exit(usageError)
+		}
+		dueDate = localDate(parsed)
+	}
+	if *title == "" {
+		*title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		logFatal("Failed to make assignment id", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(internalError)
+	}
+
+	database := read_database()
+	statistics := database.loadStatistics()
+	task := assignment{ID: hex.EncodeToString(id), Title: *title, Due: dueDate}
+	for _, prompt := range selectWorksheetPrompts(statistics, *count, false) {
+		task.Questions = append(task.Questions, assignmentQuestion{
+			FormClue: prompt.FormClue,
+			Verb:     prompt.Verb,
+			Answer:   statistics.Answer(prompt),
+		})
+	}
+	keyPath := answerKeyPath(path)
+	for path, task := range map[string]assignment{keyPath: task, path: task.withoutAnswers()} {
+		data, err := toml.Marshal(task)
+		if err == nil {
+			err = os.WriteFile(path, data, 0644)
+		}
+		if err != nil {
+			logFatal("Failed to write assignment", "path", path, "error", err)
+			fmt.Fprintf(os.Stderr, "Failed to write assignment: %v\n", err)
+			exit(assignmentError)
+		}
+	}
+	slog.Info("Wrote assignment", "path", path, "answers", keyPath, "questions", len(task.Questions), "due", task.Due)
+	fmt.Printf("Wrote %d questions due %s to %s, hand it out and keep %s to grade with\n", len(task.Questions), task.Due, path, keyPath)
+}
+
+// Asks every question of the assignment once, in order, over plain lines
+// like the drill. Statistics are left alone, this is a test, not practice,
+// and answers are only checked when the teacher grades them.
+func runAssignment(args []string) {
+	flags, options := newFlagSet("assignment", "file")
+	name := flags.String("name", "", "`name` the teacher knows the student by, the profile or account name by default")
+	output := flags.String("result", "", "`file` to write the result to, named after the assignment and the student by default")
+	parseFlags(flags, args)
+	expectArguments(flags, 1)
+	options.apply()
+	path := flags.Arg(0)
+	task, err := loadAssignment(path, false)
+	if err != nil {
+		logFatal("Failed to load assignment", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(assignmentError)
+	}
+	if *name == "" {
+		*name = studentName()
+	}
+	if *output == "" {
+		*output = assignmentResultPath(path, *name)
+	}
+	key, err := loadStudentKey()
+	if err != nil {
+		logFatal("Failed to load student key", "path", studentKeyPath(), "error", err)
+		fmt.Fprintf(os.Stderr, "Failed to load the key results are signed with: %v\n", err)
+		exit(assignmentError)
+	}
+	// Verbs are in the language the answers are in
+	useCollation(detectLanguage(specialCharactersOf(func(yield func(string) bool) {
+		for _, question := range task.Questions {
+			if !yield(question.Verb) {
+				return
+			}
+		}
+	})))
+
+	fmt.Printf("%s: %d questions, due %s. Type each answer and press enter.\n", task.Title, len(task.Questions), task.Due)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	lines := readLines(os.Stdin)
+	result := assignmentResult{Assignment: task.ID, Title: task.Title, Student: *name}
+	started := time.Now()
+	for _, question := range task.Questions {
+		fmt.Printf("%s: ", question.prompt())
+		var given string
+		select {
+		case line, more := <-lines:
+			if !more {
+				fmt.Println()
+				fmt.Fprintln(os.Stderr, "Input ended before the last question, nothing was written")
+				exit(assignmentError)
+			}
+			given = strings.TrimSpace(line)
+			if given != "" {
+				result.Answered++
+			}
+		case sig := <-signals:
+			slog.Info("Received signal, quitting", "signal", sig)
+			fmt.Println()
+			fmt.Fprintln(os.Stderr, "Assignment was interrupted, nothing was written")
+			exit(assignmentError)
+		}
+		result.Answers = append(result.Answers, assignmentAnswer{
+			FormClue: question.FormClue,
+			Verb:     question.Verb,
+			Given:    given,
+		})
+	}
+
+	now := time.Now()
+	result.Submitted = now.Truncate(time.Second)
+	result.Seconds = int(now.Sub(started).Seconds())
+	result.PublicKey = hex.EncodeToString(key.Public().(ed25519.PublicKey))
+	result.Signature, err = signResult(result, key)
+	var data []byte
+	if err == nil {
+		data, err = toml.Marshal(result)
+	}
+	if err == nil {
+		err = os.WriteFile(*output, data, 0644)
+	}
+	if err != nil {
+		logFatal("Failed to write assignment result", "path", *output, "error", err)
+		fmt.Fprintf(os.Stderr, "Failed to write the result: %v\n", err)
+		exit(assignmentError)
+	}
+	slog.Info("Finished assignment", "title", task.Title, "answered", result.Answered, "path", *output)
+	fmt.Printf(
+		"%d of %d questions answered in %s, send %s to your teacher\n",
+		result.Answered, len(task.Questions), formatStudyTime(time.Duration(result.Seconds)*time.Second), *output,
+	)
+}
+
+// Grades results by the teacher's copy of the assignment and prints them.
+// Results of another assignment, edited ones and ones signed with another
+// key than the student's earlier results fail. The answers are graded
+// here rather than trusted, but the time of submission is as the
+// student's clock had it, check it against when the result arrived.
+func runGrade(args []string) {
+	flags, options := newFlagSet("grade", "key result...")
+	parseFlags(flags, args)
+	if flags.NArg() < 2 {
+		expectArguments(flags, 2)
+	}
+	options.apply()
+	task, err := loadAssignment(flags.Arg(0), true)
+	if err != nil {
+		logFatal("Failed to load assignment", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(assignmentError)
+	}
+	studentKeys, err := loadStudentKeys()
+	if err != nil {
+		logFatal("Failed to load student keys", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(assignmentError)
+	}
+	registered := 0
+	failed := false
+	for _, path := range flags.Args()[1:] {
+		data, err := os.ReadFile(path)
+		var result assignmentResult
+		if err == nil {
+			result, err = parseAssignmentResult(data)
+		}
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		case result.Assignment != task.ID:
+			fmt.Fprintf(os.Stderr, "%s: result is of another assignment\n", path)
+			failed = true
+			continue
+		case !verifyResult(result):
+			fmt.Fprintf(os.Stderr, "%s: signature does not match, the result was edited\n", path)
+			failed = true
+			continue
+		}
+		known, isKnown := studentKeys[result.Student]
+		if isKnown && known != result.PublicKey {
+			fmt.Fprintf(os.Stderr, "%s: signed with another key than earlier results of %s\n", path, result.Student)
+			failed = true
+			continue
+		}
+		if !isKnown {
+			studentKeys[result.Student] = result.PublicKey
+			registered++
+			fmt.Fprintf(os.Stderr, "%s: first result of %s, their key is remembered\n", path, result.Student)
+		}
+		graded := task.grade(result)
+		late := ""
+		if graded.late {
+			late = ", late"
+		}
+		fmt.Printf("%s: %d/%d correct, submitted %s%s\n", result.Student, graded.correct, len(task.Questions), result.Submitted.Local().Format(historyDateLayout), late)
+		for _, mistake := range graded.mistakes {
+			question := mistake.question
+			fmt.Printf("  %s + %s: %q instead of %q\n", question.FormClue, question.Verb, mistake.given, question.Answer)
+		}
+	}
+	// Nothing is remembered in read-only mode, as with SSH keys
+	if registered > 0 && !readOnly {
+		data, err := toml.Marshal(studentKeys)
+		if err == nil {
+			err = writeFileAtomic(studentKeysPath(), data, 0)
+		}
+		if err != nil {
+			logFatal("Failed to remember student keys", "path", studentKeysPath(), "error", err)
+			fmt.Fprintf(os.Stderr, "Failed to remember the keys of new students: %v\n", err)
+			exit(assignmentError)
+		}
+		slog.Info("Remembered student keys", "students", registered, "path", studentKeysPath())
+	}
+	if failed {
+		exit(validationError)
+	}
+}
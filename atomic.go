@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Number of previous versions kept as path.1, path.2, ...
+// where path.1 is the most recent one
+const statisticsBackups = 3
+
+func backupPath(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
+
+func copyFile(from string, to string) error {
+	source, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	destination, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(destination, source); err != nil {
+		destination.Close()
+		return err
+	}
+	return destination.Close()
+}
+
+// Shifts path.i to path.i+1 dropping the oldest one,
+// then makes path.1 a copy of the current file
+func rotateBackups(path string, backups int) error {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	for i := backups - 1; i >= 1; i-- {
+		err := os.Rename(backupPath(path, i), backupPath(path, i+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	newest := backupPath(path, 1)
+	if err := os.Remove(newest); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// Hard link is instant and keeps the original untouched,
+	// but is not supported by every file system
+	if err := os.Link(path, newest); err == nil {
+		return nil
+	}
+	return copyFile(path, newest)
+}
+
+// Writers of the same file within the process take turns, so that
+// the backups rotated by one save do not interleave with another.
+// Other processes are kept out by the instance lock.
+var fileWriteLocks sync.Map
+
+// Returns the unlock
+func lockFileWrites(path string) func() {
+	lock, _ := fileWriteLocks.LoadOrStore(path, &sync.Mutex{})
+	mutex := lock.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// Writes into a temporary file in the same directory and renames it
+// over the target, so a crash never leaves a partially written file
+func writeFileAtomic(path string, bytes []byte, backups int) error {
+	defer lockFileWrites(path)()
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	defer os.Remove(tempPath) // No-op once renamed
+	if _, err := temp.Write(bytes); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tempPath, 0644); err != nil {
+		return err
+	}
+	if backups > 0 {
+		if err := rotateBackups(path, backups); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tempPath, path)
+}
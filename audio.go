@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Command playing audio clips, given the clip as its last argument.
+// Empty picks the first of defaultAudioPlayers which is installed.
+var audioPlayer string
+
+var defaultAudioPlayers = [][]string{
+	{"mpv", "--no-video", "--really-quiet"},
+	{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet"},
+	{"afplay"},
+	{"paplay"},
+	{"aplay", "-q"},
+}
+
+// Clips of the deck by prompt, none until the session is loaded
+var promptAudio map[prompt]string
+
+// Files are taken relative to the deck, URLs are left as they are
+func resolveDeckFile(file string) string {
+	if !isLocalFile(file) || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(filepath.Dir(wordDatabasePath), file)
+}
+
+func isLocalFile(file string) bool {
+	return !strings.Contains(file, "://")
+}
+
+// Media files of the deck by prompt, given how to find the file of a form
+func (database wordDatabase) mediaFiles(file func(verbIndex int, clueIndex int) string) map[prompt]string {
+	files := make(map[prompt]string)
+	for verbIndex, verb := range database.Verbs {
+		for clueIndex, clue := range database.FormClues {
+			if name := file(verbIndex, clueIndex); name != "" {
+				files[prompt{FormClue: clue, Verb: verb}] = resolveDeckFile(name)
+			}
+		}
+	}
+	return files
+}
+
+func audioPlayerCommand(clip string) (*exec.Cmd, error) {
+	if player := strings.Fields(audioPlayer); len(player) > 0 {
+		return exec.Command(player[0], append(player[1:], clip)...), nil
+	}
+	for _, player := range defaultAudioPlayers {
+		if _, err := exec.LookPath(player[0]); err == nil {
+			return exec.Command(player[0], append(slices.Clone(player[1:]), clip)...), nil
+		}
+	}
+	return nil, errors.New("no audio player found, choose one with -player")
+}
+
+// Programs started for the quiz, such as the audio player or the
+// browser, report failures this way, they are not fatal
+type commandFailedMessage struct {
+	// Shown in the toast, such as "audio" or "lookup"
+	action string
+	err    error
+}
+
+// Output of the player is discarded, it would end up on top of the UI
+func playClip(clip string) tea.Cmd {
+	return func() tea.Msg {
+		command, err := audioPlayerCommand(clip)
+		if err != nil {
+			return commandFailedMessage{"audio", err}
+		}
+		slog.Debug("Playing audio clip", "clip", clip, "player", command.Path)
+		if err := command.Run(); err != nil {
+			return commandFailedMessage{"audio", fmt.Errorf("could not play %s: %w", clip, err)}
+		}
+		return nil
+	}
+}
+
+func (screen quizScreen) clip() string {
+	return promptAudio[screen.question.prompt]
+}
+
+func (screen quizScreen) playAudio() tea.Cmd {
+	clip := screen.clip()
+	if clip == "" && screen.canSpeak() {
+		return speak(screen.question.correctAnswer)
+	}
+	if clip == "" {
+		notify("no audio")
+		return nil
+	}
+	return playClip(clip)
+}
+
+// Offered only when the question has a clip or can be spoken,
+// before the help and exit entries
+func (screen quizScreen) withPlayHelp(entries []helpEntry) []helpEntry {
+	if screen.clip() == "" && !screen.canSpeak() {
+		return entries
+	}
+	return slices.Insert(slices.Clone(entries), len(entries)-2, helpEntry{
+		bindings: []*key.Binding{&keys.PlayAudio},
+		action:   "play",
+	})
+}
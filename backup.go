@@ -0,0 +1,387 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Where backups go, only the section of the backend in use is read
+type backupSettings struct {
+	Backend string `toml:"backend"`
+	// Time between automatic backups when quitting, "0" turns them off
+	Every  string               `toml:"every"`
+	S3     s3BackupSettings     `toml:"s3"`
+	WebDAV webDAVBackupSettings `toml:"webdav"`
+	Rclone rcloneBackupSettings `toml:"rclone"`
+}
+
+type backupBackend interface {
+	upload(ctx context.Context, name string, data []byte) error
+	download(ctx context.Context, name string) ([]byte, error)
+}
+
+const (
+	defaultBackupInterval = 24 * time.Hour
+	// Uploading is left for the next time rather than holding up quitting
+	backupTimeout = time.Minute
+	// Overwritten by every backup, restored unless another one is named
+	latestBackupName = "gem2-latest.tar.gz"
+	backupTimeLayout = "20060102-150405"
+)
+
+var backupConfig backupSettings
+
+func backupSettingsPath() string {
+	return filepath.Join(configDirectory(), "backup.toml")
+}
+
+// Rewritten after every backup, its modification time is when that was
+func backupStampPath() string {
+	return filepath.Join(stateDirectory(), "last-backup")
+}
+
+func parseBackupSettings(data []byte) (backupSettings, error) {
+	var settings backupSettings
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		return backupSettings{}, err
+	}
+	if _, err := settings.interval(); err != nil {
+		return backupSettings{}, err
+	}
+	if _, err := settings.backend(); err != nil {
+		return backupSettings{}, err
+	}
+	return settings, nil
+}
+
+// No backups are made when there is no backup file
+func loadBackupSettings() error {
+	path := backupSettingsPath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	settings, err := parseBackupSettings(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	backupConfig = settings
+	return nil
+}
+
+func (settings backupSettings) interval() (time.Duration, error) {
+	if settings.Every == "" {
+		return defaultBackupInterval, nil
+	}
+	interval, err := time.ParseDuration(settings.Every)
+	if err != nil {
+		return 0, fmt.Errorf("invalid every: %w", err)
+	}
+	return interval, nil
+}
+
+func (settings backupSettings) backend() (backupBackend, error) {
+	switch settings.Backend {
+	case "s3":
+		return settings.S3.backend()
+	case "webdav":
+		return settings.WebDAV.backend()
+	case "rclone":
+		return settings.Rclone.backend()
+	case "":
+		return nil, errors.New("backend is not set, expected s3, webdav or rclone")
+	}
+	return nil, fmt.Errorf("unknown backend %q, expected s3, webdav or rclone", settings.Backend)
+}
+
+// Directories backed up, under the name they have in the archive.
+// The deck copy in the data directory is included, as it may have
+// been edited, the cache is not as it is rebuilt from the deck.
+// On Windows and macOS both are the config directory, which is
+// then archived once, under whichever name sorts first.
+func backedUpDirectories() map[string]string {
+	directories := make(map[string]string)
+	if state := stateDirectory(); state != "" {
+		directories["state"] = state
+	}
+	if data := dataDirectory(); data != "" {
+		directories["data"] = data
+	}
+	return directories
+}
+
+// Settings are not backed up, the ones of backups and sync hold
+// credentials, and the others belong to the device as much as to the
+// user. They share a directory with the data on Windows and macOS.
+func isSettingsFile(file string) bool {
+	settings := []string{
+		backupSettingsPath(),
+		syncSettingsPath(),
+		hooksPath(),
+		habitsPath(),
+		keyMapPath(),
+		leaderboardPath(),
+		themesDirectory(),
+	}
+	return slices.Contains(settings, file)
+}
+
+// Logs and locks mean nothing on another disk, sync clones
+// are on their remote already, and host keys are secrets
+func isBackedUp(file string, relative string, entry fs.DirEntry) bool {
+	name := entry.Name()
+	if isSettingsFile(file) {
+		return false
+	}
+	if entry.IsDir() {
+		return name != "sync"
+	}
+	return entry.Type().IsRegular() &&
+		name != filepath.Base(logPath) &&
+		!strings.HasPrefix(name, filepath.Base(logPath)+".") &&
+		!strings.HasSuffix(name, ".lock") &&
+		!strings.Contains(name, ".tmp") &&
+		!strings.HasPrefix(name, "ssh_host_") &&
+		relative != filepath.Base(backupStampPath())
+}
+
+func writeBackupArchive(w io.Writer) error {
+	compressed := gzip.NewWriter(w)
+	archive := tar.NewWriter(compressed)
+	directories := backedUpDirectories()
+	archived := make(map[string]bool)
+	for _, prefix := range slices.Sorted(maps.Keys(directories)) {
+		directory := directories[prefix]
+		if archived[filepath.Clean(directory)] {
+			continue
+		}
+		archived[filepath.Clean(directory)] = true
+		err := filepath.WalkDir(directory, func(file string, entry fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) && file == directory {
+				return fs.SkipDir
+			}
+			if err != nil {
+				return err
+			}
+			relative, err := filepath.Rel(directory, file)
+			if err != nil || relative == "." {
+				return err
+			}
+			if !isBackedUp(file, relative, entry) {
+				if entry.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = path.Join(prefix, filepath.ToSlash(relative))
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			header.Size = int64(len(data))
+			if err := archive.WriteHeader(header); err != nil {
+				return err
+			}
+			_, err = archive.Write(data)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return errors.Join(archive.Close(), compressed.Close())
+}
+
+// Files in the archive replace the local ones, files
+// missing from it are left alone rather than deleted
+func restoreBackupArchive(r io.Reader) (int, error) {
+	compressed, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	directories := backedUpDirectories()
+	archive := tar.NewReader(compressed)
+	restored := 0
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return restored, nil
+		}
+		if err != nil {
+			return restored, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		prefix, relative, _ := strings.Cut(header.Name, "/")
+		directory, known := directories[prefix]
+		if !known || !filepath.IsLocal(relative) {
+			slog.Warn("Skipping unexpected file in backup", "name", header.Name)
+			continue
+		}
+		// Archives of older builds have settings in them
+		// when the config directory is the data directory
+		if isSettingsFile(filepath.Join(directory, filepath.FromSlash(relative))) {
+			slog.Warn("Skipping settings file in backup", "name", header.Name)
+			continue
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return restored, err
+		}
+		file := filepath.Join(directory, filepath.FromSlash(relative))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return restored, err
+		}
+		if err := writeFileAtomic(file, data, 0); err != nil {
+			return restored, err
+		}
+		restored++
+	}
+}
+
+// Uploaded twice, under its own name to keep a history and as the latest one
+func backUp(ctx context.Context) (string, error) {
+	backend, err := backupConfig.backend()
+	if err != nil {
+		return "", err
+	}
+	var archive bytes.Buffer
+	if err := writeBackupArchive(&archive); err != nil {
+		return "", fmt.Errorf("could not pack data files: %w", err)
+	}
+	name := "gem2-" + time.Now().UTC().Format(backupTimeLayout) + ".tar.gz"
+	for _, name := range []string{name, latestBackupName} {
+		if err := backend.upload(ctx, name, archive.Bytes()); err != nil {
+			return "", fmt.Errorf("could not upload %s: %w", name, err)
+		}
+	}
+	if err := os.WriteFile(backupStampPath(), nil, 0644); err != nil {
+		slog.Error("Failed to write backup stamp", "path", backupStampPath(), "error", err)
+	}
+	slog.Info("Backed up data files", "backend", backupConfig.Backend, "name", name, "bytes", archive.Len())
+	return name, nil
+}
+
+func lastBackup() (time.Time, bool) {
+	info, err := os.Stat(backupStampPath())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
+// Called when quitting, after progress is saved, so the backup has it
+func backUpIfDue() {
+	interval, _ := backupConfig.interval()
+	if backupConfig.Backend == "" || interval == 0 || readOnly || stateDirectory() == "" {
+		return
+	}
+	if last, exists := lastBackup(); exists && time.Since(last) < interval {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+	defer cancel()
+	if _, err := backUp(ctx); err != nil {
+		slog.Error("Automatic backup failed", "error", err)
+		fmt.Fprintf(os.Stderr, "Backup failed: %v\n", err)
+	}
+}
+
+func requireBackupSettings() {
+	if err := loadBackupSettings(); err != nil {
+		logFatal("Failed to load backup settings", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(usageError)
+	}
+	if backupConfig.Backend == "" {
+		fmt.Fprintf(os.Stderr, "Backups are not set up, %s has to name a backend\n", backupSettingsPath())
+		exit(usageError)
+	}
+	if stateDirectory() == "" {
+		fmt.Fprintln(os.Stderr, "Home directory is unknown, there is nothing to back up")
+		exit(backupError)
+	}
+}
+
+func runBackup(args []string) {
+	flags, options := newFlagSet("backup", "")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	options.apply()
+	requireBackupSettings()
+	name, err := backUp(context.Background())
+	if err != nil {
+		logFatal("Backup failed", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(backupError)
+	}
+	fmt.Println("Backed up as", name)
+}
+
+// Replaces files of every profile, which must not be in use meanwhile
+func runRestore(args []string) {
+	flags, options := newFlagSet("restore", "[name]")
+	parseFlags(flags, args)
+	if flags.NArg() > 1 {
+		expectArguments(flags, 1)
+	}
+	options.apply()
+	if readOnly {
+		fmt.Fprintln(os.Stderr, "Restoring writes data files, it can not be done in read-only mode")
+		exit(usageError)
+	}
+	requireBackupSettings()
+	requireInstanceLock("restoring")
+	name := latestBackupName
+	if flags.NArg() == 1 {
+		name = flags.Arg(0)
+	}
+	backend, _ := backupConfig.backend()
+	data, err := backend.download(context.Background(), name)
+	if err != nil {
+		logFatal("Failed to download backup", "name", name, "error", err)
+		fmt.Fprintf(os.Stderr, "Could not download %s: %v\n", name, err)
+		exit(backupError)
+	}
+	restored, err := restoreBackupArchive(bytes.NewReader(data))
+	if err != nil {
+		logFatal("Failed to restore backup", "name", name, "restored", restored, "error", err)
+		fmt.Fprintf(os.Stderr, "Restoring %s failed after %d files: %v\n", name, restored, err)
+		exit(backupError)
+	}
+	slog.Info("Restored backup", "name", name, "files", restored)
+	fmt.Printf("Restored %d files from %s\n", restored, name)
+}
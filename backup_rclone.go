@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Any of the many storages rclone knows, configured with rclone itself
+type rcloneBackupSettings struct {
+	// Remote and directory, e.g. "gdrive:gem2"
+	Remote string `toml:"remote"`
+}
+
+type rcloneBackend struct {
+	remote string
+}
+
+func (settings rcloneBackupSettings) backend() (backupBackend, error) {
+	if settings.Remote == "" {
+		return nil, errors.New("rclone remote is not set")
+	}
+	return rcloneBackend{strings.TrimSuffix(settings.Remote, "/")}, nil
+}
+
+func (backend rcloneBackend) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	command := exec.CommandContext(ctx, "rclone", args...)
+	command.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if err != nil {
+		return nil, fmt.Errorf("rclone %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+func (backend rcloneBackend) upload(ctx context.Context, name string, data []byte) error {
+	_, err := backend.run(ctx, data, "rcat", backend.remote+"/"+name)
+	return err
+}
+
+func (backend rcloneBackend) download(ctx context.Context, name string) ([]byte, error) {
+	return backend.run(ctx, nil, "cat", backend.remote+"/"+name)
+}
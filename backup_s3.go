@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Any S3 compatible storage, keys default to the usual AWS variables
+type s3BackupSettings struct {
+	// Defaults to AWS in the region, needed for other storages
+	Endpoint  string `toml:"endpoint"`
+	Region    string `toml:"region"`
+	Bucket    string `toml:"bucket"`
+	Prefix    string `toml:"prefix"`
+	AccessKey string `toml:"access_key"`
+	SecretKey string `toml:"secret_key"`
+}
+
+type s3Backend struct {
+	settings s3BackupSettings
+}
+
+func (settings s3BackupSettings) backend() (backupBackend, error) {
+	if settings.Bucket == "" {
+		return nil, errors.New("s3 bucket is not set")
+	}
+	if settings.Region == "" {
+		settings.Region = "us-east-1"
+	}
+	if settings.Endpoint == "" {
+		settings.Endpoint = "https://s3." + settings.Region + ".amazonaws.com"
+	}
+	if _, err := url.ParseRequestURI(settings.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	if settings.AccessKey == "" {
+		settings.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if settings.SecretKey == "" {
+		settings.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	return s3Backend{settings}, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// Signature version 4, signing the host and every header
+// already set, which are the x-amz ones and optionally a range
+func signS3Request(request *http.Request, payloadHash string, region string, accessKey string, secretKey string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	names := []string{"host"}
+	values := map[string]string{"host": request.URL.Host}
+	for name, value := range request.Header {
+		lower := strings.ToLower(name)
+		names = append(names, lower)
+		values[lower] = strings.TrimSpace(strings.Join(value, ","))
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + values[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature,
+	))
+}
+
+// Path style addressing, which every S3 compatible storage supports
+func (backend s3Backend) request(ctx context.Context, method string, name string, body []byte) (*http.Response, error) {
+	segments := []string{url.PathEscape(backend.settings.Bucket)}
+	for _, segment := range strings.Split(strings.Trim(backend.settings.Prefix, "/"), "/") {
+		if segment != "" {
+			segments = append(segments, url.PathEscape(segment))
+		}
+	}
+	segments = append(segments, url.PathEscape(name))
+	address := strings.TrimSuffix(backend.settings.Endpoint, "/") + "/" + strings.Join(segments, "/")
+	request, err := http.NewRequestWithContext(ctx, method, address, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	signS3Request(request, sha256Hex(body), backend.settings.Region, backend.settings.AccessKey, backend.settings.SecretKey, time.Now())
+	return http.DefaultClient.Do(request)
+}
+
+// Error responses are XML, their message is the useful part
+func s3Error(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 4<<10))
+	if _, message, found := strings.Cut(string(body), "<Message>"); found {
+		message, _, _ = strings.Cut(message, "</Message>")
+		return fmt.Errorf("s3: %s: %s", response.Status, message)
+	}
+	return fmt.Errorf("s3: %s", response.Status)
+}
+
+func (backend s3Backend) upload(ctx context.Context, name string, data []byte) error {
+	response, err := backend.request(ctx, http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return s3Error(response)
+	}
+	return nil
+}
+
+func (backend s3Backend) download(ctx context.Context, name string) ([]byte, error) {
+	response, err := backend.request(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, s3Error(response)
+	}
+	return io.ReadAll(response.Body)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Collection the backups are put into has to exist already
+type webDAVBackupSettings struct {
+	URL      string `toml:"url"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+}
+
+type webDAVBackend struct {
+	settings webDAVBackupSettings
+}
+
+func (settings webDAVBackupSettings) backend() (backupBackend, error) {
+	if settings.URL == "" {
+		return nil, errors.New("webdav url is not set")
+	}
+	if _, err := url.ParseRequestURI(settings.URL); err != nil {
+		return nil, fmt.Errorf("invalid webdav url: %w", err)
+	}
+	return webDAVBackend{settings}, nil
+}
+
+func (backend webDAVBackend) request(ctx context.Context, method string, name string, body []byte) (*http.Response, error) {
+	address := strings.TrimSuffix(backend.settings.URL, "/") + "/" + url.PathEscape(name)
+	request, err := http.NewRequestWithContext(ctx, method, address, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if backend.settings.Username != "" {
+		request.SetBasicAuth(backend.settings.Username, backend.settings.Password)
+	}
+	return http.DefaultClient.Do(request)
+}
+
+func (backend webDAVBackend) upload(ctx context.Context, name string, data []byte) error {
+	response, err := backend.request(ctx, http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	// Created for new files, OK or No Content for replaced ones
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webdav: %s", response.Status)
+	}
+	return nil
+}
+
+func (backend webDAVBackend) download(ctx context.Context, name string) ([]byte, error) {
+	response, err := backend.request(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webdav: %s", response.Status)
+	}
+	return io.ReadAll(response.Body)
+}
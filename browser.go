@@ -0,0 +1,253 @@
+package main
+
+import (
+	"log/slog"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Which questions the browser shows by how well they are known
+type masteryFilter int
+
+const (
+	anyMastery masteryFilter = iota
+	learningOnly
+	masteredOnly
+	suspendedOnly
+)
+
+func (filter masteryFilter) String() string {
+	switch filter {
+	case learningOnly:
+		return "learning"
+	case masteredOnly:
+		return "mastered"
+	case suspendedOnly:
+		return "suspended"
+	}
+	return "any"
+}
+
+func (filter masteryFilter) matches(stats questionStats) bool {
+	switch filter {
+	case learningOnly:
+		return stats.Streak < masteredStreak
+	case masteredOnly:
+		return stats.Streak >= masteredStreak
+	case suspendedOnly:
+		return stats.Suspended
+	}
+	return true
+}
+
+// Whole deck narrowed down by typing and by the filters,
+// to drill what is shown or look up the table of a verb.
+// The deck has no tags or units, its forms group it instead.
+type browserScreen struct {
+	quiz    *quizScreen
+	entries []conjugatedForm
+	shown   []conjugatedForm
+	// Form clues of the deck, filtered by the one at formFilter-1
+	forms      []string
+	formFilter int
+	mastery    masteryFilter
+	search     textinput.Model
+	listPosition
+}
+
+func newBrowserScreen(quiz *quizScreen) browserScreen {
+	search := textinput.New()
+	search.Prompt = "> "
+	search.Placeholder = "type to search"
+	search.Focus()
+	var entries []conjugatedForm
+	var forms []string
+	for prompt, answer := range quiz.statistics.Answers() {
+		entries = append(entries, conjugatedForm{prompt, answer})
+		if !slices.Contains(forms, prompt.FormClue) {
+			forms = append(forms, prompt.FormClue)
+		}
+	}
+	slices.SortFunc(entries, func(a conjugatedForm, b conjugatedForm) int {
+		return comparePrompts(a.prompt, b.prompt)
+	})
+	return browserScreen{
+		quiz:    quiz,
+		entries: entries,
+		shown:   entries,
+		forms:   forms,
+		search:  search,
+	}
+}
+
+// Search looks at the prompt and the answer alike
+func (screen *browserScreen) applyFilters() {
+	query := foldForSearch(strings.TrimSpace(screen.search.Value()))
+	screen.shown = nil
+	for _, entry := range screen.entries {
+		if screen.formFilter > 0 && entry.prompt.FormClue != screen.forms[screen.formFilter-1] {
+			continue
+		}
+		if !screen.mastery.matches(screen.quiz.statistics.Record(entry.prompt)) {
+			continue
+		}
+		if !strings.Contains(foldForSearch(entry.prompt.String()), query) &&
+			!strings.Contains(foldForSearch(entry.answer), query) {
+			continue
+		}
+		screen.shown = append(screen.shown, entry)
+	}
+	screen.listPosition = listPosition{}
+}
+
+func (screen browserScreen) formFilterName() string {
+	if screen.formFilter == 0 {
+		return "all"
+	}
+	return screen.forms[screen.formFilter-1]
+}
+
+// Shown questions in random order, ahead of the usual ones
+func (screen browserScreen) startDrill() (tea.Model, tea.Cmd) {
+	if len(screen.shown) == 0 {
+		notify("nothing to drill")
+		return screen, expireToastLater()
+	}
+	queue := make([]prompt, len(screen.shown))
+	for i, entry := range screen.shown {
+		queue[i] = entry.prompt
+	}
+	rand.Shuffle(len(queue), func(i, j int) {
+		queue[i], queue[j] = queue[j], queue[i]
+	})
+	slog.Info("Drilling browsed questions", "count", len(queue))
+	return screen, replayPrompts(*screen.quiz, queue)
+}
+
+func (screen browserScreen) isTyping() bool {
+	return true
+}
+
+func (screen browserScreen) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Letters are typed into the search, so only the
+// arrow keys and the like move through the list
+func (screen browserScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Menu):
+			return screen, popScreen
+		case key.Matches(msg, keys.Back) && screen.search.Value() == "":
+			return screen, popScreen
+		case key.Matches(msg, keys.Submit):
+			if index := screen.selectedIndex(); index < len(screen.shown) {
+				return screen, pushScreen(newConjugationScreen(screen.quiz.statistics, screen.shown[index].prompt.Verb))
+			}
+			return screen, nil
+		case key.Matches(msg, keys.Drill):
+			return screen.startDrill()
+		case key.Matches(msg, keys.Bulk):
+			if len(screen.shown) == 0 {
+				notify("no questions shown")
+				return screen, nil
+			}
+			return screen, pushScreen(bulkScreen{browser: screen})
+		case key.Matches(msg, keys.FilterForm):
+			screen.formFilter = (screen.formFilter + 1) % (len(screen.forms) + 1)
+			screen.applyFilters()
+			return screen, nil
+		case key.Matches(msg, keys.FilterMastery):
+			screen.mastery = (screen.mastery + 1) % (suspendedOnly + 1)
+			screen.applyFilters()
+			return screen, nil
+		case key.Matches(msg, keys.Down) && !isPrintableKey(msg.String()):
+			screen.scrollDown(len(screen.shown), screen.shownRows())
+			return screen, nil
+		case key.Matches(msg, keys.Up) && !isPrintableKey(msg.String()):
+			screen.scrollUp()
+			return screen, nil
+		}
+		before := screen.search.Value()
+		var cmd tea.Cmd
+		screen.search, cmd = screen.search.Update(msg)
+		if screen.search.Value() != before {
+			screen.applyFilters()
+		}
+		return screen, cmd
+	case tea.MouseMsg:
+		screen.listPosition.handleMouse(msg, len(screen.shown), screen.shownRows())
+		return screen, nil
+	}
+	var cmd tea.Cmd
+	screen.search, cmd = screen.search.Update(msg)
+	return screen, cmd
+}
+
+// Search field and filters take the place of the detail line
+func (screen browserScreen) shownRows() int {
+	return listShownRows() - 2
+}
+
+func (screen browserScreen) renderEntry(entry conjugatedForm, selected bool, difficulty difficultyEstimator) string {
+	record := screen.quiz.statistics.Record(entry.prompt)
+	statsTrisymbol := difficulty.withBadge(record, renderStatsTrisymbol(background.Bold(selected).Italic(selected), record))
+	text := entry.prompt.String() + " " + symbols.arrow + " " + entry.answer
+	if record.Suspended {
+		text += " " + italic("(suspended)")
+	}
+	if selected {
+		text = "> " + text
+	}
+	return promptStatsEntryStyle.
+		Foreground(stageOf(record).color()).
+		Bold(selected).
+		Italic(selected).
+		Width(boxWidth-lipgloss.Width(statsTrisymbol)).
+		AlignHorizontal(lipgloss.Left).
+		Render(text) +
+		statsTrisymbol
+}
+
+var browserScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit}, action: "table"},
+	{bindings: []*key.Binding{&keys.FilterForm}, action: "form"},
+	{bindings: []*key.Binding{&keys.FilterMastery}, action: "mastery"},
+	{bindings: []*key.Binding{&keys.Drill}, action: "drill"},
+	{bindings: []*key.Binding{&keys.Bulk}, action: "bulk"},
+	{bindings: []*key.Binding{&keys.Menu}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen browserScreen) View() string {
+	shownRows := screen.shownRows()
+	screen.fit(shownRows)
+	screen.search.Width = boxWidth - lipgloss.Width(screen.search.Prompt) - 1
+	filters := questionStatsStyle.Render("form: " + screen.formFilterName() + ", mastery: " + screen.mastery.String())
+	lines := []string{
+		renderListTitle("Deck", screen.listPosition, len(screen.shown)),
+		screen.search.View(),
+		filters,
+		"",
+	}
+	if len(screen.shown) == 0 {
+		lines = append(lines, questionStatsAlignStyle.Render(questionStatsStyle.Render("no questions found")))
+	}
+	difficulty := screen.quiz.statistics.difficultyEstimator()
+	for row := 0; row < shownRows; row++ {
+		index := screen.firstShownIndex + row
+		if index >= len(screen.shown) {
+			break
+		}
+		lines = append(lines, screen.renderEntry(screen.shown[index], row == screen.selectedRow, difficulty))
+	}
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), renderHelpRow(browserScreenHelp[:]))
+}
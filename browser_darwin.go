@@ -0,0 +1,7 @@
+package main
+
+import "os/exec"
+
+func browserCommand(address string) *exec.Cmd {
+	return exec.Command("open", address)
+}
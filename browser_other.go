@@ -0,0 +1,9 @@
+//go:build !windows && !darwin
+
+package main
+
+import "os/exec"
+
+func browserCommand(address string) *exec.Cmd {
+	return exec.Command("xdg-open", address)
+}
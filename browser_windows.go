@@ -0,0 +1,8 @@
+package main
+
+import "os/exec"
+
+// Start would take the ampersands of the address for command separators
+func browserCommand(address string) *exec.Cmd {
+	return exec.Command("rundll32", "url.dll,FileProtocolHandler", address)
+}
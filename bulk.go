@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Change applied at once to every question the browser shows
+type bulkAction struct {
+	title string
+	// Past tense for the toast, like "suspended 12 questions"
+	done  string
+	apply func(statistics *statisticsDatabase, prompt prompt)
+}
+
+var bulkActions = [...]bulkAction{
+	{
+		title: "Reset statistics",
+		done:  "reset",
+		apply: func(statistics *statisticsDatabase, prompt prompt) {
+			// Kept suspended, starting over is not asking for it again
+			suspended := statistics.Record(prompt).Suspended
+			statistics.ResetStats(prompt)
+			statistics.Suspend(prompt, suspended)
+		},
+	},
+	{
+		title: "Suspend",
+		done:  "suspended",
+		apply: func(statistics *statisticsDatabase, prompt prompt) { statistics.Suspend(prompt, true) },
+	},
+	{
+		title: "Unsuspend",
+		done:  "unsuspended",
+		apply: func(statistics *statisticsDatabase, prompt prompt) { statistics.Suspend(prompt, false) },
+	},
+	{
+		title: "Retire as mastered",
+		done:  "retired",
+		apply: func(statistics *statisticsDatabase, prompt prompt) { statistics.MarkKnown(prompt, masteredStreak) },
+	},
+	{
+		title: "Boost to ask sooner",
+		done:  "boosted",
+		apply: func(statistics *statisticsDatabase, prompt prompt) { statistics.Boost(prompt) },
+	},
+}
+
+// Opened from the browser, which goes back to its filters
+// refreshed, as the action may have changed what they match
+type bulkScreen struct {
+	browser browserScreen
+	menuSelection
+}
+
+func (screen bulkScreen) Init() tea.Cmd {
+	return nil
+}
+
+// Saved at once, screens opened from the menu exit without saving
+func (screen bulkScreen) apply() (tea.Model, tea.Cmd) {
+	action := bulkActions[screen.selected]
+	quiz := screen.browser.quiz
+	for _, entry := range screen.browser.shown {
+		action.apply(quiz.statistics, entry.prompt)
+	}
+	slog.Info("Applied bulk action", "action", action.title, "questions", len(screen.browser.shown))
+	notify(fmt.Sprintf("%s %d questions", action.done, len(screen.browser.shown)))
+	browser := screen.browser
+	browser.applyFilters()
+	back := tea.Sequence(popScreen, replaceScreen(browser))
+	if err := quiz.saveQuietly(); err != nil {
+		return screen, tea.Sequence(back, pushScreen(newSaveFailedScreen(quiz.session, nil, err)))
+	}
+	return screen, back
+}
+
+func (screen bulkScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back, keys.Menu):
+			return screen, popScreen
+		case key.Matches(msg, keys.Down):
+			screen.moveDown(len(bulkActions))
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.moveUp()
+			return screen, nil
+		case key.Matches(msg, keys.Submit):
+			return screen.apply()
+		}
+	case tea.MouseMsg:
+		if screen.handleMouse(msg, listFirstRow, len(bulkActions)) {
+			return screen.apply()
+		}
+	}
+	return screen, nil
+}
+
+var bulkScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit}, action: "apply"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen bulkScreen) View() string {
+	lines := []string{renderMenuTitle("Bulk action"), ""}
+	for i, action := range bulkActions {
+		lines = append(lines, renderMenuEntry(action.title, nil, i == screen.selected))
+	}
+	shown := fmt.Sprintf(
+		"applies to the %d questions shown, form: %s, mastery: %s",
+		len(screen.browser.shown),
+		screen.browser.formFilterName(),
+		screen.browser.mastery,
+	)
+	if query := strings.TrimSpace(screen.browser.search.Value()); query != "" {
+		shown += fmt.Sprintf(", search: %q", query)
+	}
+	lines = append(lines, "", questionStatsStyle.Width(boxWidth).Render(shown))
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), renderHelpRow(bulkScreenHelp[:]))
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// How closely an answer has to match to be accepted, spaces
+// around and between the words of an answer never matter
+type answerStrictness int
+
+const (
+	checkExact answerStrictness = iota
+	// Letter case does not matter
+	checkCase
+	// Neither does case nor accents, ß and the like still do
+	checkAccents
+)
+
+var answerStrictnessNames = [...]string{"exact", "ignore-case", "ignore-accents"}
+
+var answerChecking answerStrictness
+
+func (strictness answerStrictness) String() string {
+	return answerStrictnessNames[strictness]
+}
+
+// Makes it usable as a flag
+func (strictness *answerStrictness) Set(value string) error {
+	for i, name := range answerStrictnessNames {
+		if name == value {
+			*strictness = answerStrictness(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown value %q, available: %s", value, strings.Join(answerStrictnessNames[:], ", "))
+}
+
+func (strictness *answerStrictness) cycle() {
+	*strictness = (*strictness + 1) % answerStrictness(len(answerStrictnessNames))
+}
+
+// Any of the answers separated by the delimiter the deck declares is accepted
+func (strictness answerStrictness) accepts(correct string, typed string) bool {
+	if answerDelimiter == "" {
+		return strictness.matches(correct, typed)
+	}
+	for _, alternative := range strings.Split(correct, answerDelimiter) {
+		if strictness.matches(alternative, typed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (strictness answerStrictness) matches(correct string, typed string) bool {
+	correct, typed = joinWords(correct), joinWords(typed)
+	switch strictness {
+	case checkCase:
+		return strings.EqualFold(norm.NFC.String(correct), norm.NFC.String(typed))
+	case checkAccents:
+		return strings.EqualFold(withoutAccents(correct), withoutAccents(typed))
+	}
+	return sameText(correct, typed)
+}
+
+func withoutAccents(text string) string {
+	return strings.Map(baseLetter, norm.NFC.String(text))
+}
+
+// Words of the answer separated by single spaces, however they were typed
+func joinWords(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Words of a correct answer of several words which are missing from
+// the typed one are underlined, so that in a compound tense the wrong
+// auxiliary stands out from the rest. Alternatives are left as they are.
+func markMissingWords(correct string, typed string) string {
+	words := strings.Fields(correct)
+	typedWords := strings.Fields(typed)
+	if len(words) < 2 || len(typedWords) == 0 || (answerDelimiter != "" && strings.Contains(correct, answerDelimiter)) {
+		return correct
+	}
+	matched := answerChecking.commonWords(words, typedWords)
+	for i, word := range words {
+		if !matched[i] {
+			words[i] = underline(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Words of the correct answer in the longest sequence of
+// words both answers share, so that a missing or an extra
+// word does not mark every word after it as wrong
+func (strictness answerStrictness) commonWords(words []string, typed []string) []bool {
+	// Length of the longest shared sequence of the remaining words
+	lengths := make([][]int, len(words)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(typed)+1)
+	}
+	for i := len(words) - 1; i >= 0; i-- {
+		for j := len(typed) - 1; j >= 0; j-- {
+			if strictness.matches(words[i], typed[j]) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	matched := make([]bool, len(words))
+	for i, j := 0, 0; i < len(words) && j < len(typed); {
+		switch {
+		case strictness.matches(words[i], typed[j]):
+			matched[i] = true
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matched
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Number of answers to choose from, 0 has the answer typed
+var multipleChoice int
+
+// Chosen with the digit keys, so there are never more than nine
+var multipleChoiceChoices = [...]int{0, 3, 4, 6}
+
+func formatMultipleChoice() string {
+	if multipleChoice == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d answers", multipleChoice)
+}
+
+// Wrong answers offered next to the correct one. Strategies are
+// registered in distractorStrategies under the name -distractors takes.
+type distractorStrategy interface {
+	// At most count distinct answers, none of them accepted as correct
+	distractors(statistics statisticsDatabase, question question, count int) []string
+}
+
+// How the wrong answers to choose from are picked
+type distractorSource int
+
+const (
+	similarDistractorSource distractorSource = iota
+	randomDistractorSource
+)
+
+var (
+	distractorSourceNames = [...]string{"similar", "random"}
+	distractorStrategies  = [...]distractorStrategy{similarDistractors{}, randomDistractors{}}
+)
+
+var distractors distractorSource
+
+func (source distractorSource) String() string {
+	return distractorSourceNames[source]
+}
+
+// Makes it usable as a flag
+func (source *distractorSource) Set(value string) error {
+	for i, name := range distractorSourceNames {
+		if name == value {
+			*source = distractorSource(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown value %q, available: %s", value, strings.Join(distractorSourceNames[:], ", "))
+}
+
+func (source *distractorSource) cycle() {
+	*source = (*source + 1) % distractorSource(len(distractorSourceNames))
+}
+
+// Answers of the deck which could pass for wrong ones, each once,
+// in random order so that ties between them are broken at random
+func (statistics statisticsDatabase) wrongAnswerCandidates(question question) []string {
+	seen := map[string]bool{}
+	var candidates []string
+	for _, answer := range statistics.Answers() {
+		if seen[answer] || answerChecking.accepts(question.correctAnswer, answer) {
+			continue
+		}
+		seen[answer] = true
+		candidates = append(candidates, answer)
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	return candidates
+}
+
+type randomDistractors struct{}
+
+func (randomDistractors) distractors(statistics statisticsDatabase, question question, count int) []string {
+	candidates := statistics.wrongAnswerCandidates(question)
+	return candidates[:min(count, len(candidates))]
+}
+
+// Other forms of the same verb come first, as telling them apart is the
+// point of the drill, then answers spelled most like the correct one
+type similarDistractors struct{}
+
+func (similarDistractors) distractors(statistics statisticsDatabase, question question, count int) []string {
+	sameVerb := map[string]bool{}
+	for prompt, answer := range statistics.Answers() {
+		if prompt.Verb == question.prompt.Verb {
+			sameVerb[answer] = true
+		}
+	}
+	correct := foldForSearch(question.correctAnswer)
+	score := func(answer string) float64 {
+		folded := foldForSearch(answer)
+		longest := max(len([]rune(correct)), len([]rune(folded)), 1)
+		similarity := 1 - float64(editDistance(correct, folded))/float64(longest)
+		if sameVerb[answer] {
+			similarity++
+		}
+		return similarity
+	}
+	candidates := statistics.wrongAnswerCandidates(question)
+	slices.SortStableFunc(candidates, func(a string, b string) int {
+		return cmp.Compare(score(b), score(a))
+	})
+	return candidates[:min(count, len(candidates))]
+}
+
+// Levenshtein distance, in letters rather than bytes
+func editDistance(a string, b string) int {
+	first, second := []rune(a), []rune(b)
+	previous := make([]int, len(second)+1)
+	current := make([]int, len(second)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(first); i++ {
+		current[0] = i
+		for j := 1; j <= len(second); j++ {
+			substitution := previous[j-1]
+			if first[i-1] != second[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(second)]
+}
+
+// Correct answer among the distractors at a random place,
+// nil when the answer is typed or there is nothing to mix it with
+func (statistics statisticsDatabase) choicesFor(question question) []string {
+	if multipleChoice == 0 {
+		return nil
+	}
+	choices := distractorStrategies[distractors].distractors(statistics, question, multipleChoice-1)
+	if len(choices) == 0 {
+		return nil
+	}
+	choices = append(choices, question.correctAnswer)
+	rand.Shuffle(len(choices), func(i, j int) {
+		choices[i], choices[j] = choices[j], choices[i]
+	})
+	return choices
+}
+
+// Digit of a choice answers with it at once
+func (screen quizScreen) chooseAnswer(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	index, err := strconv.Atoi(msg.String())
+	if err != nil || index < 1 || index > len(screen.choices) {
+		return screen, nil, false
+	}
+	screen.inputField.Width = inputFieldWidth()
+	screen.inputField.SetValue(screen.choices[index-1])
+	model, cmd := screen.answer()
+	return model, cmd, true
+}
+
+func (screen quizScreen) renderChoices() string {
+	entries := make([]string, len(screen.choices))
+	for i, choice := range screen.choices {
+		entries[i] = bold(strconv.Itoa(i+1)) + " " + isolate(choice)
+	}
+	return questionStyle.Render(strings.Join(entries, "   "))
+}
+
+func (screen quizScreen) withChoiceHelp(entries []helpEntry) []helpEntry {
+	if len(screen.choices) == 0 {
+		return entries
+	}
+	// Only shown in the help row, the digits are matched by chooseAnswer
+	choiceKeys := key.NewBinding(key.WithKeys(fmt.Sprintf("1-%d", len(screen.choices))))
+	return slices.Insert(slices.Clone(entries), 1, helpEntry{
+		bindings: []*key.Binding{&choiceKeys},
+		action:   "choose",
+	})
+}
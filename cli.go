@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type command struct {
+	name string
+	// Shown after the command name in usage messages
+	arguments string
+	summary   string
+	run       func(args []string)
+}
+
+// Filled in init, as commands refer back to the list for help
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "quiz", summary: "practice verb forms, the default command", run: runQuiz},
+		{name: "drill", summary: "practice over plain standard input and output", run: runDrill},
+		{name: "serve", summary: "answer questions over a local HTTP API", run: runServe},
+		{name: "ssh", summary: "serve the quiz over SSH, each user with their own profile", run: runSSH},
+		{name: "stats", summary: "print a statistics summary", run: runStats},
+		{name: "due", summary: "print the number of due questions, fail if there are none", run: runDue},
+		{name: "remind", summary: "show a desktop notification when the daily goal is not met yet", run: runRemind},
+		{name: "validate", summary: "check the word database and data files", run: runValidate},
+		{name: "doctor", summary: "check the terminal, data directories and deck for common problems", run: runDoctor},
+		{name: "export", arguments: "file", summary: "export mistakes, or the questions with their progress, to a .csv, .md or .xlsx file", run: runExport},
+		{name: "worksheet", arguments: "file", summary: "write questions to a .txt, .md or .pdf file for practice on paper", run: runWorksheet},
+		{name: "assign", arguments: "file", summary: "write an assignment of questions for students to answer", run: runAssign},
+		{name: "assignment", arguments: "file", summary: "answer an assignment and write the result for the teacher", run: runAssignment},
+		{name: "grade", arguments: "key result...", summary: "grade results by the key of an assignment and print them", run: runGrade},
+		{name: "import", arguments: "file", summary: "merge statistics from another statistics file", run: runImport},
+		{name: "import-mistakes", arguments: "file", summary: "import the plain text mistakes file of old versions, once", run: runImportMistakes},
+		{name: "quizlet", arguments: "export deck.xlsx", summary: "convert a set exported from Quizlet into a deck", run: runQuizlet},
+		{name: "get", arguments: "[name]", summary: "list the community decks or download one", run: runGet},
+		{name: "sync", summary: "merge progress with other devices through a git remote", run: runSync},
+		{name: "backup", summary: "upload the data files to the backup storage", run: runBackup},
+		{name: "restore", arguments: "[name]", summary: "replace the data files with a backup, the latest one by default", run: runRestore},
+		{name: "version", summary: "print version and build information", run: runVersion},
+		{name: "help", arguments: "[command]", summary: "show help for a command", run: runHelp},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, command := range commands {
+		if command.name == name {
+			return command, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: gem2 [command] [flags] [arguments]\n\nCommands:\n")
+	width := 0
+	for _, command := range commands {
+		width = max(width, len(command.name))
+	}
+	for _, command := range commands {
+		fmt.Fprintf(os.Stderr, "  %-*s %s\n", width, command.name, command.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"gem2 help <command>\" for the flags of a command\n")
+}
+
+// Flags understood by every command
+type commonOptions struct {
+	profile string
+	logging loggingOptions
+	paths   pathOptions
+	day     dayOptions
+	version bool
+	// Set by commands which only read before applying the options,
+	// nothing is written for them but the log, not even migrated files
+	readsOnly bool
+}
+
+func newFlagSet(name string, arguments string) (*flag.FlagSet, *commonOptions) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: gem2 %s\n\nFlags:\n", strings.TrimSpace(name+" [flags] "+arguments))
+		flags.PrintDefaults()
+	}
+	var options commonOptions
+	flags.BoolVar(&readOnly, "readonly", false, "practice without writing statistics, mistakes or logs")
+	flags.BoolVar(&options.version, "version", false, "print version and build information and exit")
+	flags.StringVar(&options.profile, "profile", "", "keep statistics, mistakes and history of the `name`d user separately")
+	flags.StringVar(&options.logging.level, "log-level", "info", "minimal `level` of logged messages: debug, info, warn or error")
+	flags.BoolVar(&options.logging.verbose, "verbose", false, "log debug messages too, same as -log-level debug")
+	flags.BoolVar(&options.logging.quiet, "quiet", false, "do not write a log file at all")
+	options.paths.register(flags)
+	options.day.register(flags)
+	flags.Int64Var(&options.logging.maxSize, "log-max-size", defaultLogMaxSize, "start a new log once the old one exceeds this many `bytes`, 0 disables rotation")
+	flags.IntVar(&options.logging.backups, "log-backups", defaultLogBackups, "`number` of rotated logs to keep")
+	return flags, &options
+}
+
+// Exits on invalid flags, -h is not treated as an error
+func parseFlags(flags *flag.FlagSet, args []string) {
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		exit(ok)
+	}
+	if err != nil {
+		exit(usageError)
+	}
+}
+
+// Exits unless exactly the expected number of arguments is left after flags
+func expectArguments(flags *flag.FlagSet, count int) {
+	if flags.NArg() != count {
+		fmt.Fprintf(os.Stderr, "Wrong number of arguments: expected %d, got %d\n", count, flags.NArg())
+		flags.Usage()
+		exit(usageError)
+	}
+}
+
+// Logging goes first, so that switching to a profile is already
+// logged, explicit paths go last to take precedence over the profile
+func (options commonOptions) apply() {
+	if options.version {
+		fmt.Println(currentBuild())
+		exit(ok)
+	}
+	useStandardDirectories()
+	if options.paths.log != "" {
+		logPath = options.paths.log
+	}
+	// Log file is deliberately left open until the process exits
+	setupLogging(options.logging)
+	if options.readsOnly {
+		readOnly = true
+	}
+	migrateFromWorkingDirectory(options.paths)
+	if options.profile != "" {
+		useProfile(options.profile)
+	}
+	options.paths.apply()
+	if err := options.day.apply(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(usageError)
+	}
+}
+
+// Commands which write statistics must not run alongside the quiz
+func requireInstanceLock(action string) {
+	if !readOnly && !acquireInstanceLock() {
+		logFatal("Another instance is running, close it before " + action)
+		fmt.Fprintf(os.Stderr, "Another instance is running, close it before %s\n", action)
+		exit(lockError)
+	}
+}
+
+// Practicing is still possible while another instance
+// is running, only without saving anything
+func lockOrFallBackToReadOnly() {
+	if !readOnly && !acquireInstanceLock() {
+		slog.Warn("Another instance is running, starting in read-only mode")
+		readOnly = true
+	}
+}
+
+func runHelp(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		return
+	}
+	command, exists := findCommand(args[0])
+	if !exists {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+		printUsage()
+		exit(usageError)
+	}
+	command.run([]string{"-h"})
+}
+
+func runExport(args []string) {
+	flags, options := newFlagSet("export", "file")
+	questions := flags.Bool("questions", false, "export every question of the deck instead of the mistakes")
+	withStatistics := flags.Bool("statistics", false, "add answers, accuracy, streak and last practiced columns to the exported questions")
+	parseFlags(flags, args)
+	expectArguments(flags, 1)
+	// Exporting questions only reads, not even migration backups are written
+	options.readsOnly = *questions
+	options.apply()
+	if *withStatistics && !*questions {
+		fmt.Fprintln(os.Stderr, "Statistics columns are only exported with -questions")
+		exit(usageError)
+	}
+	if *questions {
+		exportQuestions(flags.Arg(0), *withStatistics)
+		return
+	}
+	exportMistakes(flags.Arg(0))
+}
+
+func runImport(args []string) {
+	flags, options := newFlagSet("import", "file")
+	parseFlags(flags, args)
+	expectArguments(flags, 1)
+	options.apply()
+	requireInstanceLock("importing")
+	mergeStatisticsFile(flags.Arg(0))
+}
+
+func runImportMistakes(args []string) {
+	flags, options := newFlagSet("import-mistakes", "file")
+	parseFlags(flags, args)
+	expectArguments(flags, 1)
+	options.apply()
+	requireInstanceLock("importing")
+	importLegacyMistakes(flags.Arg(0))
+}
+
+// Command name may be omitted, in which case the arguments
+// are flags of the quiz
+func runCommandLine(args []string) {
+	name := "quiz"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	command, exists := findCommand(name)
+	if !exists {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		printUsage()
+		exit(usageError)
+	}
+	command.run(args)
+}
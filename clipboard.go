@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+// OSC 52 reaches the clipboard of the terminal even over ssh, the
+// system clipboard covers terminals which ignore the sequence.
+// Like the bell, the sequence goes straight to the terminal.
+func copyToClipboard(text string) tea.Cmd {
+	return func() tea.Msg {
+		termenv.Copy(text)
+		if err := clipboard.WriteAll(text); err != nil {
+			slog.Debug("System clipboard unavailable", "error", err)
+		}
+		return nil
+	}
+}
+
+func (screen quizScreen) copyAnswer() tea.Cmd {
+	notify("answer copied")
+	return copyToClipboard(screen.question.correctAnswer)
+}
+
+// Written the way the screen reader mode asks questions
+func (screen quizScreen) copyQuestion() tea.Cmd {
+	notify("question copied")
+	return copyToClipboard(fmt.Sprintf("%s: %s", screen.question.prompt, screen.question.correctAnswer))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Time spent practicing, which only runs while questions are shown.
+// Session length and answer times are read from it, so looking at
+// statistics does not count as slow answering.
+type sessionClock struct {
+	// Time counted up to when the clock last stopped
+	counted time.Duration
+	// When it was last started, zero while stopped
+	running time.Time
+}
+
+func (clock *sessionClock) start() {
+	if clock.running.IsZero() {
+		clock.running = time.Now()
+	}
+}
+
+func (clock *sessionClock) stop() {
+	if !clock.running.IsZero() {
+		clock.counted += time.Since(clock.running)
+		clock.running = time.Time{}
+	}
+}
+
+func (clock sessionClock) elapsed() time.Duration {
+	if clock.running.IsZero() {
+		return clock.counted
+	}
+	return clock.counted + time.Since(clock.running)
+}
+
+// Screens practiced on, any other screen stops the clock, new ones
+// included. Drills have no screens, their clock runs throughout.
+func runsSessionClock(screen tea.Model) bool {
+	switch screen.(type) {
+	case quizScreen, duelScreen:
+		return true
+	}
+	return false
+}
+
+// Follows whichever screen ended up on top after an update
+func (m model) keepSessionTime() {
+	if !m.session.isLoaded() {
+		return
+	}
+	if runsSessionClock(m.top()) {
+		m.session.clock.start()
+	} else {
+		m.session.clock.stop()
+	}
+}
+
+// Session time the current question has been shown for
+func (screen quizScreen) questionTime() time.Duration {
+	return screen.clock.elapsed() - screen.questionShown
+}
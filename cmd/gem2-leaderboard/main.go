@@ -0,0 +1,57 @@
+// Companion server of gem2 collecting daily results of a study group,
+// players opt in by pointing leaderboard.toml of gem2 at it
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kligunov-id/gem2/leaderboard"
+)
+
+func main() {
+	address := flag.String("addr", ":8418", "`address` to listen on, every classmate has to be able to reach it")
+	path := flag.String("data", "leaderboard.json", "`file` keeping the results of the last month")
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	board, err := leaderboard.Open(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read results: %v\n", err)
+		os.Exit(1)
+	}
+	server := &http.Server{
+		Addr:              *address,
+		Handler:           board.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		slog.Info("Received signal, stopping server", "signal", sig)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			slog.Error("Failed to stop server gracefully", "error", err)
+		}
+	}()
+
+	slog.Info("Serving leaderboard", "address", *address, "data", *path)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log/slog"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+var collationLanguages = map[string]language.Tag{
+	"german":  language.German,
+	"french":  language.French,
+	"spanish": language.Spanish,
+}
+
+// Declared by the deck or told by its special characters,
+// empty when neither tells it or the deck is not loaded yet
+var deckLanguage string
+
+// Root collation until the language of the deck is known,
+// it already sorts accented letters next to their base letter
+var deckCollator = collate.New(language.Und)
+
+// Unknown or empty language falls back to the root collation
+func useCollation(name string) {
+	tag, exists := collationLanguages[name]
+	if !exists {
+		tag = language.Und
+	}
+	deckCollator = collate.New(tag)
+	slog.Debug("Using collation", "language", tag)
+}
+
+func (statistics *statisticsDatabase) useDeckCollation() {
+	deckLanguage = statistics.language()
+	useCollation(deckLanguage)
+}
+
+// Alphabetical order of text in the deck, as a native speaker expects
+func compareText(a string, b string) int {
+	return deckCollator.CompareString(a, b)
+}
+
+func comparePrompts(a prompt, b prompt) int {
+	return compareText(a.String(), b.String())
+}
+
+// Sorting keys are computed once per prompt, which
+// matters for the whole deck on the statistics screen
+type promptLister []prompt
+
+func (prompts promptLister) Len() int {
+	return len(prompts)
+}
+
+func (prompts promptLister) Swap(i, j int) {
+	prompts[i], prompts[j] = prompts[j], prompts[i]
+}
+
+func (prompts promptLister) Bytes(i int) []byte {
+	return []byte(prompts[i].String())
+}
+
+func sortPromptsAlphabetically(prompts []prompt) {
+	deckCollator.Sort(promptLister(prompts))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strconv"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Screens with a view of their own in the compact layout, a few lines
+// without the box for small panes like a split of tmux. Screens without
+// one are drawn in the usual box there, as they are rarely open for long.
+type compactScreen interface {
+	compactView() string
+}
+
+// Whole width of the terminal, as there is no border to keep
+func compactWidth() int {
+	if terminalWidth == 0 {
+		return boxWidth
+	}
+	return terminalWidth
+}
+
+// Drawn from the top left corner, centering the box
+// would leave most of a small pane empty
+func (m model) compactView() string {
+	var screen string
+	if compact, ok := m.top().(compactScreen); ok {
+		screen = compact.compactView()
+	} else {
+		screen = clearImages(m.top().View())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, screen, renderStatusBar(m.top(), m.session)) + "\n"
+}
+
+// Question, its count and the session stats on one line, with the answer
+// typed in place of the verb form. Images are left out, there is no room.
+func (screen quizScreen) renderCompactQuestion() string {
+	statsStyle := background.Foreground(textColor)
+	statsTrisymbol := renderStatsTrisymbol(
+		statsStyle.Bold(true),
+		questionStats{Streak: screen.streak, Correct: screen.correctAnswers, Mistakes: screen.wrongAnswers},
+	)
+	current := int(screen.answers())
+	if screen.mode == input {
+		current++
+	}
+	clue := statsStyle.Render(strconv.Itoa(current)+screen.renderSessionLength()+". ") +
+		promptStyle.Render(screen.question.prompt.FormClue+" + "+screen.question.prompt.Verb+": ")
+	screen.inputField.Width = max(compactWidth()-lipgloss.Width(clue)-lipgloss.Width(statsTrisymbol)-2, 1)
+	answer := questionStyle.Render(screen.inputField.View())
+	spacing := max(compactWidth()-lipgloss.Width(clue)-lipgloss.Width(answer)-lipgloss.Width(statsTrisymbol), 1)
+	return clue + answer + statsStyle.Width(spacing).Render("") + statsTrisymbol
+}
+
+func (screen quizScreen) compactView() string {
+	lines := []string{screen.renderCompactQuestion()}
+	switch {
+	case screen.mode == validation:
+		style, text := screen.validationMessage()
+		lines = append(lines, style.UnsetWidth().UnsetAlign().Render(text), renderHelpRow(screen.withPlayHelp(validationHelp[:])))
+	case screen.picker.open:
+		lines = append(lines, screen.renderPicker(), renderHelpRow(pickerHelp[:]))
+	default:
+		if len(screen.choices) > 0 {
+			lines = append(lines, screen.renderChoices())
+		}
+		lines = append(lines, renderHelpRow(screen.withChoiceHelp(screen.withPlayHelp(inputHelp[:]))))
+	}
+	if readOnly {
+		lines = append(lines, renderReadOnlyRow())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
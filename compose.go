@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// Two typed characters replaced by one, for accents on a plain US layout
+type composeTable map[string]rune
+
+var composeTables = map[string]composeTable{
+	"german": {
+		`"a`: 'ä', `"o`: 'ö', `"u`: 'ü', `"s`: 'ß',
+		`"A`: 'Ä', `"O`: 'Ö', `"U`: 'Ü',
+	},
+	"french": {
+		`'e`: 'é', "`e": 'è', "`a": 'à', "`u": 'ù',
+		`^a`: 'â', `^e`: 'ê', `^i`: 'î', `^o`: 'ô', `^u`: 'û',
+		`"e`: 'ë', `"i`: 'ï', `,c`: 'ç',
+		`'E`: 'É', "`E": 'È', "`A": 'À', `,C`: 'Ç',
+	},
+	"spanish": {
+		`'a`: 'á', `'e`: 'é', `'i`: 'í', `'o`: 'ó', `'u`: 'ú',
+		`~n`: 'ñ', `"u`: 'ü',
+		`'A`: 'Á', `'E`: 'É', `'I`: 'Í', `'O`: 'Ó', `'U`: 'Ú', `~N`: 'Ñ',
+	},
+}
+
+// None unless chosen with -compose
+var currentComposeTable composeTable
+
+func composeLanguages() []string {
+	languages := make([]string, 0, len(composeTables))
+	for language := range composeTables {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Language whose table produces most of the given special
+// characters, none when there are no such characters
+func detectLanguage(characters []rune) string {
+	best, bestCovered := "", 0
+	for _, language := range composeLanguages() {
+		produced := make(map[rune]bool)
+		for _, character := range composeTables[language] {
+			produced[character] = true
+		}
+		covered := 0
+		for _, character := range characters {
+			if produced[character] {
+				covered++
+			}
+		}
+		if covered > bestCovered {
+			best, bestCovered = language, covered
+		}
+	}
+	return best
+}
+
+func useComposeLanguage(language string, statistics *statisticsDatabase) error {
+	switch language {
+	case "off":
+		currentComposeTable = nil
+		return nil
+	case "auto":
+		language = statistics.language()
+		if _, exists := composeTables[language]; !exists {
+			return nil
+		}
+	}
+	table, exists := composeTables[language]
+	if !exists {
+		return fmt.Errorf("unknown compose language %q, available: auto, off, %s", language, strings.Join(composeLanguages(), ", "))
+	}
+	currentComposeTable = table
+	slog.Debug("Using compose sequences", "language", language)
+	return nil
+}
+
+// Replaces a sequence just completed before the cursor
+func (screen *quizScreen) composeBeforeCursor() {
+	if currentComposeTable == nil {
+		return
+	}
+	value := []rune(screen.inputField.Value())
+	position := screen.inputField.Position()
+	if position < 2 {
+		return
+	}
+	composed, exists := currentComposeTable[string(value[position-2:position])]
+	if !exists {
+		return
+	}
+	value = append(append(value[:position-2], composed), value[position:]...)
+	screen.inputField.SetValue(string(value))
+	screen.inputField.SetCursor(position - 1)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// A wrong answer which is the correct answer
+// to some other prompts of the deck
+type confusion struct {
+	prompt       prompt
+	wrongAnswer  string
+	confusedWith []prompt
+	count        int
+}
+
+func (statistics statisticsDatabase) findConfusions(mistakes map[prompt]mistakeRecord) []confusion {
+	promptsByAnswer := make(map[string][]prompt)
+	for prompt, answer := range statistics.Answers() {
+		answer = strings.TrimSpace(answer)
+		promptsByAnswer[answer] = append(promptsByAnswer[answer], prompt)
+	}
+	var confusions []confusion
+	for prompt, record := range mistakes {
+		if _, exists := statistics.Lookup(prompt); !exists {
+			continue
+		}
+		for wrongAnswer, count := range record.answers {
+			confusedWith := promptsByAnswer[wrongAnswer]
+			if len(confusedWith) == 0 {
+				continue
+			}
+			confusions = append(confusions, confusion{
+				prompt:       prompt,
+				wrongAnswer:  wrongAnswer,
+				confusedWith: confusedWith,
+				count:        int(count),
+			})
+		}
+	}
+	sort.Slice(confusions, func(i, j int) bool {
+		if confusions[i].count != confusions[j].count {
+			return confusions[i].count > confusions[j].count
+		}
+		return comparePrompts(confusions[i].prompt, confusions[j].prompt) < 0
+	})
+	return confusions
+}
+
+type confusionScreen struct {
+	confusions []confusion
+	listPosition
+}
+
+func newConfusionScreen(statistics *statisticsDatabase) confusionScreen {
+	return confusionScreen{
+		confusions: statistics.findConfusions(readMistakes()),
+	}
+}
+
+func (screen confusionScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen confusionScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back):
+			return screen, popScreen
+		case key.Matches(msg, keys.Down):
+			screen.scrollDown(len(screen.confusions), listShownRows())
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.scrollUp()
+			return screen, nil
+		}
+	}
+	return screen, nil
+}
+
+func (screen confusionScreen) renderConfusionEntry(confusion confusion, selected bool) string {
+	count := background.Bold(selected).Foreground(accentColor).Render(fmt.Sprintf("%d%s", confusion.count, symbols.times))
+	entry := fmt.Sprintf(
+		"%s + %s %s %s",
+		confusion.prompt.FormClue,
+		confusion.prompt.Verb,
+		symbols.arrow,
+		confusion.wrongAnswer,
+	)
+	if selected {
+		entry = "> " + entry
+	}
+	return promptStatsEntryStyle.
+		Bold(selected).
+		Italic(selected).
+		Width(boxWidth-lipgloss.Width(count)).
+		AlignHorizontal(lipgloss.Left).
+		Render(entry) +
+		count
+}
+
+func (confusion confusion) describe() string {
+	described := make([]string, len(confusion.confusedWith))
+	for i, prompt := range confusion.confusedWith {
+		described[i] = fmt.Sprintf("%s + %s", prompt.FormClue, prompt.Verb)
+	}
+	slices.SortFunc(described, compareText)
+	return "answer to " + strings.Join(described, ", ")
+}
+
+var confusionScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Up}, action: "up"},
+	{bindings: []*key.Binding{&keys.Down}, action: "down"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen confusionScreen) View() string {
+	screen.fit(listShownRows())
+	footer := renderHelpRow(confusionScreenHelp[:])
+	lines := []string{renderListTitle("Most confused answers", screen.listPosition, len(screen.confusions)), ""}
+	if len(screen.confusions) == 0 {
+		lines = append(lines, questionStatsAlignStyle.Render(questionStatsStyle.Render("no confusions found")))
+	}
+	for row := 0; row < listShownRows(); row++ {
+		index := screen.firstShownIndex + row
+		if index >= len(screen.confusions) {
+			break
+		}
+		lines = append(lines, screen.renderConfusionEntry(screen.confusions[index], row == screen.selectedRow))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	if selectedIndex := screen.selectedIndex(); selectedIndex < len(screen.confusions) {
+		footer = lipgloss.JoinVertical(
+			lipgloss.Left,
+			questionStatsAlignStyle.Render(questionStatsStyle.Inline(true).MaxWidth(boxWidth).Render(
+				screen.confusions[selectedIndex].describe(),
+			)),
+			footer,
+		)
+	}
+	return renderBox(body, footer)
+}
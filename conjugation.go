@@ -0,0 +1,253 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+	"golang.org/x/text/unicode/norm"
+)
+
+type conjugatedForm struct {
+	prompt prompt
+	answer string
+}
+
+// Forms of the verb in the order of the deck
+func (statistics statisticsDatabase) conjugation(verb string) []conjugatedForm {
+	var forms []conjugatedForm
+	for prompt, answer := range statistics.Answers() {
+		if prompt.Verb == verb {
+			forms = append(forms, conjugatedForm{prompt, answer})
+		}
+	}
+	return forms
+}
+
+// Verbs of the deck in alphabetical order
+func (statistics statisticsDatabase) verbs() []string {
+	seen := make(map[string]bool)
+	var verbs []string
+	for _, prompt := range statistics.Prompts() {
+		if !seen[prompt.Verb] {
+			seen[prompt.Verb] = true
+			verbs = append(verbs, prompt.Verb)
+		}
+	}
+	slices.SortFunc(verbs, compareText)
+	return verbs
+}
+
+// Every form of one verb for reference, with how each was answered
+type conjugationScreen struct {
+	statistics *statisticsDatabase
+	verb       string
+	forms      []conjugatedForm
+	listPosition
+}
+
+func newConjugationScreen(statistics *statisticsDatabase, verb string) conjugationScreen {
+	return conjugationScreen{
+		statistics: statistics,
+		verb:       verb,
+		forms:      statistics.conjugation(verb),
+	}
+}
+
+func (screen conjugationScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen conjugationScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back, keys.Conjugation):
+			return screen, popScreen
+		case key.Matches(msg, keys.Down):
+			screen.scrollDown(len(screen.forms), listShownRows())
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.scrollUp()
+			return screen, nil
+		}
+	case tea.MouseMsg:
+		screen.listPosition.handleMouse(msg, len(screen.forms), listShownRows())
+		return screen, nil
+	}
+	return screen, nil
+}
+
+// Answers line up in a column after the longest form clue
+func (screen conjugationScreen) renderFormEntry(form conjugatedForm, clueWidth int, selected bool) string {
+	statsTrisymbol := renderStatsTrisymbol(background.Bold(selected).Italic(selected), screen.statistics.Record(form.prompt))
+	clue := form.prompt.FormClue
+	if selected {
+		clue = "> " + clue
+		clueWidth += 2
+	}
+	entry := clue + strings.Repeat(" ", max(clueWidth-lipgloss.Width(clue), 0)+1) + symbols.arrow + " " + form.answer
+	return promptStatsEntryStyle.
+		Bold(selected).
+		Italic(selected).
+		Width(boxWidth-lipgloss.Width(statsTrisymbol)).
+		AlignHorizontal(lipgloss.Left).
+		Render(entry) +
+		statsTrisymbol
+}
+
+var conjugationScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Up}, action: "up"},
+	{bindings: []*key.Binding{&keys.Down}, action: "down"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen conjugationScreen) View() string {
+	screen.fit(listShownRows())
+	footer := renderHelpRow(conjugationScreenHelp[:])
+	lines := []string{renderListTitle(screen.verb, screen.listPosition, len(screen.forms)), ""}
+	if len(screen.forms) == 0 {
+		lines = append(lines, questionStatsAlignStyle.Render(questionStatsStyle.Render("no forms in the deck")))
+	}
+	clueWidth := 0
+	for _, form := range screen.forms {
+		clueWidth = max(clueWidth, lipgloss.Width(form.prompt.FormClue))
+	}
+	for row := 0; row < listShownRows(); row++ {
+		index := screen.firstShownIndex + row
+		if index >= len(screen.forms) {
+			break
+		}
+		lines = append(lines, screen.renderFormEntry(screen.forms[index], clueWidth, row == screen.selectedRow))
+	}
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), footer)
+}
+
+// Verbs of the deck narrowed down by typing, case and accents
+// aside, to open the conjugation table of one of them
+type verbListScreen struct {
+	statistics *statisticsDatabase
+	verbs      []string
+	shown      []string
+	search     textinput.Model
+	listPosition
+}
+
+func newVerbListScreen(statistics *statisticsDatabase) verbListScreen {
+	search := textinput.New()
+	search.Prompt = "> "
+	search.Placeholder = "type to search"
+	search.Focus()
+	verbs := statistics.verbs()
+	return verbListScreen{
+		statistics: statistics,
+		verbs:      verbs,
+		shown:      verbs,
+		search:     search,
+	}
+}
+
+// Lowercase letters without accents, so "etre" finds "être"
+func foldForSearch(text string) string {
+	var folded strings.Builder
+	for _, letter := range norm.NFC.String(text) {
+		folded.WriteRune(unicode.ToLower(baseLetter(letter)))
+	}
+	return folded.String()
+}
+
+func (screen *verbListScreen) applySearch() {
+	query := foldForSearch(strings.TrimSpace(screen.search.Value()))
+	screen.shown = nil
+	for _, verb := range screen.verbs {
+		if strings.Contains(foldForSearch(verb), query) {
+			screen.shown = append(screen.shown, verb)
+		}
+	}
+	screen.listPosition = listPosition{}
+}
+
+func (screen verbListScreen) isTyping() bool {
+	return true
+}
+
+func (screen verbListScreen) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Letters are typed into the search, so only the
+// arrow keys and the like move through the list
+func (screen verbListScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Menu):
+			return screen, popScreen
+		case key.Matches(msg, keys.Back) && screen.search.Value() == "":
+			return screen, popScreen
+		case key.Matches(msg, keys.Submit):
+			if index := screen.selectedIndex(); index < len(screen.shown) {
+				return screen, pushScreen(newConjugationScreen(screen.statistics, screen.shown[index]))
+			}
+			return screen, nil
+		case key.Matches(msg, keys.Down) && !isPrintableKey(msg.String()):
+			screen.scrollDown(len(screen.shown), screen.shownRows())
+			return screen, nil
+		case key.Matches(msg, keys.Up) && !isPrintableKey(msg.String()):
+			screen.scrollUp()
+			return screen, nil
+		}
+		before := screen.search.Value()
+		var cmd tea.Cmd
+		screen.search, cmd = screen.search.Update(msg)
+		if screen.search.Value() != before {
+			screen.applySearch()
+		}
+		return screen, cmd
+	case tea.MouseMsg:
+		screen.listPosition.handleMouse(msg, len(screen.shown), screen.shownRows())
+		return screen, nil
+	}
+	var cmd tea.Cmd
+	screen.search, cmd = screen.search.Update(msg)
+	return screen, cmd
+}
+
+// Search field takes the place of the detail line
+func (screen verbListScreen) shownRows() int {
+	return listShownRows() - 1
+}
+
+var verbListScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit}, action: "open"},
+	{bindings: []*key.Binding{&keys.Menu}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen verbListScreen) View() string {
+	shownRows := screen.shownRows()
+	screen.fit(shownRows)
+	screen.search.Width = boxWidth - lipgloss.Width(screen.search.Prompt) - 1
+	lines := []string{renderListTitle("Conjugations", screen.listPosition, len(screen.shown)), screen.search.View(), ""}
+	if len(screen.shown) == 0 {
+		lines = append(lines, questionStatsAlignStyle.Render(questionStatsStyle.Render("no verbs found")))
+	}
+	for row := 0; row < shownRows; row++ {
+		index := screen.firstShownIndex + row
+		if index >= len(screen.shown) {
+			break
+		}
+		verb := screen.shown[index]
+		selected := row == screen.selectedRow
+		if selected {
+			verb = "> " + verb
+		}
+		lines = append(lines, promptStatsEntryStyle.Bold(selected).Italic(selected).Width(boxWidth).Render(verb))
+	}
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), renderHelpRow(verbListScreenHelp[:]))
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+const (
+	sparklineDays       = 42
+	rollingAccuracyDays = 7
+)
+
+// Streak thresholds after which a question
+// is considered mature and mastered respectively
+const (
+	matureStreak   = 4
+	masteredStreak = 8
+)
+
+type dashboardScreen struct {
+	statistics *statisticsDatabase
+	history    *practiceHistory
+}
+
+type deckSummary struct {
+	questions uint32
+	started   uint32
+	mature    uint32
+	mastered  uint32
+	stages    [stageCount]uint32
+	correct   uint64
+	mistakes  uint64
+}
+
+func (statistics statisticsDatabase) summary() deckSummary {
+	var summary deckSummary
+	for _, stats := range statistics.All() {
+		summary.questions++
+		if stats.Correct > 0 || stats.Mistakes > 0 {
+			summary.started++
+		}
+		if stats.Streak >= matureStreak {
+			summary.mature++
+		}
+		if stats.Streak >= masteredStreak {
+			summary.mastered++
+		}
+		summary.stages[stageOf(stats)]++
+		summary.correct += uint64(stats.Correct)
+		summary.mistakes += uint64(stats.Mistakes)
+	}
+	return summary
+}
+
+func (screen dashboardScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen dashboardScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back, keys.Dashboard):
+			return screen, popScreen
+		case key.Matches(msg, keys.Calendar):
+			return screen, pushScreen(heatmapScreen{history: screen.history})
+		}
+	}
+	return screen, nil
+}
+
+func (history practiceHistory) renderSparkline(today time.Time) string {
+	var sparkline strings.Builder
+	for i := sparklineDays - 1; i >= 0; i-- {
+		accuracy, exists := history.rollingAccuracy(today.AddDate(0, 0, -i), rollingAccuracyDays)
+		if !exists {
+			sparkline.WriteString(symbols.empty)
+			continue
+		}
+		level := min(int(accuracy*float32(len(symbols.sparkline))), len(symbols.sparkline)-1)
+		sparkline.WriteRune(symbols.sparkline[level])
+	}
+	return sparkline.String()
+}
+
+func formatPercentage(part uint64, total uint64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", 100*float64(part)/float64(total))
+}
+
+func formatStudyTime(duration time.Duration) string {
+	hours := int(duration.Hours())
+	minutes := int(duration.Minutes()) % 60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+func renderAccuracyRow(label string, record dayRecord) string {
+	return formatAccuracy(label, record, bold)
+}
+
+// Numbers are passed through emphasize, so that
+// the same text works both in the UI and on stdout
+func formatAccuracy(label string, record dayRecord, emphasize func(string) string) string {
+	if record.answered() == 0 {
+		return fmt.Sprintf("%s: nothing answered", label)
+	}
+	return fmt.Sprintf(
+		"%s: %s answered, %s correct",
+		label,
+		emphasize(fmt.Sprint(record.answered())),
+		emphasize(formatPercentage(uint64(record.correct), uint64(record.answered()))),
+	)
+}
+
+func (screen dashboardScreen) renderSummary() []string {
+	summary := screen.statistics.summary()
+	answered := summary.correct + summary.mistakes
+	return []string{
+		fmt.Sprintf(
+			"Deck: %s questions, %s started, %s untouched",
+			bold(fmt.Sprint(summary.questions)),
+			bold(fmt.Sprint(summary.started)),
+			bold(fmt.Sprint(summary.questions-summary.started)),
+		),
+		renderStageCounts(summary.stages),
+		fmt.Sprintf(
+			"Lifetime: %s answered, %s correct",
+			bold(fmt.Sprint(answered)),
+			bold(formatPercentage(summary.correct, answered)),
+		),
+		fmt.Sprintf("Study time: %s", bold(formatStudyTime(screen.history.totalStudyTime()))),
+	}
+}
+
+var dashboardScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Calendar}, action: "calendar"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen dashboardScreen) View() string {
+	today := time.Now()
+	textStyle := background.Foreground(textColor).Width(boxWidth)
+	sparklineStyle := background.Foreground(highlightColor).Width(boxWidth)
+	axisStyle := background.Italic(true).Foreground(mutedColor)
+	axisLeft := axisStyle.Render(fmt.Sprintf("%d days ago", sparklineDays))
+	axisRight := axisStyle.Render("today")
+	axisSpacing := background.Render(strings.Repeat(
+		" ",
+		max(0, sparklineDays-lipgloss.Width(axisLeft)-lipgloss.Width(axisRight)),
+	))
+	lines := []string{statsTitleStyle.Render("Dashboard"), ""}
+	for _, line := range screen.renderSummary() {
+		lines = append(lines, textStyle.Render(line))
+	}
+	lines = append(
+		lines,
+		"",
+		textStyle.Render(fmt.Sprintf("Accuracy (%d-day rolling)", rollingAccuracyDays)),
+		sparklineStyle.Render(screen.history.renderSparkline(today)),
+		background.Width(boxWidth).Render(axisLeft+axisSpacing+axisRight),
+		textStyle.Render(renderAccuracyRow("Today", screen.history.day(today))),
+		textStyle.Render(renderAccuracyRow("Last week", screen.history.sumDays(today, 7))),
+		textStyle.Render(renderAccuracyRow(
+			fmt.Sprintf("Last %d days", sparklineDays),
+			screen.history.sumDays(today, sparklineDays),
+		)),
+	)
+	body := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	footer := renderHelpRow(dashboardScreenHelp[:])
+	return renderBox(body, footer)
+}
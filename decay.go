@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Questions not practiced for longer than it lose most of their streak
+// when the statistics are loaded, 0 trusts old streaks forever
+var streakDecayHorizon time.Duration
+
+// Offered by the settings screen, any other horizon can be given as a flag
+var streakDecayChoices = [...]time.Duration{0, 14 * 24 * time.Hour, 30 * 24 * time.Hour, 90 * 24 * time.Hour}
+
+func formatStreakDecay() string {
+	if streakDecayHorizon == 0 {
+		return "off"
+	}
+	if streakDecayHorizon%(24*time.Hour) == 0 {
+		return fmt.Sprintf("after %d days", streakDecayHorizon/(24*time.Hour))
+	}
+	return "after " + streakDecayHorizon.String()
+}
+
+// Streak left after the horizon passed once is at most half of the
+// mastered one, and the ceiling halves again with every further horizon.
+// It caps the streak rather than halving it, so loading statistics
+// which were saved after decaying takes nothing more from them.
+func (statistics statisticsDatabase) decayedStreak(prompt prompt, now time.Time) uint32 {
+	record := statistics.Record(prompt)
+	if streakDecayHorizon == 0 || record.LastPracticed.IsZero() {
+		return record.Streak
+	}
+	horizons := now.Sub(record.LastPracticed) / streakDecayHorizon
+	if horizons <= 0 {
+		return record.Streak
+	}
+	ceiling := uint32(masteredStreak) >> min(horizons, 31)
+	return min(record.Streak, ceiling)
+}
+
+// Returning after a long break asks the stale questions again
+// as often as the weak ones, rather than as if practiced yesterday
+func (statistics *statisticsDatabase) decayStaleStreaks(now time.Time) {
+	decayed := 0
+	for prompt, record := range statistics.All() {
+		streak := statistics.decayedStreak(prompt, now)
+		if streak == record.Streak {
+			continue
+		}
+		record.Streak = streak
+		statistics.UpdateStats(prompt, record)
+		decayed++
+	}
+	if decayed > 0 {
+		slog.Info("Decayed stale streaks", "questions", decayed, "horizon", streakDecayHorizon)
+	}
+}
@@ -0,0 +1,418 @@
+// Package deck reads the word database: a table with a verb in every
+// row, a form clue above every column and the forms in between.
+package deck
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	excelize "github.com/xuri/excelize/v2"
+)
+
+type Deck struct {
+	FormClues []string
+	Verbs     []string
+	// Forms of the verb in every row, one per form clue,
+	// rows may be shorter when their last forms are missing
+	Forms [][]string
+	// Audio clips and images of every row, one per form clue, nil
+	// when the table has no such columns or the row has no files
+	Audio  [][]string
+	Images [][]string
+	// Declared on the settings sheet, empty ones are not declared
+	Settings Settings
+}
+
+// Read from a sheet named "settings" after the table, a name in the
+// first column and its value in the second, so they are shared with
+// the deck. Values are checked where they are used.
+type Settings struct {
+	// Such as german, instead of telling it by the special characters
+	Language string
+	// How closely answers must match, such as ignore-case
+	AnswerChecking string
+	// Separates answers accepted alike in a cell, such as "/"
+	AnswerDelimiter string
+	// Text to speech voice for forms without an audio clip
+	Voice string
+}
+
+const settingsSheet = "settings"
+
+// Names as written in the first column of the settings sheet
+func (settings *Settings) fields() map[string]*string {
+	return map[string]*string{
+		"language":         &settings.Language,
+		"answer checking":  &settings.AnswerChecking,
+		"answer delimiter": &settings.AnswerDelimiter,
+		"voice":            &settings.Voice,
+	}
+}
+
+// Headers of columns holding a file or URL for every form of the row,
+// "audio: clue" or "image: clue" hold files for just that form clue
+const (
+	audioHeader = "audio"
+	imageHeader = "image"
+)
+
+// Where the form clues and the media files are in a row
+type columnLayout struct {
+	formColumns []int
+	// Files for the whole row come before the ones for a single form clue
+	audioColumns []mediaColumn
+	imageColumns []mediaColumn
+}
+
+type mediaColumn struct {
+	column int
+	// Index of the form clue, -1 for all of them
+	clue int
+}
+
+type Prompt struct {
+	FormClue string
+	Verb     string
+}
+
+func (prompt Prompt) String() string {
+	return fmt.Sprintf("%s + %s", prompt.FormClue, prompt.Verb)
+}
+
+// Progress is reported every this many rows
+const progressInterval = 500
+
+// Called with the number of rows read so far and the number of rows
+// the sheet claims to have, which is 0 when the sheet does not say
+type Progress func(read int, total int)
+
+// Reads the first sheet, the second column holds the verbs
+// and the forms start from the third one
+func Read(path string) (Deck, error) {
+	return ReadWithProgress(path, nil)
+}
+
+// Rows are streamed rather than loaded at once,
+// so memory stays bounded by the parsed deck itself
+func ReadWithProgress(path string, progress Progress) (Deck, error) {
+	table, err := excelize.OpenFile(path)
+	if err != nil {
+		return Deck{}, err
+	}
+	defer table.Close()
+
+	sheet := table.GetSheetList()[0]
+	total := sheetRowCount(table, sheet)
+	rows, err := table.Rows(sheet)
+	if err != nil {
+		return Deck{}, err
+	}
+	defer rows.Close()
+	var deck Deck
+	var layout columnLayout
+	read := 0
+	for rows.Next() {
+		row, err := rows.Columns()
+		if err != nil {
+			return Deck{}, fmt.Errorf("row %d: %w", read+1, err)
+		}
+		if read == 0 {
+			if len(row) < 2 {
+				return Deck{}, errors.New("header row has less than 2 columns")
+			}
+			deck.FormClues, layout, err = parseHeader(row)
+			if err != nil {
+				return Deck{}, err
+			}
+		} else {
+			deck.addRow(row, layout)
+		}
+		read++
+		if progress != nil && read%progressInterval == 0 {
+			progress(read, total)
+		}
+	}
+	if err := rows.Error(); err != nil {
+		return Deck{}, err
+	}
+	if read < 2 {
+		return Deck{}, errors.New("table contains less than 2 lines")
+	}
+	if progress != nil {
+		progress(read, total)
+	}
+	deck.Settings, err = readSettings(table)
+	if err != nil {
+		return Deck{}, err
+	}
+	return deck, nil
+}
+
+// Names are matched ignoring case, blank rows are skipped. The delimiter
+// is not trimmed, as a space is a delimiter as good as any other.
+func readSettings(table *excelize.File) (Settings, error) {
+	var settings Settings
+	index, err := table.GetSheetIndex(settingsSheet)
+	if err != nil || index <= 0 {
+		return settings, err
+	}
+	rows, err := table.GetRows(table.GetSheetName(index))
+	if err != nil {
+		return settings, err
+	}
+	fields := settings.fields()
+	for i, row := range rows {
+		if len(row) == 0 || strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(row[0]))
+		field, known := fields[name]
+		if !known {
+			return Settings{}, fmt.Errorf("settings row %d: unknown setting %q", i+1, row[0])
+		}
+		value := ""
+		if len(row) > 1 {
+			value = row[1]
+		}
+		if field != &settings.AnswerDelimiter {
+			value = strings.TrimSpace(value)
+		}
+		*field = value
+	}
+	return settings, nil
+}
+
+// Every column from the third one is a form clue unless it is a media column
+func parseHeader(header []string) ([]string, columnLayout, error) {
+	var clues []string
+	var layout columnLayout
+	for column := 2; column < len(header); column++ {
+		if _, _, isMedia := parseMediaHeader(header[column]); !isMedia {
+			clues = append(clues, header[column])
+			layout.formColumns = append(layout.formColumns, column)
+		}
+	}
+	// Media columns may come before the clue they name
+	for column := 2; column < len(header); column++ {
+		kind, clue, isMedia := parseMediaHeader(header[column])
+		if !isMedia {
+			continue
+		}
+		media := mediaColumn{column, -1}
+		if clue != "" {
+			media.clue = slices.Index(clues, clue)
+			if media.clue < 0 {
+				return nil, columnLayout{}, fmt.Errorf("%s column %q names no form clue", kind, header[column])
+			}
+		}
+		if kind == audioHeader {
+			layout.audioColumns = append(layout.audioColumns, media)
+		} else {
+			layout.imageColumns = append(layout.imageColumns, media)
+		}
+	}
+	// Files for a single form clue replace the ones for the whole row
+	rowFirst := func(a, b mediaColumn) int {
+		return cmp.Compare(min(a.clue, 0), min(b.clue, 0))
+	}
+	slices.SortStableFunc(layout.audioColumns, rowFirst)
+	slices.SortStableFunc(layout.imageColumns, rowFirst)
+	return clues, layout, nil
+}
+
+func parseMediaHeader(header string) (kind string, clue string, isMedia bool) {
+	name, clue, _ := strings.Cut(header, ":")
+	kind = strings.ToLower(strings.TrimSpace(name))
+	return kind, strings.TrimSpace(clue), kind == audioHeader || kind == imageHeader
+}
+
+// Rows too short to have a verb, such as
+// blank ones, are kept without any forms
+func (deck *Deck) addRow(row []string, layout columnLayout) {
+	verb := ""
+	if len(row) >= 2 {
+		verb = row[1]
+	}
+	var forms []string
+	for _, column := range layout.formColumns {
+		if column >= len(row) {
+			break
+		}
+		forms = append(forms, row[column])
+	}
+	deck.Verbs = append(deck.Verbs, verb)
+	deck.Forms = append(deck.Forms, forms)
+	if len(layout.audioColumns) > 0 {
+		deck.Audio = append(deck.Audio, layout.files(layout.audioColumns, row))
+	}
+	if len(layout.imageColumns) > 0 {
+		deck.Images = append(deck.Images, layout.files(layout.imageColumns, row))
+	}
+}
+
+func (layout columnLayout) files(columns []mediaColumn, row []string) []string {
+	var files []string
+	for _, media := range columns {
+		if media.column >= len(row) || strings.TrimSpace(row[media.column]) == "" {
+			continue
+		}
+		if files == nil {
+			files = make([]string, len(layout.formColumns))
+		}
+		file := strings.TrimSpace(row[media.column])
+		if media.clue >= 0 {
+			files[media.clue] = file
+			continue
+		}
+		for clue := range files {
+			files[clue] = file
+		}
+	}
+	return files
+}
+
+// Writes the deck as a table Read reads back, with an empty first column
+// and no media columns, for decks made from something else than a table
+func Write(path string, deck Deck) error {
+	table := excelize.NewFile()
+	defer table.Close()
+	sheet := table.GetSheetList()[0]
+	header := []any{"", "verb"}
+	for _, clue := range deck.FormClues {
+		header = append(header, clue)
+	}
+	if err := table.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+	for verbIndex, verb := range deck.Verbs {
+		row := []any{"", verb}
+		for _, form := range deck.Forms[verbIndex] {
+			row = append(row, form)
+		}
+		cell, err := excelize.CoordinatesToCellName(1, verbIndex+2)
+		if err != nil {
+			return err
+		}
+		if err := table.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	if err := writeSettings(table, deck.Settings); err != nil {
+		return err
+	}
+	return table.SaveAs(path)
+}
+
+// Writes a plain table of a single sheet, for
+// reports about a deck rather than a deck itself
+func WriteRows(path string, header []string, rows [][]string) error {
+	table := excelize.NewFile()
+	defer table.Close()
+	sheet := table.GetSheetList()[0]
+	for i, cells := range append([][]string{header}, rows...) {
+		row := make([]any, len(cells))
+		for j, cell := range cells {
+			row[j] = cell
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := table.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	return table.SaveAs(path)
+}
+
+// The settings sheet is only added when something is declared
+func writeSettings(table *excelize.File, settings Settings) error {
+	var rows [][]any
+	for name, field := range settings.fields() {
+		if *field != "" {
+			rows = append(rows, []any{name, *field})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	slices.SortFunc(rows, func(a []any, b []any) int {
+		return cmp.Compare(a[0].(string), b[0].(string))
+	})
+	if _, err := table.NewSheet(settingsSheet); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if err := table.SetSheetRow(settingsSheet, fmt.Sprintf("A%d", i+1), &row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Taken from the dimension the sheet records, such as A1:F8001
+func sheetRowCount(table *excelize.File, sheet string) int {
+	dimension, err := table.GetSheetDimension(sheet)
+	if err != nil {
+		return 0
+	}
+	_, last, found := strings.Cut(dimension, ":")
+	if !found {
+		return 0
+	}
+	_, rows, err := excelize.CellNameToCoordinates(last)
+	if err != nil {
+		return 0
+	}
+	return rows
+}
+
+// Form of the verb for the clue, empty when the cell is
+func (deck Deck) Form(verbIndex int, clueIndex int) string {
+	forms := deck.Forms[verbIndex]
+	if len(forms) <= clueIndex {
+		return ""
+	}
+	return forms[clueIndex]
+}
+
+// Audio clip of the form as written in the table, empty when there is none
+func (deck Deck) Clip(verbIndex int, clueIndex int) string {
+	return mediaFile(deck.Audio, verbIndex, clueIndex)
+}
+
+// Image of the form as written in the table, empty when there is none
+func (deck Deck) Image(verbIndex int, clueIndex int) string {
+	return mediaFile(deck.Images, verbIndex, clueIndex)
+}
+
+func mediaFile(files [][]string, verbIndex int, clueIndex int) string {
+	if verbIndex >= len(files) || clueIndex >= len(files[verbIndex]) {
+		return ""
+	}
+	return files[verbIndex][clueIndex]
+}
+
+type Question struct {
+	Prompt Prompt
+	Answer string
+}
+
+// Questions are the cells which have a form in them, in the order of
+// the table, empty cells are counted as missing and are not asked
+func (deck Deck) Questions() (questions []Question, missing int) {
+	for verbIndex, verb := range deck.Verbs {
+		for clueIndex, clue := range deck.FormClues {
+			form := deck.Form(verbIndex, clueIndex)
+			if form == "" {
+				missing++
+				continue
+			}
+			questions = append(questions, Question{Prompt{clue, verb}, form})
+		}
+	}
+	return questions, missing
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+// Bump when deck.Deck changes, older caches are then read again
+const deckCacheVersion = 4
+
+// Parsed deck along with what the table looked like when it was
+// parsed, the cache is only used while the table stays the same
+type deckCache struct {
+	Version  int
+	Path     string
+	Size     int64
+	Modified time.Time
+	Deck     deck.Deck
+}
+
+// One file per deck, named after its absolute path.
+// Empty when there is nowhere to keep it.
+func deckCachePath(path string) string {
+	cache := cacheDirectory()
+	absolute, err := filepath.Abs(path)
+	if cache == "" || err != nil {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(absolute))
+	return filepath.Join(cache, "decks", hex.EncodeToString(hash[:8])+".gob")
+}
+
+func (cache deckCache) matches(path string, info fs.FileInfo) bool {
+	absolute, _ := filepath.Abs(path)
+	return cache.Version == deckCacheVersion &&
+		cache.Path == absolute &&
+		cache.Size == info.Size() &&
+		cache.Modified.Equal(info.ModTime())
+}
+
+// Problems with the cache only mean parsing the table again. A deck
+// cached before the table changed is returned too, as previous, so
+// that the table can be compared with what it was on the last run.
+func readCachedDeck(path string) (cached deck.Deck, fresh bool, previous *deck.Deck) {
+	cachePath := deckCachePath(path)
+	info, err := os.Stat(path)
+	if cachePath == "" || err != nil {
+		return deck.Deck{}, false, nil
+	}
+	file, err := os.Open(cachePath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Failed to open deck cache", "path", cachePath, "error", err)
+		}
+		return deck.Deck{}, false, nil
+	}
+	defer file.Close()
+	var cache deckCache
+	if err := gob.NewDecoder(file).Decode(&cache); err != nil {
+		slog.Warn("Failed to decode deck cache", "path", cachePath, "error", err)
+		return deck.Deck{}, false, nil
+	}
+	if !cache.matches(path, info) {
+		slog.Debug("Deck changed since it was cached", "path", path)
+		if cache.Version != deckCacheVersion {
+			return deck.Deck{}, false, nil
+		}
+		return deck.Deck{}, false, &cache.Deck
+	}
+	slog.Debug("Using cached deck", "path", path, "cache", cachePath)
+	return cache.Deck, true, nil
+}
+
+// Written in read-only mode as well, the cache holds nothing of the
+// user's own and is replaced atomically should instances race
+func cacheDeck(path string, parsed deck.Deck) {
+	cachePath := deckCachePath(path)
+	info, err := os.Stat(path)
+	if cachePath == "" || err != nil {
+		return
+	}
+	absolute, _ := filepath.Abs(path)
+	cache := deckCache{
+		Version:  deckCacheVersion,
+		Path:     absolute,
+		Size:     info.Size(),
+		Modified: info.ModTime(),
+		Deck:     parsed,
+	}
+	var encoded bytes.Buffer
+	if err := gob.NewEncoder(&encoded).Encode(cache); err != nil {
+		slog.Error("Failed to encode deck cache", "error", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		slog.Warn("Failed to create cache directory", "path", filepath.Dir(cachePath), "error", err)
+		return
+	}
+	if err := writeFileAtomic(cachePath, encoded.Bytes(), 0); err != nil {
+		slog.Warn("Failed to write deck cache", "path", cachePath, "error", err)
+		return
+	}
+	slog.Debug("Deck cached", "path", path, "cache", cachePath)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+// Questions of the deck which differ from the deck read on the last run
+type deckChanges struct {
+	added   []prompt
+	removed []prompt
+	changed []deck.Question
+	// Answers of the changed questions on the last run, by index
+	oldAnswers []string
+}
+
+// Questions keep the order of the deck
+func compareDecks(old deck.Deck, new deck.Deck) deckChanges {
+	oldQuestions, _ := old.Questions()
+	newQuestions, _ := new.Questions()
+	oldAnswers := make(map[prompt]string, len(oldQuestions))
+	for _, question := range oldQuestions {
+		oldAnswers[question.Prompt] = question.Answer
+	}
+	var changes deckChanges
+	kept := make(map[prompt]bool, len(newQuestions))
+	for _, question := range newQuestions {
+		kept[question.Prompt] = true
+		oldAnswer, existed := oldAnswers[question.Prompt]
+		switch {
+		case !existed:
+			changes.added = append(changes.added, question.Prompt)
+		case oldAnswer != question.Answer:
+			changes.changed = append(changes.changed, question)
+			changes.oldAnswers = append(changes.oldAnswers, oldAnswer)
+		}
+	}
+	for _, question := range oldQuestions {
+		if !kept[question.Prompt] {
+			changes.removed = append(changes.removed, question.Prompt)
+		}
+	}
+	return changes
+}
+
+func (changes deckChanges) isEmpty() bool {
+	return len(changes.added)+len(changes.removed)+len(changes.changed) == 0
+}
+
+func (changes deckChanges) lines() []string {
+	var lines []string
+	for _, prompt := range changes.added {
+		lines = append(lines, "+ "+prompt.String())
+	}
+	for _, prompt := range changes.removed {
+		lines = append(lines, "- "+prompt.String())
+	}
+	for i, question := range changes.changed {
+		lines = append(lines, fmt.Sprintf("~ %s: %s %s %s", question.Prompt, changes.oldAnswers[i], symbols.arrow, question.Answer))
+	}
+	return lines
+}
+
+// Shown on startup before anything else when the deck was edited since
+// the last run, questions are compared with the deck cached back then
+type deckChangesScreen struct {
+	changes deckChanges
+	next    tea.Model
+}
+
+func (screen deckChangesScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen deckChangesScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Submit) {
+			return screen, replaceScreen(screen.next)
+		}
+	}
+	return screen, nil
+}
+
+var deckChangesHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit}, action: "continue"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen deckChangesScreen) View() string {
+	summary := fmt.Sprintf(
+		"%d added, %d removed, %d changed",
+		len(screen.changes.added),
+		len(screen.changes.removed),
+		len(screen.changes.changed),
+	)
+	lines := []string{statsTitleStyle.Render("Deck changed since the last run"), questionStatsStyle.Render(summary), ""}
+	// Title, summary and blank line take the place of the list title
+	shown := listShownRows() - 1
+	changes := screen.changes.lines()
+	for i, change := range changes {
+		if i == shown-1 && len(changes) > shown {
+			lines = append(lines, questionStatsStyle.Render(fmt.Sprintf("and %d more", len(changes)-i)))
+			break
+		}
+		lines = append(lines, promptStatsEntryStyle.MaxWidth(boxWidth).Render(change))
+	}
+	footer := lipgloss.JoinVertical(
+		lipgloss.Left,
+		questionStatsAlignStyle.Render(questionStatsStyle.Render("statistics of removed questions are kept")),
+		renderHelpRow(deckChangesHelp[:]),
+	)
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), footer)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+// Declared by the settings sheet of the deck, empty when it does not
+var (
+	declaredLanguage string
+	answerDelimiter  string
+	speechVoice      string
+)
+
+// Flags given explicitly win over the deck,
+// which wins over settings remembered from the last run
+func useDeckSettings(settings deck.Settings, explicit map[string]bool) error {
+	declaredLanguage = strings.ToLower(settings.Language)
+	answerDelimiter = settings.AnswerDelimiter
+	speechVoice = settings.Voice
+	if settings.AnswerChecking != "" && !explicit["answer-checking"] {
+		if err := answerChecking.Set(settings.AnswerChecking); err != nil {
+			return fmt.Errorf("answer checking of the deck: %w", err)
+		}
+	}
+	slog.Debug("Using deck settings", "settings", settings)
+	return nil
+}
+
+func useDeckSettingsOrExit(settings deck.Settings, explicit map[string]bool) {
+	if err := useDeckSettings(settings, explicit); err != nil {
+		logFatal("Invalid deck settings", "path", wordDatabasePath, "error", err)
+		fmt.Fprintf(os.Stderr, "%s: %v\n", wordDatabasePath, err)
+		exit(databaseError)
+	}
+}
+
+// Declared by the deck, or else told by its special characters
+func (statistics statisticsDatabase) language() string {
+	if declaredLanguage != "" {
+		return declaredLanguage
+	}
+	return detectLanguage(statistics.specialCharacters())
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Answers worth of the deck average every question starts from,
+// so a single unlucky answer does not make a question hard
+const difficultyPriorAnswers = 3
+
+// Questions asked in a row from easiest to hardest in ladder order
+const ladderLength = 10
+
+// Averages of the whole deck, questions are estimated against them
+type difficultyEstimator struct {
+	errorRate       float64
+	responseSeconds float64
+}
+
+func (statistics statisticsDatabase) difficultyEstimator() difficultyEstimator {
+	var answers, mistakes uint64
+	var seconds float64
+	timed := 0
+	for _, record := range statistics.All() {
+		answers += uint64(record.Correct + record.Mistakes)
+		mistakes += uint64(record.Mistakes)
+		if record.ResponseSeconds > 0 {
+			seconds += float64(record.ResponseSeconds)
+			timed++
+		}
+	}
+	var estimator difficultyEstimator
+	if answers > 0 {
+		estimator.errorRate = float64(mistakes) / float64(answers)
+	}
+	if timed > 0 {
+		estimator.responseSeconds = seconds / float64(timed)
+	}
+	return estimator
+}
+
+// From 0 for easy to 1 for hard, mostly how often the question is
+// answered wrong, partly how much slower than the others it is answered.
+// Questions never answered are as hard as the deck on average.
+func (estimator difficultyEstimator) estimate(record questionStats) float64 {
+	answers := float64(record.Correct + record.Mistakes)
+	errorRate := (float64(record.Mistakes) + difficultyPriorAnswers*estimator.errorRate) /
+		(answers + difficultyPriorAnswers)
+	// Half at the deck average, approaching 1 when much slower
+	slowness := 0.5
+	if record.ResponseSeconds > 0 && estimator.responseSeconds > 0 {
+		seconds := float64(record.ResponseSeconds)
+		slowness = seconds / (seconds + estimator.responseSeconds)
+	}
+	return 0.7*errorRate + 0.3*slowness
+}
+
+func (estimator difficultyEstimator) badge(record questionStats) string {
+	if record.Correct+record.Mistakes == 0 {
+		return ""
+	}
+	switch difficulty := estimator.estimate(record); {
+	case difficulty < 0.25:
+		return background.Foreground(mutedColor).Render("easy")
+	case difficulty < 0.45:
+		return background.Foreground(textColor).Render("medium")
+	default:
+		return background.Foreground(accentColor).Render("hard")
+	}
+}
+
+// Badge goes in front of the statistics of a list entry
+func (estimator difficultyEstimator) withBadge(record questionStats, statistics string) string {
+	badge := estimator.badge(record)
+	if badge == "" {
+		return statistics
+	}
+	return badge + background.Render("  ") + statistics
+}
+
+// Questions the usual selection picks next, ordered from easy to hard,
+// so the selection decides which questions and the ladder in what order
+func (statistics statisticsDatabase) climbLadder(count int) []prompt {
+	picked := map[prompt]bool{}
+	var ladder []prompt
+	// Draws repeat, a small deck may not have count distinct questions
+	for draw := 0; draw < 4*count && len(ladder) < count; draw++ {
+		prompt := statistics.nextPrompt()
+		if !picked[prompt] {
+			picked[prompt] = true
+			ladder = append(ladder, prompt)
+		}
+	}
+	estimator := statistics.difficultyEstimator()
+	slices.SortStableFunc(ladder, func(a prompt, b prompt) int {
+		return cmp.Compare(estimator.estimate(statistics.Record(a)), estimator.estimate(statistics.Record(b)))
+	})
+	return ladder
+}
+
+// Next question of the ladder, climbing a new one once at the top
+func (screen *quizScreen) nextRung() question {
+	if len(screen.ladder) == 0 {
+		screen.ladder = screen.statistics.climbLadder(ladderLength)
+	}
+	prompt := screen.ladder[0]
+	screen.ladder = screen.ladder[1:]
+	return question{prompt, screen.statistics.Answer(prompt)}
+}
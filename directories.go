@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+const applicationDirectory = "gem2"
+
+// Base directory named by an XDG Base Directory Specification
+// variable, which requires ignoring relative values, or else the
+// platform default. Empty when even that is unknown.
+func baseDirectory(variable string, platformDefault func() (string, error)) string {
+	if value := os.Getenv(variable); filepath.IsAbs(value) {
+		return filepath.Join(value, applicationDirectory)
+	}
+	base, err := platformDefault()
+	if err != nil || base == "" {
+		return ""
+	}
+	return filepath.Join(base, applicationDirectory)
+}
+
+func dataDirectory() string {
+	return baseDirectory("XDG_DATA_HOME", defaultDataHome)
+}
+
+func stateDirectory() string {
+	return baseDirectory("XDG_STATE_HOME", defaultStateHome)
+}
+
+func configDirectory() string {
+	return baseDirectory("XDG_CONFIG_HOME", defaultConfigHome)
+}
+
+func cacheDirectory() string {
+	return baseDirectory("XDG_CACHE_HOME", defaultCacheHome)
+}
+
+// Default location of a file which used
+// to be kept in the working directory
+type standardLocation struct {
+	path      *string
+	directory string
+	backups   int
+	// Deck is used from the working directory as long as it is there,
+	// as it is often edited by hand there, rather than moved out of it
+	inPlace bool
+}
+
+func standardLocations() []standardLocation {
+	data, state := dataDirectory(), stateDirectory()
+	return []standardLocation{
+		{path: &wordDatabasePath, directory: data, inPlace: true},
+		{path: &statisticsPath, directory: state, backups: statisticsBackups},
+		{path: &historyPath, directory: state},
+		{path: &mistakesPath, directory: state},
+		{path: &notesPath, directory: state},
+		{path: &achievementsPath, directory: state},
+		{path: &profilesDirectory, directory: state},
+	}
+}
+
+// Only decides where files are, nothing is moved yet, so that the
+// log can be opened in its new place before migration is logged
+func useStandardDirectories() {
+	state := stateDirectory()
+	if state == "" {
+		return
+	}
+	logPath = filepath.Join(state, logPath)
+	lockPath = filepath.Join(state, lockPath)
+	if !readOnly {
+		if err := os.MkdirAll(state, 0755); err != nil {
+			// Writing will fail later on and is reported there
+			slog.Error("Failed to create state directory", "path", state, "error", err)
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+func moveFile(from string, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	// Renaming fails across file systems, directories
+	// are not worth copying so they stay where they are
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return errors.New("can not move a directory to another file system")
+	}
+	if err := copyFile(from, to); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+// Returns where the file should be used from: the standard location
+// unless the old one could not be moved, must not be in read-only
+// mode, or is used in place
+func (location standardLocation) adopt(legacy string) string {
+	path := filepath.Join(location.directory, filepath.Base(legacy))
+	if location.inPlace && fileExists(legacy) {
+		return legacy
+	}
+	if fileExists(path) || !fileExists(legacy) {
+		return path
+	}
+	if readOnly {
+		return legacy
+	}
+	if err := os.MkdirAll(location.directory, 0755); err != nil {
+		slog.Error("Failed to create directory", "path", location.directory, "error", err)
+		return legacy
+	}
+	if err := moveFile(legacy, path); err != nil {
+		slog.Error("Failed to move file to standard location", "from", legacy, "to", path, "error", err)
+		return legacy
+	}
+	for i := 1; i <= location.backups; i++ {
+		if err := moveFile(backupPath(legacy, i), backupPath(path, i)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Failed to move backup to standard location", "from", backupPath(legacy, i), "error", err)
+		}
+	}
+	slog.Info("Moved file to standard location", "from", legacy, "to", path)
+	return path
+}
+
+// Files given explicitly are left where they are
+func migrateFromWorkingDirectory(paths pathOptions) {
+	if stateDirectory() == "" {
+		slog.Warn("Home directory is unknown, keeping files in the working directory")
+		return
+	}
+	explicit := map[*string]bool{
+		&wordDatabasePath: paths.deck != "",
+		&statisticsPath:   paths.stats != "",
+		&historyPath:      paths.stats != "",
+		&notesPath:        paths.stats != "",
+		&achievementsPath: paths.stats != "",
+		&mistakesPath:     paths.mistakes != "",
+	}
+	for _, location := range standardLocations() {
+		if location.directory == "" || explicit[location.path] {
+			continue
+		}
+		*location.path = location.adopt(*location.path)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build windows || darwin
+
+package main
+
+import "os"
+
+// Both platforms have a single per-user place for application files:
+// %AppData% on Windows, Application Support on macOS, which
+// os.UserConfigDir returns, so data, state and config share it
+func defaultDataHome() (string, error) {
+	return os.UserConfigDir()
+}
+
+func defaultStateHome() (string, error) {
+	return os.UserConfigDir()
+}
+
+func defaultConfigHome() (string, error) {
+	return os.UserConfigDir()
+}
+
+// Caches are the one thing both platforms keep elsewhere
+func defaultCacheHome() (string, error) {
+	return os.UserCacheDir()
+}
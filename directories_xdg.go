@@ -0,0 +1,32 @@
+//go:build !windows && !darwin
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func homeSubdirectory(parts ...string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{home}, parts...)...), nil
+}
+
+func defaultDataHome() (string, error) {
+	return homeSubdirectory(".local", "share")
+}
+
+func defaultStateHome() (string, error) {
+	return homeSubdirectory(".local", "state")
+}
+
+func defaultConfigHome() (string, error) {
+	return homeSubdirectory(".config")
+}
+
+func defaultCacheHome() (string, error) {
+	return homeSubdirectory(".cache")
+}
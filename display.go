@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var colorProfiles = map[string]termenv.Profile{
+	"never": termenv.Ascii,
+	"16":    termenv.ANSI,
+	"256":   termenv.ANSI256,
+	"true":  termenv.TrueColor,
+}
+
+// How the UI is drawn, as opposed to what is practiced
+type displayOptions struct {
+	layout     string
+	theme      string
+	color      string
+	background string
+	ascii      bool
+}
+
+func (options *displayOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&options.layout, "layout", "box", "`layout` of the screen: box, full to use the whole terminal with statistics on the side, or compact for a few lines without the box")
+	flags.StringVar(&options.theme, "theme", defaultThemeName, "color theme `name`, user themes are read from the themes config directory")
+	flags.StringVar(&options.color, "color", "auto", "color `support` of the terminal: auto, never, 16, 256 or true")
+	flags.StringVar(&options.background, "background", "auto", "`brightness` of the terminal background, auto, dark or light, picks the palette of the theme")
+	flags.BoolVar(&options.ascii, "ascii", false, "draw with ASCII characters only, the default for non-UTF-8 locales")
+}
+
+// Locale is taken from the first variable set, the same way as C programs do
+func isUTF8Locale() bool {
+	for _, variable := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(variable); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	// Nothing set usually means a modern terminal with the C locale
+	return true
+}
+
+// Detection by lipgloss already respects NO_COLOR,
+// colors given explicitly take precedence over it
+func (options displayOptions) apply() error {
+	if options.color != "auto" {
+		profile, exists := colorProfiles[options.color]
+		if !exists {
+			return fmt.Errorf("unknown color support %q, expected auto, never, 16, 256 or true", options.color)
+		}
+		lipgloss.SetColorProfile(profile)
+	}
+	if err := currentLayout.Set(options.layout); err != nil {
+		return err
+	}
+	switch options.background {
+	case "auto":
+		darkBackground = lipgloss.HasDarkBackground()
+	case "dark", "light":
+		darkBackground = options.background == "dark"
+	default:
+		return fmt.Errorf("unknown background %q, expected auto, dark or light", options.background)
+	}
+	if options.ascii || !isUTF8Locale() {
+		symbols = asciiSymbols
+	}
+	loadUserThemes()
+	return useTheme(options.theme)
+}
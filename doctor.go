@@ -0,0 +1,262 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+type findingLevel int
+
+const (
+	findingOK findingLevel = iota
+	findingWarning
+	findingProblem
+)
+
+var findingLevelNames = [...]string{"ok", "warning", "problem"}
+
+// What was checked and what to do when it is not fine
+type finding struct {
+	level   findingLevel
+	subject string
+	text    string
+	hint    string
+}
+
+// Most trouble comes from the terminal or the files around the
+// program rather than from it, so these are checked in one go
+type diagnosis struct {
+	findings []finding
+}
+
+func (diagnosis *diagnosis) add(level findingLevel, subject string, text string, hint string) {
+	diagnosis.findings = append(diagnosis.findings, finding{level, subject, text, hint})
+}
+
+func (diagnosis diagnosis) hasProblems() bool {
+	for _, finding := range diagnosis.findings {
+		if finding.level == findingProblem {
+			return true
+		}
+	}
+	return false
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (diagnosis *diagnosis) checkTerminal() {
+	term := os.Getenv("TERM")
+	switch {
+	case !isTerminal(os.Stdout):
+		diagnosis.add(findingWarning, "terminal", "output is not a terminal, the checks describe whatever it is redirected to",
+			"run gem2 doctor in the terminal the quiz is practiced in")
+	case term == "" || term == "dumb":
+		diagnosis.add(findingProblem, "terminal", fmt.Sprintf("TERM is %q, the screen can not be redrawn in place", term),
+			"set TERM to the terminal type, xterm-256color for most terminal emulators")
+	default:
+		diagnosis.add(findingOK, "terminal", "TERM is "+term, "")
+	}
+}
+
+// Profile is the one detected, the color flag is not applied here
+func (diagnosis *diagnosis) checkColors() {
+	if _, set := os.LookupEnv("NO_COLOR"); set {
+		diagnosis.add(findingWarning, "colors", "NO_COLOR is set, everything is drawn without colors",
+			"unset NO_COLOR, or pass -color 256 to have colors anyway")
+		return
+	}
+	switch lipgloss.ColorProfile() {
+	case termenv.Ascii:
+		diagnosis.add(findingWarning, "colors", "no color support detected",
+			"set COLORTERM=truecolor or TERM=xterm-256color if the terminal has colors, or pass -color 256")
+	case termenv.ANSI:
+		diagnosis.add(findingWarning, "colors", "only 16 colors detected, themes are approximated with them",
+			"set TERM=xterm-256color if the terminal has more, or pass -color 256")
+	case termenv.ANSI256:
+		diagnosis.add(findingOK, "colors", "256 colors", "")
+	default:
+		diagnosis.add(findingOK, "colors", "true color", "")
+	}
+}
+
+func (diagnosis *diagnosis) checkUnicode() {
+	if !isUTF8Locale() {
+		diagnosis.add(findingWarning, "unicode", "locale is not UTF-8, screens are drawn with ASCII only",
+			"set LANG to a UTF-8 locale like en_US.UTF-8, or pass -ascii to keep ASCII")
+		return
+	}
+	// Ambiguous width characters are counted as wide for CJK locales
+	if lipgloss.Width(unicodeSymbols.arrow) != 1 {
+		diagnosis.add(findingWarning, "unicode", "symbols like "+unicodeSymbols.arrow+" are counted as two cells wide, borders come out misaligned",
+			"set RUNEWIDTH_EASTASIAN=0, or pass -ascii")
+		return
+	}
+	diagnosis.add(findingOK, "unicode", "UTF-8 locale", "")
+}
+
+// Whether the terminal draws the symbols as wide as they are counted
+// can only be seen, asking the terminal would need its answer read
+func printWidthSample() {
+	sample := []string{
+		unicodeSymbols.correct,
+		unicodeSymbols.mistake,
+		unicodeSymbols.streak,
+		unicodeSymbols.empty,
+		unicodeSymbols.heatmapCell,
+		string(unicodeSymbols.sparkline),
+		unicodeSymbols.times,
+		unicodeSymbols.border.TopLeft,
+		unicodeSymbols.border.Top,
+		unicodeSymbols.border.TopRight,
+	}
+	symbolsLine := strings.Join(sample, "")
+	fmt.Println("Both lines end in the same column when the terminal draws symbols as wide as expected:")
+	fmt.Println("  " + symbolsLine + "|")
+	fmt.Println("  " + strings.Repeat(".", lipgloss.Width(symbolsLine)) + "|")
+	fmt.Println("If they do not, pass -ascii or use a font with these symbols.")
+	fmt.Println()
+}
+
+// Preference is the one the quiz starts with, not what the terminal does
+func (diagnosis *diagnosis) checkAltScreen() {
+	term := os.Getenv("TERM")
+	if strings.HasPrefix(term, "screen") && os.Getenv("TMUX") == "" && os.Getenv("STY") != "" {
+		diagnosis.add(findingWarning, "alt screen", "GNU screen leaves the quiz on the terminal after exiting unless told otherwise",
+			"put 'altscreen on' into ~/.screenrc")
+		return
+	}
+	preferences, err := store.loadPreferences()
+	if err != nil {
+		diagnosis.add(findingWarning, "alt screen", err.Error(), "remove the UI preferences file, it is written again on exit")
+		return
+	}
+	if preferences.AltScreen {
+		diagnosis.add(findingOK, "alt screen", "quiz starts on the alternate screen, ctrl+a switches", "")
+	} else {
+		diagnosis.add(findingOK, "alt screen", "quiz starts inline, ctrl+a switches to the alternate screen", "")
+	}
+}
+
+// Directory missing is fine as long as it can be created, which
+// happens only when something is first written into it
+func writableDirectory(directory string) error {
+	for {
+		info, err := os.Stat(directory)
+		if errors.Is(err, fs.ErrNotExist) {
+			parent := filepath.Dir(directory)
+			if parent == directory {
+				return err
+			}
+			directory = parent
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", directory)
+		}
+		probe, err := os.CreateTemp(directory, ".gem2-doctor-*")
+		if err != nil {
+			return err
+		}
+		probe.Close()
+		return os.Remove(probe.Name())
+	}
+}
+
+// Files are opened for writing without truncating them, nothing changes
+func (diagnosis *diagnosis) checkWritable(subject string, path string) {
+	if err := writableDirectory(filepath.Dir(path)); err != nil {
+		diagnosis.add(findingProblem, subject, fmt.Sprintf("%s can not be written: %v", path, err),
+			"fix the permissions of its directory, or choose another place with the flags or the environment")
+		return
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		diagnosis.add(findingOK, subject, path+", created when first written", "")
+		return
+	}
+	if err != nil {
+		diagnosis.add(findingProblem, subject, fmt.Sprintf("%s can not be written: %v", path, err),
+			"fix the permissions of the file, or pass -readonly to practice without saving")
+		return
+	}
+	file.Close()
+	diagnosis.add(findingOK, subject, path, "")
+}
+
+func (diagnosis *diagnosis) checkDataPaths() {
+	diagnosis.checkWritable("statistics", statisticsPath)
+	diagnosis.checkWritable("history", historyPath)
+	diagnosis.checkWritable("mistakes", mistakesPath)
+	diagnosis.checkWritable("log", logPath)
+	if err := writableDirectory(configDirectory()); err != nil {
+		diagnosis.add(findingWarning, "config", fmt.Sprintf("%s can not be written: %v", configDirectory(), err),
+			"set XDG_CONFIG_HOME to a writable directory")
+	}
+}
+
+// Same checks as validate, which has the details
+func (diagnosis *diagnosis) checkDeck() {
+	database, err := readDatabase()
+	if err != nil {
+		diagnosis.add(findingProblem, "deck", err.Error(), "pass -deck or set GEM2_DECK to a deck which can be read")
+		return
+	}
+	var report validationReport
+	report.checkDatabase(database)
+	report.checkDeckSettings(database.Settings)
+	report.checkStatistics(database)
+	for _, warning := range report.warnings {
+		diagnosis.add(findingWarning, "deck", warning, "")
+	}
+	for _, problem := range report.problems {
+		diagnosis.add(findingProblem, "deck", problem, "run gem2 validate, then fix the file it names")
+	}
+	if len(report.warnings)+len(report.problems) == 0 {
+		diagnosis.add(findingOK, "deck", fmt.Sprintf("%s, %d verbs", wordDatabasePath, len(database.Verbs)), "")
+	}
+}
+
+func (diagnosis diagnosis) print() {
+	for _, finding := range diagnosis.findings {
+		fmt.Printf("%-8s %s: %s\n", findingLevelNames[finding.level], finding.subject, finding.text)
+		if finding.hint != "" {
+			fmt.Printf("%-8s fix: %s\n", "", finding.hint)
+		}
+	}
+}
+
+func runDoctor(args []string) {
+	flags, options := newFlagSet("doctor", "")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	// Checking writes nothing but the probes, not even migration backups
+	options.readsOnly = true
+	options.apply()
+	var diagnosis diagnosis
+	diagnosis.checkTerminal()
+	diagnosis.checkColors()
+	diagnosis.checkUnicode()
+	diagnosis.checkAltScreen()
+	diagnosis.checkDataPaths()
+	diagnosis.checkDeck()
+	if isTerminal(os.Stdout) && isUTF8Locale() {
+		printWidthSample()
+	}
+	diagnosis.print()
+	if diagnosis.hasProblems() {
+		exit(validationError)
+	}
+}
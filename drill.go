@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Reads lines in the background, so that waiting
+// for input can be interrupted by a signal
+func readLines(file *os.File) <-chan string {
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	return lines
+}
+
+func (screen quizScreen) describeAnswer() string {
+	if !screen.isAnswerCorrect() {
+		return fmt.Sprintf("Wrong! Correct answer is: %s", screen.question.correctAnswer)
+	}
+	switch {
+	case screen.sessionRecord:
+		return fmt.Sprintf("Correct! New session record: %d in a row", screen.streak)
+	case screen.promptRecord:
+		return "Correct! New best streak for this question"
+	}
+	return "Correct!"
+}
+
+// Everything in words on a line of its own, for screen readers
+func (screen quizScreen) describeQuestion(number int) string {
+	return fmt.Sprintf(
+		"Question %d. Form clue: %s. Verb: %s. Answer: ",
+		number,
+		screen.question.prompt.FormClue,
+		screen.question.prompt.Verb,
+	)
+}
+
+// Same selection and statistics as the quiz, but without
+// the terminal UI, so it works in dumb terminals and scripts
+func runDrill(args []string) {
+	flags, options := newFlagSet("drill", "")
+	count := flags.Int("count", 0, "stop after this many `questions`, 0 means until the end of input")
+	labelled := flags.Bool("screen-reader", false, "label every part of the question")
+	flags.IntVar(&warmUpLength, "warm-up", warmUpLength, "`number` of easy questions to start with before the usual selection")
+	frequencyList := flags.String("frequency", "auto", "verb frequency `list` introducing new questions, most frequent verbs first: auto, off, a language or a file")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	options.apply()
+	loadSessionSettings()
+	slog.Info("Starting drill", "build", currentBuild().String())
+	lockOrFallBackToReadOnly()
+	database := read_database()
+	statistics := database.loadStatistics()
+	history := loadHistory()
+	useDeckSettingsOrExit(database.Settings, nil)
+	if err := useFrequencyList(*frequencyList, &statistics); err != nil {
+		logFatal("Invalid frequency list", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(usageError)
+	}
+	quiz := newQuizScreen(newSession(&statistics, &history))
+	quiz.startWarmUp()
+	drill(quiz, *count, *labelled)
+}
+
+// Questions and results are printed as lines, answers are read
+// as lines, until the count is reached or the input ends
+func drill(quiz quizScreen, count int, labelled bool) {
+	if labelled {
+		fmt.Printf(
+			"Deck %s, %d questions due. Type each answer and press enter, end the input to quit.\n",
+			filepath.Base(wordDatabasePath),
+			quiz.statistics.dueSummary(time.Now()).DueNow,
+		)
+	}
+	// Nothing but questions is shown, so the clock never stops
+	quiz.clock.start()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	lines := readLines(os.Stdin)
+	asked := 0
+drill:
+	for count == 0 || asked < count {
+		if labelled {
+			fmt.Print(quiz.describeQuestion(asked + 1))
+		} else {
+			fmt.Printf("%s: ", quiz.question.prompt)
+		}
+		select {
+		case line, more := <-lines:
+			if !more {
+				fmt.Println()
+				break drill
+			}
+			quiz.inputField.SetValue(line)
+		case sig := <-signals:
+			slog.Info("Received signal, quitting", "signal", sig)
+			fmt.Println()
+			break drill
+		}
+		quiz.submitAnswer()
+		fmt.Println(quiz.describeAnswer())
+		if event, broken := quiz.recordBrokenEvent(); broken {
+			if err := runHook(context.Background(), hooks.RecordBroken, event, false); err != nil {
+				slog.Error("Record hook failed", "error", err)
+			}
+		}
+		quiz.nextQuestion()
+		asked++
+	}
+
+	fmt.Printf("%d correct, %d wrong\n", quiz.correctAnswers, quiz.wrongAnswers)
+	quiz.teardownWithoutUI()
+	slog.Info("Finished successfully")
+}
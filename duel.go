@@ -0,0 +1,411 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Choices for the number of questions every player answers
+var duelRounds = [...]int{5, 10, 20}
+
+// Which player's answers go into the statistics of the profile,
+// the other player is a guest whose answers are only scored
+const notRecorded = -1
+
+type duelSettings struct {
+	names  [2]string
+	rounds int
+	// Index of the player, or notRecorded
+	recorded int
+}
+
+type duelPlayer struct {
+	name       string
+	correct    int
+	wrong      int
+	streak     int
+	bestStreak int
+	answerTime time.Duration
+}
+
+// Players are asked in turns, sharing one keyboard
+// and one pool of questions drawn up front
+type duelScreen struct {
+	settings duelSettings
+	// Copy of the quiz, its question and input field are the current turn's
+	quiz    quizScreen
+	pool    []question
+	turn    int
+	players [2]duelPlayer
+}
+
+// Names, rounds and recording, names are typed
+// in place of their entry once it is chosen
+type duelSetupScreen struct {
+	quiz     *quizScreen
+	settings duelSettings
+	menuSelection
+	editing bool
+	input   textinput.Model
+}
+
+type duelResultScreen struct {
+	duel duelScreen
+}
+
+const (
+	firstPlayerEntry = iota
+	secondPlayerEntry
+	roundsEntry
+	recordingEntry
+	startEntry
+	duelSetupEntries
+)
+
+func newDuelSetupScreen(quiz *quizScreen) duelSetupScreen {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.CharLimit = 20
+	return duelSetupScreen{
+		quiz: quiz,
+		settings: duelSettings{
+			names:    [2]string{"Player 1", "Player 2"},
+			rounds:   duelRounds[1],
+			recorded: notRecorded,
+		},
+		input: input,
+	}
+}
+
+func (screen duelSetupScreen) isTyping() bool {
+	return screen.editing
+}
+
+func (screen duelSetupScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (settings *duelSettings) cycleRounds() {
+	for i, rounds := range duelRounds {
+		if rounds == settings.rounds {
+			settings.rounds = duelRounds[(i+1)%len(duelRounds)]
+			return
+		}
+	}
+	settings.rounds = duelRounds[0]
+}
+
+// Nobody, then either player in turn
+func (settings *duelSettings) cycleRecorded() {
+	settings.recorded++
+	if settings.recorded >= len(settings.names) {
+		settings.recorded = notRecorded
+	}
+}
+
+func (settings duelSettings) describeRecorded() string {
+	if settings.recorded == notRecorded {
+		return "not recorded"
+	}
+	owner := "profile"
+	if profileName != "" {
+		owner = profileName
+	}
+	return settings.names[settings.recorded] + " as " + owner
+}
+
+func (screen duelSetupScreen) choose() (tea.Model, tea.Cmd) {
+	switch screen.selected {
+	case firstPlayerEntry, secondPlayerEntry:
+		screen.editing = true
+		screen.input.SetValue(screen.settings.names[screen.selected])
+		screen.input.CursorEnd()
+		return screen, screen.input.Focus()
+	case roundsEntry:
+		screen.settings.cycleRounds()
+	case recordingEntry:
+		screen.settings.cycleRecorded()
+	case startEntry:
+		slog.Info("Starting duel", "players", screen.settings.names, "rounds", screen.settings.rounds, "recorded", screen.settings.recorded)
+		return screen, pushScreen(newDuelScreen(*screen.quiz, screen.settings))
+	}
+	return screen, nil
+}
+
+func (screen duelSetupScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if screen.editing {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(msg, keys.Submit):
+				if name := screen.input.Value(); name != "" {
+					screen.settings.names[screen.selected] = name
+				}
+				fallthrough
+			case key.Matches(msg, keys.Menu):
+				screen.editing = false
+				screen.input.Blur()
+				return screen, nil
+			}
+		}
+		var cmd tea.Cmd
+		screen.input, cmd = screen.input.Update(msg)
+		return screen, cmd
+	}
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back, keys.Menu):
+			return screen, popScreen
+		case key.Matches(msg, keys.Down):
+			screen.moveDown(duelSetupEntries)
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.moveUp()
+			return screen, nil
+		case key.Matches(msg, keys.Submit):
+			return screen.choose()
+		}
+	case tea.MouseMsg:
+		if screen.handleMouse(msg, listFirstRow, duelSetupEntries) {
+			return screen.choose()
+		}
+		return screen, nil
+	}
+	return screen, nil
+}
+
+var duelSetupHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Down, &keys.Up}, action: "move"},
+	{bindings: []*key.Binding{&keys.Submit}, action: "change"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+}
+
+var duelNameHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit}, action: "save"},
+	{bindings: []*key.Binding{&keys.Menu}, action: "cancel"},
+}
+
+func (screen duelSetupScreen) View() string {
+	entries := [duelSetupEntries]struct {
+		title  string
+		detail string
+	}{
+		firstPlayerEntry:  {"First player", screen.settings.names[0]},
+		secondPlayerEntry: {"Second player", screen.settings.names[1]},
+		roundsEntry:       {"Questions each", strconv.Itoa(screen.settings.rounds)},
+		recordingEntry:    {"Statistics", screen.settings.describeRecorded()},
+		startEntry:        {"Start", ""},
+	}
+	lines := []string{renderMenuTitle("Duel"), ""}
+	for i, entry := range entries {
+		detail := func() string { return entry.detail }
+		if screen.editing && i == screen.selected {
+			screen.input.Width = boxWidth - lipgloss.Width(entry.title) - lipgloss.Width(screen.input.Prompt) - 3
+			detail = screen.input.View
+		}
+		lines = append(lines, renderMenuEntry(entry.title, detail, i == screen.selected))
+	}
+	lines = append(lines, "", questionStatsStyle.Render("Players answer in turns, the same number of questions each."))
+	footer := renderHelpRow(duelSetupHelp[:])
+	if screen.editing {
+		footer = renderHelpRow(duelNameHelp[:])
+	}
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), footer)
+}
+
+// Each player gets a question of their own from the pool, so
+// the one waiting does not learn the answer before their turn
+func newDuelScreen(quiz quizScreen, settings duelSettings) duelScreen {
+	pool := make([]question, 2*settings.rounds)
+	for i := range pool {
+		pool[i] = quiz.statistics.getRandomQuestion()
+	}
+	screen := duelScreen{settings: settings, quiz: quiz, pool: pool}
+	for i, name := range settings.names {
+		screen.players[i].name = name
+	}
+	screen.startTurn()
+	return screen
+}
+
+func (screen *duelScreen) player() *duelPlayer {
+	return &screen.players[screen.turn%len(screen.players)]
+}
+
+func (screen *duelScreen) startTurn() {
+	screen.quiz.question = screen.pool[screen.turn]
+	screen.quiz.questionShown = screen.quiz.clock.elapsed()
+	screen.quiz.inputField.Reset()
+	screen.quiz.inputField.Focus()
+	screen.quiz.mode = input
+}
+
+func (screen duelScreen) isTyping() bool {
+	return screen.quiz.mode == input
+}
+
+func (screen duelScreen) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Answers of the recorded player count as if they were given in the
+// quiz, everything else about them is left to submitAnswer
+func (screen *duelScreen) answer() {
+	player := screen.player()
+	player.answerTime += screen.quiz.questionTime()
+	if screen.quiz.isAnswerCorrect() {
+		player.correct++
+		player.streak++
+		player.bestStreak = max(player.bestStreak, player.streak)
+	} else {
+		player.wrong++
+		player.streak = 0
+	}
+	if screen.turn%len(screen.players) == screen.settings.recorded {
+		screen.quiz.submitAnswer()
+	}
+	screen.quiz.inputField.Blur()
+	screen.quiz.mode = validation
+}
+
+func (screen duelScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Menu):
+			slog.Info("Duel abandoned", "turn", screen.turn)
+			return screen, popScreen
+		case key.Matches(msg, keys.Submit) && screen.quiz.mode == input:
+			screen.answer()
+			correct := screen.quiz.isAnswerCorrect()
+			return screen, tea.Batch(signalAnswer(correct), startAnimation(correct))
+		case key.Matches(msg, keys.Submit):
+			screen.turn++
+			if screen.turn == len(screen.pool) {
+				slog.Info("Duel finished", "players", screen.players)
+				return screen, replaceScreen(duelResultScreen{screen})
+			}
+			screen.startTurn()
+			return screen, textinput.Blink
+		}
+	}
+	if screen.quiz.mode != input {
+		return screen, nil
+	}
+	if isPaste(msg) {
+		rejectPaste()
+		return screen, nil
+	}
+	var cmd tea.Cmd
+	screen.quiz.inputField, cmd = screen.quiz.inputField.Update(msg)
+	return screen, cmd
+}
+
+func (screen duelScreen) renderScoreRow() string {
+	player := screen.player()
+	style := background.Foreground(textColor)
+	score := style.Render(fmt.Sprintf(
+		"%s %d : %d %s",
+		screen.players[0].name, screen.players[0].correct, screen.players[1].correct, screen.players[1].name,
+	))
+	round := screen.turn/len(screen.players) + 1
+	return style.Width(boxWidth-lipgloss.Width(score)).
+		Render(fmt.Sprintf("%s, question %s of %d", bold(player.name), bold(strconv.Itoa(round)), screen.settings.rounds)) +
+		score
+}
+
+var duelInputHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit}, action: "submit"},
+	{bindings: []*key.Binding{&keys.Menu}, action: "abandon"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+var duelValidationHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit}, action: "next player"},
+	{bindings: []*key.Binding{&keys.Menu}, action: "abandon"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen duelScreen) View() string {
+	lines := []string{screen.renderScoreRow(), "", screen.quiz.renderQuestion(), ""}
+	footer := renderHelpRow(duelInputHelp[:])
+	if screen.quiz.mode == validation {
+		lines = append(lines, "", screen.quiz.renderValidationRow())
+		footer = renderHelpRow(duelValidationHelp[:])
+	}
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), footer)
+}
+
+// More correct answers win, a tie goes to the faster player
+func (screen duelResultScreen) winner() (duelPlayer, bool) {
+	first, second := screen.duel.players[0], screen.duel.players[1]
+	switch {
+	case first.correct != second.correct:
+		if first.correct > second.correct {
+			return first, true
+		}
+		return second, true
+	case first.answerTime.Round(time.Second) != second.answerTime.Round(time.Second):
+		if first.answerTime < second.answerTime {
+			return first, true
+		}
+		return second, true
+	}
+	return duelPlayer{}, false
+}
+
+func (screen duelResultScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen duelResultScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Retry):
+			rematch := newDuelScreen(screen.duel.quiz, screen.duel.settings)
+			return screen, replaceScreen(rematch)
+		case key.Matches(msg, keys.Back, keys.Submit, keys.Menu):
+			return screen, popScreen
+		}
+	}
+	return screen, nil
+}
+
+var duelResultHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Retry}, action: "rematch"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+// Players side by side, a row for every figure
+func (screen duelResultScreen) View() string {
+	players := screen.duel.players
+	columnWidth := boxWidth / 3
+	row := func(title string, values func(player duelPlayer) string) string {
+		return promptStatsEntryStyle.Width(columnWidth).Render(title) +
+			questionStatsStyle.Width(columnWidth).Render(values(players[0])) +
+			questionStatsStyle.Width(boxWidth-2*columnWidth).Render(values(players[1]))
+	}
+	verdict := "Draw!"
+	if winner, won := screen.winner(); won {
+		verdict = winner.name + " wins!"
+	}
+	lines := []string{
+		statsTitleStyle.Render("Duel results"),
+		"",
+		recordStyle.Render(verdict),
+		"",
+		row("", func(player duelPlayer) string { return bold(player.name) }),
+		row("Correct", func(player duelPlayer) string { return strconv.Itoa(player.correct) }),
+		row("Wrong", func(player duelPlayer) string { return strconv.Itoa(player.wrong) }),
+		row("Best streak", func(player duelPlayer) string { return strconv.Itoa(player.bestStreak) }),
+		row("Time", func(player duelPlayer) string { return player.answerTime.Round(time.Second).String() }),
+	}
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), renderHelpRow(duelResultHelp[:]))
+}
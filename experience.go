@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	baseExperience = 10
+	// Earned on top for questions which are always missed
+	maxDifficultyExperience = 10
+	// One point per answer of the current session streak, up to this
+	maxStreakExperience = 10
+	// Reaching the next level takes this many points times the current level
+	levelExperience = 100
+)
+
+// Points for a correct answer, given the record of the question
+// before it was answered and the session streak including it
+func answerExperience(record questionStats, sessionStreak uint32) uint32 {
+	experience := uint32(baseExperience)
+	if answered := record.Correct + record.Mistakes; answered > 0 {
+		experience += maxDifficultyExperience * record.Mistakes / answered
+	}
+	return experience + min(sessionStreak, maxStreakExperience)
+}
+
+func (history practiceHistory) addExperience(points uint32) {
+	key := dateKey(time.Now())
+	record := history.days[key]
+	record.experience += points
+	history.days[key] = record
+}
+
+func (history practiceHistory) totalExperience() uint64 {
+	var total uint64
+	for _, record := range history.days {
+		total += uint64(record.experience)
+	}
+	return total
+}
+
+type level struct {
+	number int
+	// Points earned since reaching the level, out of needed for the next
+	progress uint64
+	needed   uint64
+}
+
+// Levels start at 1 and every next one takes longer to reach
+func levelOf(experience uint64) level {
+	current := level{number: 1, progress: experience, needed: levelExperience}
+	for current.progress >= current.needed {
+		current.progress -= current.needed
+		current.number++
+		current.needed = uint64(current.number) * levelExperience
+	}
+	return current
+}
+
+// Called with the record of the question before the correct answer
+func (screen *quizScreen) awardExperience(record questionStats) {
+	before := levelOf(screen.history.totalExperience())
+	screen.history.addExperience(answerExperience(record, screen.streak))
+	if after := levelOf(screen.history.totalExperience()); after.number > before.number {
+		slog.Info("Reached a new level", "level", after.number)
+		notify(fmt.Sprintf("level %d!", after.number))
+	}
+}
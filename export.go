@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+type exportFormat int
+
+const (
+	csvFormat exportFormat = iota
+	markdownFormat
+	spreadsheetFormat
+)
+
+func exportFormatFromPath(path string) (exportFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return csvFormat, nil
+	case ".md", ".markdown":
+		return markdownFormat, nil
+	case ".xlsx":
+		return spreadsheetFormat, nil
+	}
+	return 0, fmt.Errorf("unknown export format of %q, use .csv, .md or .xlsx", path)
+}
+
+var mistakesExportHeader = []string{"Form clue", "Verb", "Correct answer", "Mistakes", "Wrong answers", "Last mistake"}
+
+// Most often missed first, since that is the order to review them in
+func mistakesExportRows(mistakes map[prompt]mistakeRecord) [][]string {
+	groups := groupMistakes(mistakes)
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].record.count > groups[j].record.count
+	})
+	rows := make([][]string, len(groups))
+	for i, group := range groups {
+		rows[i] = []string{
+			group.prompt.FormClue,
+			group.prompt.Verb,
+			group.record.correctAnswer,
+			fmt.Sprint(group.record.count),
+			group.record.summarizeAnswers(),
+			group.last.Format(historyDateLayout),
+		}
+	}
+	return rows
+}
+
+func escapeMarkdownCell(cell string) string {
+	return strings.ReplaceAll(cell, "|", `\|`)
+}
+
+func writeMarkdownTable(w io.Writer, header []string, rows [][]string) error {
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	lines := []string{
+		"| " + strings.Join(header, " | ") + " |",
+		"| " + strings.Join(separator, " | ") + " |",
+	}
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = escapeMarkdownCell(cell)
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func writeTable(path string, header []string, rows [][]string) error {
+	format, err := exportFormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	if format == spreadsheetFormat {
+		return deck.WriteRows(path, header, rows)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	switch format {
+	case csvFormat:
+		w := csv.NewWriter(f)
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		if err := w.WriteAll(rows); err != nil {
+			return err
+		}
+	case markdownFormat:
+		if err := writeMarkdownTable(f, header, rows); err != nil {
+			return err
+		}
+	}
+	return f.Close()
+}
+
+func exportMistakes(path string) {
+	mistakes := readMistakes()
+	if mistakes == nil {
+		logFatal("Mistakes file can not be exported")
+		exit(exportError)
+	}
+	// Only the answers of the mistakes are at hand, the deck is not read
+	useCollation(detectLanguage(specialCharactersOf(func(yield func(string) bool) {
+		for _, record := range mistakes {
+			if !yield(record.correctAnswer) {
+				return
+			}
+		}
+	})))
+	if err := writeTable(path, mistakesExportHeader, mistakesExportRows(mistakes)); err != nil {
+		logFatal("Failed to export mistakes", "error", err)
+		fmt.Fprintf(os.Stderr, "Failed to export mistakes: %v\n", err)
+		exit(exportError)
+	}
+	slog.Info("Exported mistakes", "records", len(mistakes), "path", path)
+	fmt.Printf("Exported %d questions to %s\n", len(mistakes), path)
+}
+
+var questionsExportHeader = []string{"Form clue", "Verb", "Answer"}
+
+var questionsStatisticsHeader = []string{"Answered", "Accuracy", "Streak", "Last practiced"}
+
+// In the order of the deck, with how each question went so far
+// when the table is meant as a progress report for someone else
+func (statistics statisticsDatabase) questionsExportRows(withStatistics bool) [][]string {
+	rows := make([][]string, 0, statistics.Len())
+	for prompt, answer := range statistics.Answers() {
+		row := []string{prompt.FormClue, prompt.Verb, answer}
+		if withStatistics {
+			record := statistics.Record(prompt)
+			answered := uint64(record.Correct + record.Mistakes)
+			lastPracticed := ""
+			if !record.LastPracticed.IsZero() {
+				lastPracticed = record.LastPracticed.Local().Format(historyDateLayout)
+			}
+			row = append(
+				row,
+				fmt.Sprint(answered),
+				formatPercentage(uint64(record.Correct), answered),
+				fmt.Sprint(record.Streak),
+				lastPracticed,
+			)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func exportQuestions(path string, withStatistics bool) {
+	database := read_database()
+	statistics := database.loadStatistics()
+	header := questionsExportHeader
+	if withStatistics {
+		header = append(slices.Clone(header), questionsStatisticsHeader...)
+	}
+	if err := writeTable(path, header, statistics.questionsExportRows(withStatistics)); err != nil {
+		logFatal("Failed to export questions", "error", err)
+		fmt.Fprintf(os.Stderr, "Failed to export questions: %v\n", err)
+		exit(exportError)
+	}
+	slog.Info("Exported questions", "questions", statistics.Len(), "statistics", withStatistics, "path", path)
+	fmt.Printf("Exported %d questions to %s\n", statistics.Len(), path)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Answers which are signalled with the terminal bell or
+// a flash of the border, for drilling without reading
+type answerSignal int
+
+const (
+	signalOff answerSignal = iota
+	signalWrong
+	signalCorrect
+	signalAll
+)
+
+var answerSignalNames = [...]string{"off", "wrong", "correct", "all"}
+
+const flashDuration = 250 * time.Millisecond
+
+var (
+	bellSignal  answerSignal
+	flashSignal answerSignal
+	// Color of the border while it flashes, nil otherwise
+	flashColor lipgloss.TerminalColor
+	// Only the latest flash ends it, earlier ones
+	// may still be ticking when answering quickly
+	flashCount int
+)
+
+type flashEndedMessage struct {
+	flash int
+}
+
+func (signal answerSignal) String() string {
+	return answerSignalNames[signal]
+}
+
+// Makes it usable as a flag
+func (signal *answerSignal) Set(value string) error {
+	for i, name := range answerSignalNames {
+		if name == value {
+			*signal = answerSignal(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown value %q, available: %s", value, strings.Join(answerSignalNames[:], ", "))
+}
+
+func (signal *answerSignal) cycle() {
+	*signal = (*signal + 1) % answerSignal(len(answerSignalNames))
+}
+
+func (signal answerSignal) fires(correct bool) bool {
+	switch signal {
+	case signalWrong:
+		return !correct
+	case signalCorrect:
+		return correct
+	}
+	return signal == signalAll
+}
+
+// Bell goes straight to the terminal, as
+// the view has no way to ring it only once
+func ringBell() tea.Msg {
+	os.Stdout.WriteString("\a")
+	return nil
+}
+
+func startFlash(correct bool) tea.Cmd {
+	flashColor = wrongColor
+	if correct {
+		flashColor = highlightColor
+	}
+	flashCount++
+	flash := flashCount
+	return tea.Tick(flashDuration, func(time.Time) tea.Msg { return flashEndedMessage{flash} })
+}
+
+func endFlash(msg flashEndedMessage) {
+	if msg.flash == flashCount {
+		flashColor = nil
+	}
+}
+
+func signalAnswer(correct bool) tea.Cmd {
+	var cmds []tea.Cmd
+	if bellSignal.fires(correct) {
+		cmds = append(cmds, ringBell)
+	}
+	if flashSignal.fires(correct) {
+		cmds = append(cmds, startFlash(correct))
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/kligunov-id/gem2/scheduler"
+)
+
+// Verbs one per line, the most frequent first, named after
+// the languages of the compose tables the deck is told by
+//
+//go:embed frequency/*.txt
+var frequencyLists embed.FS
+
+// Unstarted questions in the order they are introduced in,
+// nil introduces them in random order like the rest
+var introductionOrder []prompt
+
+func frequencyLanguages() []string {
+	entries, _ := frequencyLists.ReadDir("frequency")
+	languages := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		languages = append(languages, strings.TrimSuffix(entry.Name(), ".txt"))
+	}
+	return languages
+}
+
+// Lines starting with # and blank ones are skipped
+func parseFrequencyList(data []byte) map[string]int {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		verb := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if verb == "" || strings.HasPrefix(verb, "#") {
+			continue
+		}
+		if _, listed := ranks[verb]; !listed {
+			ranks[verb] = len(ranks)
+		}
+	}
+	return ranks
+}
+
+// Auto uses the list shipped for the language of the deck, if there is one,
+// any other value is either the language of a shipped list or a file
+func useFrequencyList(list string, statistics *statisticsDatabase) error {
+	introductionOrder = nil
+	switch list {
+	case "off":
+		return nil
+	case "auto":
+		list = statistics.language()
+		if !slices.Contains(frequencyLanguages(), list) {
+			return nil
+		}
+	}
+	data, err := frequencyLists.ReadFile(path.Join("frequency", list+".txt"))
+	if err != nil {
+		data, err = os.ReadFile(list)
+	}
+	if err != nil {
+		return fmt.Errorf("frequency list %q is neither a file nor one of: auto, off, %s", list, strings.Join(frequencyLanguages(), ", "))
+	}
+	introductionOrder = statistics.frequencyOrder(parseFrequencyList(data))
+	slog.Debug("Introducing questions in frequency order", "list", list)
+	return nil
+}
+
+// Verbs not on the list come after the listed ones in the order of
+// the deck, and so do the forms of a verb, as the sort is stable
+func (statistics statisticsDatabase) frequencyOrder(ranks map[string]int) []prompt {
+	rank := func(prompt prompt) int {
+		if rank, listed := ranks[strings.ToLower(strings.TrimSpace(prompt.Verb))]; listed {
+			return rank
+		}
+		return len(ranks)
+	}
+	order := slices.Clone(statistics.Prompts())
+	slices.SortStableFunc(order, func(a prompt, b prompt) int {
+		return rank(a) - rank(b)
+	})
+	return order
+}
+
+// Unstarted question of the most frequent verb, false once all are started
+func (statistics statisticsDatabase) nextIntroduction() (prompt, bool) {
+	for _, prompt := range introductionOrder {
+		if record, exists := statistics.Lookup(prompt); exists && !scheduler.IsStarted(record) && !record.Suspended {
+			return prompt, true
+		}
+	}
+	return prompt{}, false
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+const (
+	sidebarWidth   = 24
+	sidebarPadding = 2
+	tickerHeight   = 1
+	// Session mistakes shown in the ticker below the box
+	tickerMistakes = 5
+)
+
+// Full layout makes the box take the whole terminal instead of staying
+// small and centered, with deck statistics on the side and recent
+// mistakes below. The sidebar is left out when the terminal is too narrow.
+var sidebarShown bool
+
+var recentMistakes []string
+
+func rememberMistake(question question, answer string) {
+	recentMistakes = append(recentMistakes, fmt.Sprintf(
+		"%s + %s: %s %s %s",
+		question.prompt.FormClue,
+		question.prompt.Verb,
+		strings.TrimSpace(answer),
+		symbols.arrow,
+		question.correctAnswer,
+	))
+	recentMistakes = recentMistakes[max(len(recentMistakes)-tickerMistakes, 0):]
+}
+
+func resizeFullScreen(width int, height int) {
+	const border = 2
+	sidebarTotalWidth := sidebarWidth + 2*sidebarPadding + border
+	boxHeight = max(height-2*verticalPadding-border-statusBarHeight-tickerHeight, minBoxHeight)
+	boxWidth = width - 2*horizontalPadding - border - sidebarTotalWidth
+	sidebarShown = boxWidth >= minBoxWidth
+	if !sidebarShown {
+		boxWidth = max(width-2*horizontalPadding-border, minBoxWidth)
+	}
+}
+
+func renderSidebar(statistics *statisticsDatabase, history *practiceHistory) string {
+	now := time.Now()
+	summary := statistics.summary()
+	due := statistics.dueSummary(now)
+	today := history.day(now)
+	titleStyle := background.Foreground(textColor).Bold(true)
+	textStyle := background.Foreground(textColor)
+	row := func(label string, value string) string {
+		value = questionStyle.Render(value)
+		return textStyle.Width(sidebarWidth-lipgloss.Width(value)).Render(label) + value
+	}
+	lines := []string{
+		titleStyle.Render("Today"),
+		row("answered", fmt.Sprint(today.answered())),
+		row("correct", formatPercentage(uint64(today.correct), uint64(today.answered()))),
+		row("daily streak", fmt.Sprint(history.dailyStreak(now))),
+		"",
+		titleStyle.Render("Deck"),
+		row("due", fmt.Sprint(due.DueNow)),
+		row("weak", fmt.Sprint(due.Weak)),
+		row("new", fmt.Sprint(due.New)),
+		row("mature", fmt.Sprint(summary.mature)),
+		row("mastered", fmt.Sprint(summary.mastered)),
+	}
+	return boxStyle.
+		Width(sidebarWidth+2*sidebarPadding).
+		PaddingLeft(sidebarPadding).
+		PaddingRight(sidebarPadding).
+		Align(lipgloss.Left, lipgloss.Top).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+func renderTicker() string {
+	style := background.Foreground(mutedColor).Italic(true)
+	text := "No mistakes this session"
+	if len(recentMistakes) > 0 {
+		text = "Missed: " + strings.Join(recentMistakes, symbols.helpSeparator)
+	}
+	return style.Inline(true).MaxWidth(layoutWidth()).Render(text)
+}
+
+func renderFullScreen(screen string, statistics *statisticsDatabase, history *practiceHistory) string {
+	if sidebarShown {
+		screen = lipgloss.JoinHorizontal(lipgloss.Top, screen, renderSidebar(statistics, history))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, screen, renderTicker())
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Daily goal and the habit trackers told when it is reached,
+// trackers without credentials are not used
+type habitSettings struct {
+	// Answers in a day which complete the goal
+	DailyGoal uint32            `toml:"daily_goal"`
+	Habitica  habiticaSettings  `toml:"habitica"`
+	Beeminder beeminderSettings `toml:"beeminder"`
+}
+
+type habiticaSettings struct {
+	UserID   string `toml:"user_id"`
+	APIToken string `toml:"api_token"`
+	// ID or alias of the daily to check off
+	Task string `toml:"task"`
+}
+
+type beeminderSettings struct {
+	Username  string `toml:"username"`
+	AuthToken string `toml:"auth_token"`
+	Goal      string `toml:"goal"`
+}
+
+var habits habitSettings
+
+const (
+	habiticaURL  = "https://habitica.com/api/v3"
+	beeminderURL = "https://www.beeminder.com/api/v1"
+	// Quitting waits for the trackers, but not for long
+	habitTimeout = 10 * time.Second
+)
+
+func habitsPath() string {
+	return filepath.Join(configDirectory(), "habits.toml")
+}
+
+func parseHabits(data []byte) (habitSettings, error) {
+	var settings habitSettings
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		return habitSettings{}, err
+	}
+	if settings.DailyGoal == 0 && (settings.Habitica.enabled() || settings.Beeminder.enabled()) {
+		return habitSettings{}, errors.New("daily_goal is not set")
+	}
+	return settings, nil
+}
+
+// Nothing is reported when there is no habits file
+func loadHabits() error {
+	path := habitsPath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	settings, err := parseHabits(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	habits = settings
+	return nil
+}
+
+func (settings habiticaSettings) enabled() bool {
+	return settings.UserID != "" && settings.APIToken != "" && settings.Task != ""
+}
+
+func (settings beeminderSettings) enabled() bool {
+	return settings.Username != "" && settings.AuthToken != "" && settings.Goal != ""
+}
+
+// Either API explains failures in a JSON body, which is shown as it is
+func habitError(tracker string, response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1<<10))
+	return fmt.Errorf("%s: %s: %s", tracker, response.Status, strings.TrimSpace(string(body)))
+}
+
+// Scoring the daily up checks it off for today
+func (settings habiticaSettings) report(ctx context.Context) error {
+	address := habiticaURL + "/tasks/" + url.PathEscape(settings.Task) + "/score/up"
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, address, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("x-api-user", settings.UserID)
+	request.Header.Set("x-api-key", settings.APIToken)
+	// Required by Habitica from third party tools
+	request.Header.Set("x-client", settings.UserID+"-gem2")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return habitError("habitica", response)
+	}
+	return nil
+}
+
+// One datapoint per day, the request ID makes Beeminder
+// ignore it when the goal is reported again the same day
+func (settings beeminderSettings) report(ctx context.Context, answered uint32, now time.Time) error {
+	address := beeminderURL + "/users/" + url.PathEscape(settings.Username) + "/goals/" + url.PathEscape(settings.Goal) + "/datapoints.json"
+	form := url.Values{
+		"auth_token": {settings.AuthToken},
+		"value":      {"1"},
+		"timestamp":  {strconv.FormatInt(now.Unix(), 10)},
+		"comment":    {fmt.Sprintf("gem2: %d answers", answered)},
+		"requestid":  {"gem2-" + dateKey(now)},
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, address, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return habitError("beeminder", response)
+	}
+	return nil
+}
+
+// Only the session which reaches the goal reports it, later
+// sessions of the same day have nothing new to tell
+func (session *session) reachedDailyGoal(now time.Time) (uint32, bool) {
+	if habits.DailyGoal == 0 || !session.isLoaded() {
+		return 0, false
+	}
+	answered := session.history.day(now).answered()
+	before := answered - min(answered, session.correctAnswers+session.wrongAnswers)
+	return answered, before < habits.DailyGoal && answered >= habits.DailyGoal
+}
+
+func (session *session) reportHabits() {
+	now := time.Now()
+	answered, reached := session.reachedDailyGoal(now)
+	if !reached {
+		return
+	}
+	slog.Info("Reached daily goal", "goal", habits.DailyGoal, "answered", answered)
+	ctx, cancel := context.WithTimeout(context.Background(), habitTimeout)
+	defer cancel()
+	var failures []error
+	if habits.Habitica.enabled() {
+		failures = append(failures, habits.Habitica.report(ctx))
+	}
+	if habits.Beeminder.enabled() {
+		failures = append(failures, habits.Beeminder.report(ctx, answered, now))
+	}
+	if err := errors.Join(failures...); err != nil {
+		slog.Error("Failed to report daily goal", "error", err)
+		fmt.Fprintf(os.Stderr, "Daily goal was not reported: %v\n", err)
+	}
+}
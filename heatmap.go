@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+const (
+	heatmapMaxWeeks = 52
+)
+
+var heatmapWeekdayLabels = [...]string{"M", " ", "W", " ", "F", " ", "S"}
+
+// As many weeks as fit next to the weekday labels, up to a year
+func heatmapWeeks() int {
+	return min((boxWidth-2)/2, heatmapMaxWeeks)
+}
+
+type heatmapScreen struct {
+	history *practiceHistory
+}
+
+func (screen heatmapScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen heatmapScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Calendar, keys.Back):
+			return screen, popScreen
+		}
+	}
+	return screen, nil
+}
+
+// Monday of the leftmost column, so that the
+// rightmost column is the current week
+func heatmapStart(today time.Time) time.Time {
+	daysSinceMonday := (int(today.Weekday()) + 6) % 7
+	return today.AddDate(0, 0, -daysSinceMonday-7*(heatmapWeeks()-1))
+}
+
+func (history practiceHistory) maxAnswered(from time.Time, to time.Time) uint32 {
+	var maxAnswered uint32 = 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		maxAnswered = max(maxAnswered, history.day(day).answered())
+	}
+	return maxAnswered
+}
+
+func heatmapLevel(answered uint32, maxAnswered uint32) int {
+	level := int(answered) * len(heatmapColors) / int(maxAnswered+1)
+	return min(level, len(heatmapColors)-1)
+}
+
+func renderHeatmapMonths(start time.Time) string {
+	// Two symbols per week column plus the weekday label column
+	row := []rune(strings.Repeat(" ", 2+2*heatmapWeeks()))
+	previousMonth := time.Month(0)
+	lastLabelEnd := 0
+	for week := 0; week < heatmapWeeks(); week++ {
+		month := start.AddDate(0, 0, 7*week).Month()
+		position := 2 + 2*week
+		if month != previousMonth && position >= lastLabelEnd {
+			label := []rune(month.String()[:3])
+			if position+len(label) <= len(row) {
+				copy(row[position:], label)
+				lastLabelEnd = position + len(label) + 1
+			}
+		}
+		previousMonth = month
+	}
+	return string(row)
+}
+
+func (history practiceHistory) renderHeatmap(today time.Time) []string {
+	start := heatmapStart(today)
+	maxAnswered := history.maxAnswered(start, today)
+	emptyStyle := background.Foreground(mutedColor)
+	labelStyle := background.Foreground(textColor)
+	rows := make([]string, 7)
+	for weekday := 0; weekday < 7; weekday++ {
+		var row strings.Builder
+		row.WriteString(labelStyle.Render(heatmapWeekdayLabels[weekday] + " "))
+		for week := 0; week < heatmapWeeks(); week++ {
+			day := start.AddDate(0, 0, 7*week+weekday)
+			if day.After(today) {
+				break
+			}
+			answered := history.day(day).answered()
+			if answered == 0 {
+				row.WriteString(emptyStyle.Render(symbols.empty + " "))
+				continue
+			}
+			level := heatmapLevel(answered, maxAnswered)
+			cellStyle := background.Foreground(heatmapColors[level])
+			row.WriteString(cellStyle.Render(heatmapCellSymbol(level) + " "))
+		}
+		rows[weekday] = row.String()
+	}
+	return rows
+}
+
+func renderHeatmapLegend() string {
+	legend := background.Foreground(mutedColor).Render("less " + symbols.empty + " ")
+	for level, color := range heatmapColors {
+		legend += background.Foreground(color).Render(heatmapCellSymbol(level) + " ")
+	}
+	return legend + background.Foreground(mutedColor).Render("more")
+}
+
+var heatmapScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen heatmapScreen) View() string {
+	today := studyDay(time.Now())
+	start := heatmapStart(today)
+	lines := []string{
+		statsTitleStyle.Render(fmt.Sprintf("Practice over the last %d weeks", heatmapWeeks())),
+		background.Foreground(mutedColor).Width(boxWidth).Render(renderHeatmapMonths(start)),
+	}
+	for _, row := range screen.history.renderHeatmap(today) {
+		lines = append(lines, background.Width(boxWidth).Render(row))
+	}
+	lines = append(lines, background.Width(boxWidth).Render(renderHeatmapLegend()))
+	body := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	footer := renderHelpRow(heatmapScreenHelp[:])
+	return renderBox(body, footer)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+const historyDateLayout = "2006-01-02"
+
+// Answers taking longer than this are most likely
+// the user walking away, so they count as this much
+const maxCountedAnswerTime = 2 * time.Minute
+
+type dayRecord struct {
+	correct  uint32
+	mistakes uint32
+	seconds  uint32
+	// Experience points earned that day, see answerExperience
+	experience uint32
+}
+
+func (record dayRecord) answered() uint32 {
+	return record.correct + record.mistakes
+}
+
+// Days are keyed by the date of their study day formatted
+// with historyDateLayout, which keeps the file
+// readable and stable across time zone changes
+type practiceHistory struct {
+	days map[string]dayRecord
+}
+
+type dayRecordTOML struct {
+	Correct  uint32
+	Mistakes uint32
+	Seconds  uint32
+	// Left out of files written before experience was awarded
+	Experience uint32 `toml:",omitempty"`
+}
+
+type practiceHistoryTOML struct {
+	Days map[string]dayRecordTOML
+}
+
+func dateKey(t time.Time) string {
+	return studyDay(t).Format(historyDateLayout)
+}
+
+// Number of study days between the times, ignoring time of day
+func daysBetween(from time.Time, to time.Time) int {
+	from, to = studyDay(from), studyDay(to)
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
+func (history practiceHistory) day(t time.Time) dayRecord {
+	return history.days[dateKey(t)]
+}
+
+func (history practiceHistory) recordAnswer(correct bool, answerTime time.Duration) {
+	key := dateKey(time.Now())
+	record := history.days[key]
+	record.seconds += uint32(min(answerTime, maxCountedAnswerTime).Seconds())
+	if correct {
+		record.correct++
+	} else {
+		record.mistakes++
+	}
+	history.days[key] = record
+}
+
+func (history practiceHistory) pack() practiceHistoryTOML {
+	days := make(map[string]dayRecordTOML, len(history.days))
+	for key, record := range history.days {
+		days[key] = dayRecordTOML{record.correct, record.mistakes, record.seconds, record.experience}
+	}
+	return practiceHistoryTOML{days}
+}
+
+func (history practiceHistory) save() error {
+	if readOnly {
+		slog.Info("Read-only mode, history not saved")
+		return nil
+	}
+	return store.saveHistory(history)
+}
+
+func loadHistory() practiceHistory {
+	history, err := readHistory()
+	if err != nil {
+		logFatal("Failed to parse TOML history file", "path", historyPath, "error", err)
+		exit(historyError)
+	}
+	return history
+}
+
+func readHistory() (practiceHistory, error) {
+	return store.loadHistory()
+}
+
+func parseHistory(bytes []byte) (practiceHistory, error) {
+	history := practiceHistory{map[string]dayRecord{}}
+	var historyTOML practiceHistoryTOML
+	if err := toml.Unmarshal(bytes, &historyTOML); err != nil {
+		return history, err
+	}
+	for key, record := range historyTOML.Days {
+		if _, err := time.Parse(historyDateLayout, key); err != nil {
+			slog.Warn("Ignoring invalid date in history file", "date", key)
+			continue
+		}
+		history.days[key] = dayRecord{record.Correct, record.Mistakes, record.Seconds, record.Experience}
+	}
+	return history, nil
+}
+
+func (history practiceHistory) totalStudyTime() time.Duration {
+	var seconds uint64 = 0
+	for _, record := range history.days {
+		seconds += uint64(record.seconds)
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Days in a row with answers up to today, a streak
+// ending yesterday still lasts until today is over
+func (history practiceHistory) dailyStreak(today time.Time) int {
+	day := today
+	if history.day(day).answered() == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	streak := 0
+	for history.day(day).answered() > 0 {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+func (history practiceHistory) sumDays(lastDay time.Time, days int) dayRecord {
+	var total dayRecord
+	for i := 0; i < days; i++ {
+		record := history.day(lastDay.AddDate(0, 0, -i))
+		total.correct += record.correct
+		total.mistakes += record.mistakes
+		total.seconds += record.seconds
+		total.experience += record.experience
+	}
+	return total
+}
+
+// Accuracy over the window of days ending at the given day,
+// second value is false when nothing was answered in the window
+func (history practiceHistory) rollingAccuracy(day time.Time, window int) (float32, bool) {
+	total := history.sumDays(day, window)
+	if total.answered() == 0 {
+		return 0, false
+	}
+	return float32(total.correct) / float32(total.answered()), true
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+type homeEntry struct {
+	title string
+	// Nil screen quits
+	open func(screen homeScreen) tea.Model
+	// Next run may start on it when quitting from it
+	restorable bool
+}
+
+var homeEntries = [...]homeEntry{
+	{
+		title:      "Quiz",
+		restorable: true,
+		open: func(screen homeScreen) tea.Model {
+			// Time spent on the home screen is not answering time
+			quiz := *screen.quiz
+			quiz.questionShown = quiz.clock.elapsed()
+			return quiz
+		},
+	},
+	{
+		title:      "Statistics",
+		restorable: true,
+		open: func(screen homeScreen) tea.Model {
+			return newStatisticsScreen(screen.quiz.statistics)
+		},
+	},
+	{
+		title:      "Mistakes",
+		restorable: true,
+		open: func(screen homeScreen) tea.Model {
+			return newMistakesScreen(screen.quiz)
+		},
+	},
+	{
+		title:      "Settings",
+		restorable: true,
+		open: func(screen homeScreen) tea.Model {
+			return settingsScreen{}
+		},
+	},
+	{
+		title: "Quit",
+		open: func(screen homeScreen) tea.Model {
+			return nil
+		},
+	},
+}
+
+// Start screen, the quiz is prepared but
+// not timed until it is chosen
+type homeScreen struct {
+	quiz *quizScreen
+	menuSelection
+}
+
+func (screen homeScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen homeScreen) open() (tea.Model, tea.Cmd) {
+	next := homeEntries[screen.selected].open(screen)
+	if next == nil {
+		return screen, requestQuit
+	}
+	return screen, pushScreen(next)
+}
+
+func (screen homeScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Down):
+			screen.moveDown(len(homeEntries))
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.moveUp()
+			return screen, nil
+		case key.Matches(msg, keys.Submit):
+			return screen.open()
+		}
+	case tea.MouseMsg:
+		// Entries follow the summary and a blank line
+		firstRow := listFirstRow + len(screen.renderSummary()) + 1
+		if screen.handleMouse(msg, firstRow, len(homeEntries)) {
+			return screen.open()
+		}
+		return screen, nil
+	}
+	return screen, nil
+}
+
+func (screen homeScreen) renderSummary() []string {
+	now := time.Now()
+	due := screen.quiz.statistics.dueSummary(now)
+	streak := screen.quiz.history.dailyStreak(now)
+	level := levelOf(screen.quiz.history.totalExperience())
+	days := "days"
+	if streak == 1 {
+		days = "day"
+	}
+	return []string{
+		fmt.Sprintf(
+			"Deck: %s, %s questions",
+			bold(filepath.Base(wordDatabasePath)),
+			bold(fmt.Sprint(screen.quiz.statistics.Len())),
+		),
+		fmt.Sprintf(
+			"Due: %s, weak: %s, new: %s",
+			bold(fmt.Sprint(due.DueNow)),
+			bold(fmt.Sprint(due.Weak)),
+			bold(fmt.Sprint(due.New)),
+		),
+		fmt.Sprintf("Daily streak: %s %s", bold(fmt.Sprint(streak)), days),
+		fmt.Sprintf(
+			"Level: %s, %s/%s XP",
+			bold(fmt.Sprint(level.number)),
+			bold(fmt.Sprint(level.progress)),
+			bold(fmt.Sprint(level.needed)),
+		),
+	}
+}
+
+var homeScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Down, &keys.Up}, action: "move"},
+	{bindings: []*key.Binding{&keys.Submit}, action: "open"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen homeScreen) View() string {
+	textStyle := background.Foreground(textColor).Inline(true).MaxWidth(boxWidth)
+	lines := []string{renderMenuTitle("gem2"), ""}
+	for _, line := range screen.renderSummary() {
+		lines = append(lines, background.Width(boxWidth).Render(textStyle.Render(line)))
+	}
+	lines = append(lines, "")
+	for i, entry := range homeEntries {
+		lines = append(lines, renderMenuEntry(entry.title, nil, i == screen.selected))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return renderBox(body, renderHelpRow(homeScreenHelp[:]))
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Shell commands run on events of the quiz, empty ones are not run
+type hookCommands struct {
+	SessionEnd   string `toml:"on_session_end"`
+	RecordBroken string `toml:"on_record_broken"`
+}
+
+var hooks hookCommands
+
+// Quitting waits for the session end hook, but not forever
+const sessionEndHookTimeout = 30 * time.Second
+
+// Passed to hooks as JSON on their input and as GEM2_* variables,
+// fields which do not apply to the event are left out
+type hookEvent struct {
+	Event   string `json:"event"`
+	Deck    string `json:"deck"`
+	Correct uint32 `json:"correct"`
+	Wrong   uint32 `json:"wrong"`
+	Streak  uint32 `json:"streak"`
+	Seconds int    `json:"seconds"`
+	Level   int    `json:"level"`
+	// Session or question, for broken records only
+	Record string `json:"record,omitempty"`
+	Prompt string `json:"prompt,omitempty"`
+	Answer string `json:"answer,omitempty"`
+}
+
+func hooksPath() string {
+	return filepath.Join(configDirectory(), "hooks.toml")
+}
+
+// Unknown names are most likely typos of the known ones
+func parseHooks(data []byte) (hookCommands, error) {
+	var parsed hookCommands
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&parsed)
+	var unknown *toml.StrictMissingError
+	if errors.As(err, &unknown) {
+		return hookCommands{}, fmt.Errorf(
+			"unknown hook %q, available: on_record_broken, on_session_end",
+			strings.Join(unknown.Errors[0].Key(), "."),
+		)
+	}
+	return parsed, err
+}
+
+// No hooks are run when there is no hooks file
+func loadHooks() error {
+	path := hooksPath()
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	parsed, err := parseHooks(bytes)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	hooks = parsed
+	return nil
+}
+
+func (session *session) hookEvent(name string) hookEvent {
+	return hookEvent{
+		Event:   name,
+		Deck:    wordDatabasePath,
+		Correct: session.correctAnswers,
+		Wrong:   session.wrongAnswers,
+		Streak:  session.streak,
+		Seconds: int(session.clock.elapsed().Seconds()),
+		Level:   levelOf(session.history.totalExperience()).number,
+	}
+}
+
+func (event hookEvent) environment() []string {
+	environment := append(
+		os.Environ(),
+		"GEM2_EVENT="+event.Event,
+		"GEM2_DECK="+event.Deck,
+		"GEM2_CORRECT="+strconv.FormatUint(uint64(event.Correct), 10),
+		"GEM2_WRONG="+strconv.FormatUint(uint64(event.Wrong), 10),
+		"GEM2_STREAK="+strconv.FormatUint(uint64(event.Streak), 10),
+		"GEM2_SECONDS="+strconv.Itoa(event.Seconds),
+		"GEM2_LEVEL="+strconv.Itoa(event.Level),
+	)
+	if event.Record != "" {
+		environment = append(
+			environment,
+			"GEM2_RECORD="+event.Record,
+			"GEM2_PROMPT="+event.Prompt,
+			"GEM2_ANSWER="+event.Answer,
+		)
+	}
+	return environment
+}
+
+// Output is only kept when the UI is not running on top of it
+func runHook(ctx context.Context, command string, event hookEvent, showOutput bool) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	hook := shellCommand(ctx, command)
+	hook.Env = event.environment()
+	hook.Stdin = bytes.NewReader(data)
+	if showOutput {
+		hook.Stdout = os.Stdout
+		hook.Stderr = os.Stderr
+	}
+	slog.Info("Running hook", "event", event.Event, "command", command)
+	return hook.Run()
+}
+
+// Sessions without answers are not reported
+func (session *session) runSessionEndHook() {
+	if hooks.SessionEnd == "" || !session.isLoaded() || session.correctAnswers+session.wrongAnswers == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sessionEndHookTimeout)
+	defer cancel()
+	if err := runHook(ctx, hooks.SessionEnd, session.hookEvent("session_end"), true); err != nil {
+		slog.Error("Session end hook failed", "error", err)
+		fmt.Fprintf(os.Stderr, "Session end hook failed: %v\n", err)
+	}
+}
+
+// Only when the last answer broke a record and there is a hook for it
+func (screen quizScreen) recordBrokenEvent() (hookEvent, bool) {
+	if hooks.RecordBroken == "" || (!screen.sessionRecord && !screen.promptRecord) {
+		return hookEvent{}, false
+	}
+	event := screen.hookEvent("record_broken")
+	event.Record = "question"
+	if screen.sessionRecord {
+		event.Record = "session"
+	}
+	event.Prompt = screen.question.prompt.String()
+	event.Answer = screen.question.correctAnswer
+	return event, true
+}
+
+func (screen quizScreen) runRecordBrokenHook() tea.Cmd {
+	event, broken := screen.recordBrokenEvent()
+	if !broken {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := runHook(context.Background(), hooks.RecordBroken, event, false); err != nil {
+			return commandFailedMessage{"hook", err}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// How images of the deck are drawn, text names the file instead
+type imageProtocol int
+
+const (
+	textImages imageProtocol = iota
+	kittyImages
+	itermImages
+	sixelImages
+)
+
+var imageProtocols = map[string]imageProtocol{
+	"text":  textImages,
+	"kitty": kittyImages,
+	"iterm": itermImages,
+	"sixel": sixelImages,
+}
+
+// Text unless chosen with -images
+var currentImageProtocol imageProtocol
+
+// Images of the deck by prompt, none until the session is loaded
+var promptImages map[prompt]string
+
+const (
+	// Fewer rows than that only get the name of the file
+	minImageRows = 3
+	maxImageRows = 10
+	// Rows of the quiz while checking an answer along with the help row
+	// and the empty one below the image, which leaves the rest to it
+	quizRows = 12
+	// Terminals scale kitty and iTerm images to the cells themselves,
+	// sixels are scaled here assuming cells of about this many pixels
+	cellPixelWidth  = 10
+	cellPixelHeight = 20
+	kittyChunkSize  = 4096
+	// Removes every kitty image, they stay on top of the text otherwise
+	kittyDeleteImages = "\x1b_Ga=d,d=A,q=2\x1b\\"
+	kittyImageStart   = "\x1b_G"
+)
+
+func (protocol imageProtocol) String() string {
+	for name, known := range imageProtocols {
+		if known == protocol {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+func imageProtocolNames() []string {
+	names := make([]string, 0, len(imageProtocols))
+	for name := range imageProtocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func useImageProtocol(name string) error {
+	if name == "auto" {
+		currentImageProtocol = detectImageProtocol()
+		slog.Debug("Detected image protocol", "protocol", currentImageProtocol)
+		return nil
+	}
+	protocol, exists := imageProtocols[name]
+	if !exists {
+		return fmt.Errorf("unknown image protocol %q, available: auto, %s", name, strings.Join(imageProtocolNames(), ", "))
+	}
+	currentImageProtocol = protocol
+	return nil
+}
+
+// Terminals can not be asked without reading their answer before
+// the UI starts, so the ones known to draw images are recognized
+// by their environment and the rest get the text fallback
+func detectImageProtocol() imageProtocol {
+	term := os.Getenv("TERM")
+	// Multiplexers would need the sequences wrapped to pass them on
+	if os.Getenv("TMUX") != "" || strings.HasPrefix(term, "screen") {
+		return textImages
+	}
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty":
+		return kittyImages
+	case os.Getenv("TERM_PROGRAM") == "iTerm.app" || os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return itermImages
+	case strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "mlterm") || strings.Contains(term, "sixel"):
+		return sixelImages
+	}
+	return textImages
+}
+
+// Drawn image along with what it was drawn for
+type shownImage struct {
+	path       string
+	protocol   imageProtocol
+	maxColumns int
+	maxRows    int
+	// Sequence drawing the image, empty when it could not be read
+	sequence string
+	columns  int
+	rows     int
+	// Changes with every image drawn, see renderImageArea
+	generation int
+}
+
+// The quiz draws the same image on every frame,
+// so the last one is kept rather than read again
+var lastImage shownImage
+
+func loadImage(path string, maxColumns int, maxRows int) shownImage {
+	if lastImage.path == path &&
+		lastImage.protocol == currentImageProtocol &&
+		lastImage.maxColumns == maxColumns &&
+		lastImage.maxRows == maxRows {
+		return lastImage
+	}
+	shown := shownImage{
+		path:       path,
+		protocol:   currentImageProtocol,
+		maxColumns: maxColumns,
+		maxRows:    maxRows,
+		generation: lastImage.generation + 1,
+	}
+	lastImage = shown
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Error("Failed to read image", "path", path, "error", err)
+		return shown
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		slog.Error("Failed to decode image", "path", path, "error", err)
+		return shown
+	}
+	shown.columns, shown.rows = fitImage(decoded.Bounds().Size(), maxColumns, maxRows)
+	switch currentImageProtocol {
+	case kittyImages:
+		shown.sequence = kittyImage(decoded, shown.columns, shown.rows)
+	case itermImages:
+		shown.sequence = itermImage(data, shown.columns, shown.rows)
+	case sixelImages:
+		shown.sequence = sixelImage(decoded, shown.columns*cellPixelWidth, shown.rows*cellPixelHeight)
+	}
+	lastImage = shown
+	return shown
+}
+
+// Largest number of cells keeping the proportions of the image
+func fitImage(size image.Point, maxColumns int, maxRows int) (columns int, rows int) {
+	columns = maxColumns
+	rows = columns * cellPixelWidth * size.Y / (size.X * cellPixelHeight)
+	if rows > maxRows {
+		rows = maxRows
+		columns = rows * cellPixelHeight * size.X / (size.Y * cellPixelWidth)
+	}
+	return max(columns, 1), max(rows, 1)
+}
+
+func kittyImage(decoded image.Image, columns int, rows int) string {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, decoded); err != nil {
+		slog.Error("Failed to encode image", "error", err)
+		return ""
+	}
+	data := base64.StdEncoding.EncodeToString(encoded.Bytes())
+	var sequence strings.Builder
+	sequence.WriteString(kittyDeleteImages)
+	for first := true; ; first = false {
+		chunk := data[:min(len(data), kittyChunkSize)]
+		data = data[len(chunk):]
+		more := 0
+		if len(data) > 0 {
+			more = 1
+		}
+		// Placed without moving the cursor and without a reply,
+		// which would be taken for pressed keys
+		if first {
+			fmt.Fprintf(&sequence, "\x1b_Ga=T,f=100,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", columns, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&sequence, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+		if more == 0 {
+			return sequence.String()
+		}
+	}
+}
+
+// The file is passed on as it is, iTerm decodes it itself
+func itermImage(data []byte, columns int, rows int) string {
+	return fmt.Sprintf(
+		"\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+		len(data),
+		columns,
+		rows,
+		base64.StdEncoding.EncodeToString(data),
+	)
+}
+
+// Rows of the image area above the question. The renderer only
+// redraws changed lines, so every row carries an invisible marker
+// changing with the image, or text would not clear the previous one.
+// The image is drawn from its last row upwards, after the rows
+// above it are written, which would otherwise paint over it.
+func renderImageArea(path string, maxColumns int, maxRows int) string {
+	if currentImageProtocol == textImages || maxRows < minImageRows || !isLocalFile(path) {
+		return renderImageCaption(path)
+	}
+	shown := loadImage(path, maxColumns, maxRows)
+	if shown.sequence == "" {
+		return renderImageCaption(path)
+	}
+	marker := strings.Repeat("\x1b7", 1+shown.generation%2)
+	lines := make([]string, shown.rows)
+	for i := range lines {
+		lines[i] = marker
+	}
+	lines[len(lines)-1] = marker +
+		strings.Repeat(" ", (maxColumns-shown.columns)/2) +
+		"\x1b7" +
+		cursorUp(shown.rows-1) +
+		shown.sequence +
+		"\x1b8"
+	return strings.Join(lines, "\n")
+}
+
+func cursorUp(rows int) string {
+	if rows == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\x1b[%dA", rows)
+}
+
+func renderImageCaption(path string) string {
+	return questionStatsAlignStyle.Render(questionStatsStyle.Render("[picture: " + filepath.Base(path) + "]"))
+}
+
+func (screen quizScreen) image() string {
+	return promptImages[screen.question.prompt]
+}
+
+// Image of the question on top, taking the rows the box has to spare.
+// Its size is kept while answering and checking the answer, so the
+// same rows are left whatever the screen, to fit the taller one.
+func (screen quizScreen) withImage(body string) string {
+	path := screen.image()
+	if path == "" {
+		return body
+	}
+	rows := min(boxHeight-quizRows, maxImageRows)
+	return lipgloss.JoinVertical(lipgloss.Left, renderImageArea(path, boxWidth, rows), "", body)
+}
+
+// Kitty images stay on screen until deleted, so
+// screens without one delete whatever is left
+func clearImages(view string) string {
+	if currentImageProtocol != kittyImages || strings.Contains(view, kittyImageStart) {
+		return view
+	}
+	return kittyDeleteImages + view
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Answer as it was given, one line of the journal
+type journalEntry struct {
+	// Counts up across sessions, so that the statistics can tell
+	// which answers they were saved with. Older builds left it out.
+	Sequence uint64    `json:"sequence,omitempty"`
+	Time     time.Time `json:"time"`
+	FormClue string    `json:"form_clue"`
+	Verb     string    `json:"verb"`
+	Answer   string    `json:"answer"`
+	Correct  bool      `json:"correct"`
+	Seconds  float64   `json:"seconds"`
+}
+
+// Next to the statistics it can rebuild, so that
+// separate statistics files do not share it
+func journalPath() string {
+	return strings.TrimSuffix(statisticsPath, filepath.Ext(statisticsPath)) + ".journal"
+}
+
+// Synced before the answer is checked on screen, statistics are only
+// written every so often, and a crash before that loses nothing as the
+// answers after the last save are replayed when the statistics are loaded
+func (fileStorage) recordAnswer(entry journalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		logFatal("Unachievable JSON encoding error", "error", err)
+		exit(internalError)
+	}
+	defer lockFileWrites(journalPath())()
+	file, err := os.OpenFile(journalPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open answer journal: %w", err)
+	}
+	_, err = file.Write(append(line, '\n'))
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write answer journal: %w", err)
+	}
+	return nil
+}
+
+// A line cut short by a crash while writing it is skipped
+func readJournal(path string) ([]journalEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var entries []journalEntry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			slog.Warn("Skipping unreadable journal line", "path", path, "line", line, "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Every answer given is given again in order. Questions reset,
+// marked known or suspended since are not, the journal only has answers.
+func (statistics *statisticsDatabase) replayJournal(entries []journalEntry) {
+	for _, entry := range entries {
+		statistics.JournalSequence = max(statistics.JournalSequence, entry.Sequence)
+		prompt := prompt{FormClue: entry.FormClue, Verb: entry.Verb}
+		if entry.Correct {
+			statistics.ContinueStreakAt(prompt, entry.Time)
+		} else {
+			statistics.EndStreakAt(prompt, entry.Answer, entry.Time)
+		}
+		statistics.RecordResponseTime(prompt, time.Duration(entry.Seconds*float64(time.Second)))
+	}
+}
+
+// Answers given since the statistics were last saved, which a crash
+// kept out of them. Answers journaled by older builds have no number,
+// they were saved long ago, having been journaled before this build.
+func (statistics *statisticsDatabase) replayUnsavedAnswers() error {
+	path := journalPath()
+	entries, err := readJournal(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read answer journal %s: %w", path, err)
+	}
+	var unsaved []journalEntry
+	for _, entry := range entries {
+		if entry.Sequence > statistics.JournalSequence {
+			unsaved = append(unsaved, entry)
+		}
+	}
+	if len(unsaved) == 0 {
+		return nil
+	}
+	statistics.replayJournal(unsaved)
+	slog.Warn("Replayed answers given after statistics were last saved", "path", path, "answers", len(unsaved))
+	return nil
+}
+
+// Reports whether there was a journal to rebuild the statistics from
+func (statistics *statisticsDatabase) rebuildFromJournal() (bool, error) {
+	path := journalPath()
+	entries, err := readJournal(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not read answer journal %s: %w", path, err)
+	}
+	if len(entries) == 0 {
+		return false, nil
+	}
+	statistics.replayJournal(entries)
+	slog.Warn("Rebuilt statistics from the answer journal", "path", path, "answers", len(entries))
+	return true, nil
+}
+
+func (screen quizScreen) journalAnswer(correct bool) {
+	if readOnly {
+		return
+	}
+	screen.statistics.JournalSequence++
+	entry := journalEntry{
+		Sequence: screen.statistics.JournalSequence,
+		Time:     time.Now().Truncate(time.Second),
+		FormClue: screen.question.prompt.FormClue,
+		Verb:     screen.question.prompt.Verb,
+		Answer:   screen.inputField.Value(),
+		Correct:  correct,
+		Seconds:  min(screen.questionTime(), maxCountedAnswerTime).Seconds(),
+	}
+	// Statistics are still saved as usual, failing here is not worth a stop
+	if err := store.recordAnswer(entry); err != nil {
+		slog.Error("Failed to journal answer", "error", err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Overlay with every binding, opened on top of any screen
+type keyHelpScreen struct {
+	listPosition
+}
+
+// Screens with a focused text input, where
+// printable keys are typed rather than handled
+type typingScreen interface {
+	isTyping() bool
+}
+
+func typedAsText(screen tea.Model, msg tea.KeyMsg) bool {
+	typing, isTypingScreen := screen.(typingScreen)
+	return isTypingScreen && typing.isTyping() && (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace)
+}
+
+func (m *model) toggleKeyHelp() {
+	if _, isOpen := m.top().(keyHelpScreen); isOpen {
+		m.pop()
+		return
+	}
+	m.push(keyHelpScreen{})
+}
+
+func (screen keyHelpScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen keyHelpScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back):
+			return screen, popScreen
+		case key.Matches(msg, keys.Down):
+			screen.scrollDown(len(keys.named()), listShownRows())
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.scrollUp()
+			return screen, nil
+		}
+	}
+	return screen, nil
+}
+
+var keyHelpScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Down, &keys.Up}, action: "move"},
+	{bindings: []*key.Binding{&keys.Help}, action: "close"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+}
+
+func (screen keyHelpScreen) View() string {
+	screen.fit(listShownRows())
+	named := keys.named()
+	keysWidth := 0
+	for _, binding := range named {
+		keysWidth = max(keysWidth, lipgloss.Width(keyNames(binding.binding.Keys())))
+	}
+	lines := []string{statsTitleStyle.Render("Keys"), ""}
+	for row := 0; row < listShownRows(); row++ {
+		index := screen.firstShownIndex + row
+		if index >= len(named) {
+			break
+		}
+		selected := row == screen.selectedRow
+		names := helpKeyStyle.Width(keysWidth + 2).Render(keyNames(named[index].binding.Keys()))
+		lines = append(lines, names+promptStatsEntryStyle.
+			Bold(selected).
+			Italic(selected).
+			Inline(true).
+			MaxWidth(boxWidth-lipgloss.Width(names)).
+			Render(named[index].description))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return renderBox(body, renderHelpRow(keyHelpScreenHelp[:]))
+}
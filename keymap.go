@@ -0,0 +1,319 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+type keyMap struct {
+	Submit        key.Binding
+	Menu          key.Binding
+	Stats         key.Binding
+	Dashboard     key.Binding
+	Quit          key.Binding
+	AltScreen     key.Binding
+	Up            key.Binding
+	Down          key.Binding
+	Back          key.Binding
+	Calendar      key.Binding
+	ReplayDay     key.Binding
+	ReplayWeek    key.Binding
+	Toggle        key.Binding
+	Retry         key.Binding
+	SaveAs        key.Binding
+	Continue      key.Binding
+	Help          key.Binding
+	Picker        key.Binding
+	PlayAudio     key.Binding
+	Lookup        key.Binding
+	Copy          key.Binding
+	CopyAll       key.Binding
+	Conjugation   key.Binding
+	FilterForm    key.Binding
+	FilterMastery key.Binding
+	Drill         key.Binding
+	Note          key.Binding
+	Left          key.Binding
+	Right         key.Binding
+	Known         key.Binding
+	Learn         key.Binding
+	Queue         key.Binding
+	Bulk          key.Binding
+	Pin           key.Binding
+	// Lists with vim style navigation
+	Top          key.Binding
+	Bottom       key.Binding
+	HalfPageDown key.Binding
+	HalfPageUp   key.Binding
+	PageDown     key.Binding
+	PageUp       key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Submit:        key.NewBinding(key.WithKeys("enter")),
+		Menu:          key.NewBinding(key.WithKeys("tab")),
+		Stats:         key.NewBinding(key.WithKeys("ctrl+s")),
+		Dashboard:     key.NewBinding(key.WithKeys("ctrl+d")),
+		Quit:          key.NewBinding(key.WithKeys("esc")),
+		AltScreen:     key.NewBinding(key.WithKeys("ctrl+a")),
+		Up:            key.NewBinding(key.WithKeys("k", "up")),
+		Down:          key.NewBinding(key.WithKeys("j", "down")),
+		Back:          key.NewBinding(key.WithKeys("backspace")),
+		Calendar:      key.NewBinding(key.WithKeys("c")),
+		ReplayDay:     key.NewBinding(key.WithKeys("d")),
+		ReplayWeek:    key.NewBinding(key.WithKeys("w")),
+		Toggle:        key.NewBinding(key.WithKeys(" ")),
+		Retry:         key.NewBinding(key.WithKeys("r")),
+		SaveAs:        key.NewBinding(key.WithKeys("a")),
+		Continue:      key.NewBinding(key.WithKeys("c")),
+		Help:          key.NewBinding(key.WithKeys("?", "f1")),
+		Picker:        key.NewBinding(key.WithKeys("ctrl+k")),
+		PlayAudio:     key.NewBinding(key.WithKeys("ctrl+p")),
+		Lookup:        key.NewBinding(key.WithKeys("d")),
+		Copy:          key.NewBinding(key.WithKeys("y")),
+		CopyAll:       key.NewBinding(key.WithKeys("Y")),
+		Conjugation:   key.NewBinding(key.WithKeys("t")),
+		FilterForm:    key.NewBinding(key.WithKeys("ctrl+f")),
+		FilterMastery: key.NewBinding(key.WithKeys("ctrl+t")),
+		Drill:         key.NewBinding(key.WithKeys("ctrl+r")),
+		Note:          key.NewBinding(key.WithKeys("n")),
+		Left:          key.NewBinding(key.WithKeys("h", "left")),
+		Right:         key.NewBinding(key.WithKeys("l", "right")),
+		Known:         key.NewBinding(key.WithKeys("y")),
+		Learn:         key.NewBinding(key.WithKeys("n")),
+		Queue:         key.NewBinding(key.WithKeys("ctrl+n")),
+		Bulk:          key.NewBinding(key.WithKeys("ctrl+b")),
+		Pin:           key.NewBinding(key.WithKeys("p")),
+
+		Top:          key.NewBinding(key.WithKeys("g", "home")),
+		Bottom:       key.NewBinding(key.WithKeys("G", "end")),
+		HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d")),
+		HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u")),
+		PageDown:     key.NewBinding(key.WithKeys("pgdown")),
+		PageUp:       key.NewBinding(key.WithKeys("pgup")),
+	}
+}
+
+// Help rows keep pointers into it, so a loaded keymap
+// is copied over it rather than replacing the variable
+var keys = defaultKeyMap()
+
+type namedBinding struct {
+	// Used in the keymap file
+	name        string
+	description string
+	binding     *key.Binding
+}
+
+// In the order of the help overlay
+func (keys *keyMap) named() []namedBinding {
+	return []namedBinding{
+		{"submit", "submit, next, open, confirm", &keys.Submit},
+		{"menu", "menu, cancel path entry", &keys.Menu},
+		{"stats", "statistics", &keys.Stats},
+		{"dashboard", "dashboard", &keys.Dashboard},
+		{"up", "move up", &keys.Up},
+		{"down", "move down", &keys.Down},
+		{"top", "first entry, letters pressed twice", &keys.Top},
+		{"bottom", "last entry, or entry number typed before", &keys.Bottom},
+		{"half_page_down", "half a page down", &keys.HalfPageDown},
+		{"half_page_up", "half a page up", &keys.HalfPageUp},
+		{"page_down", "page down", &keys.PageDown},
+		{"page_up", "page up", &keys.PageUp},
+		{"back", "back", &keys.Back},
+		{"calendar", "practice calendar", &keys.Calendar},
+		{"replay_day", "replay mistakes of a day", &keys.ReplayDay},
+		{"replay_week", "replay mistakes of a week", &keys.ReplayWeek},
+		{"toggle", "keep or reset statistics", &keys.Toggle},
+		{"retry", "retry saving", &keys.Retry},
+		{"save_as", "save elsewhere", &keys.SaveAs},
+		{"continue", "continue without saving", &keys.Continue},
+		{"picker", "special characters for the answer", &keys.Picker},
+		{"play_audio", "play the audio clip of the question", &keys.PlayAudio},
+		{"lookup", "look the verb up in the dictionary", &keys.Lookup},
+		{"copy", "copy the correct answer", &keys.Copy},
+		{"copy_all", "copy the question with its answer", &keys.CopyAll},
+		{"conjugation", "conjugation table of the verb", &keys.Conjugation},
+		{"filter_form", "show one form or all of them", &keys.FilterForm},
+		{"filter_mastery", "show any, learning, mastered or suspended questions", &keys.FilterMastery},
+		{"drill", "drill the shown questions", &keys.Drill},
+		{"bulk", "reset, suspend, retire or boost the shown questions", &keys.Bulk},
+		{"note", "note on the question", &keys.Note},
+		{"pin", "ask the question first in the next session", &keys.Pin},
+		{"left", "previous character", &keys.Left},
+		{"right", "next character", &keys.Right},
+		{"known", "already know the question", &keys.Known},
+		{"learn", "need to learn the question", &keys.Learn},
+		{"queue", "questions coming up next", &keys.Queue},
+		{"help", "this list", &keys.Help},
+		{"alt_screen", "toggle full screen", &keys.AltScreen},
+		{"quit", "save and exit", &keys.Quit},
+	}
+}
+
+func (keys *keyMap) find(name string) (*key.Binding, bool) {
+	for _, named := range keys.named() {
+		if named.name == name {
+			return named.binding, true
+		}
+	}
+	return nil, false
+}
+
+// Bindings which are active at the same time and so must not share
+// a key. While typing, printable keys belong to the text, only help
+// may have some as long as it has other keys to use while typing.
+type keyContext struct {
+	name   string
+	typing bool
+	// Digits are taken as a count for the next key
+	counted  bool
+	bindings []string
+}
+
+var keyContexts = [...]keyContext{
+	{name: "quiz", typing: true, bindings: []string{"submit", "menu", "stats", "dashboard", "queue", "picker", "play_audio", "help", "quit", "alt_screen"}},
+	{name: "answer check", bindings: []string{"submit", "menu", "stats", "dashboard", "queue", "play_audio", "lookup", "copy", "copy_all", "conjugation", "note", "pin", "help", "quit", "alt_screen"}},
+	{name: "picker", bindings: []string{"left", "right", "submit", "picker", "back", "menu", "stats", "play_audio", "help", "quit", "alt_screen"}},
+	{name: "menus", bindings: []string{"up", "down", "submit", "menu", "back", "help", "quit", "alt_screen"}},
+	{name: "lists", bindings: []string{"up", "down", "back", "stats", "replay_day", "replay_week", "help", "quit", "alt_screen"}},
+	{name: "statistics", counted: true, bindings: []string{"up", "down", "top", "bottom", "half_page_down", "half_page_up", "page_down", "page_up", "back", "stats", "conjugation", "pin", "help", "quit", "alt_screen"}},
+	{name: "dashboard", bindings: []string{"calendar", "dashboard", "back", "help", "quit", "alt_screen"}},
+	{name: "session summary", bindings: []string{"back", "help", "quit", "alt_screen"}},
+	{name: "deck changes", bindings: []string{"submit", "help", "quit", "alt_screen"}},
+	{name: "reconciliation", bindings: []string{"up", "down", "toggle", "submit", "help", "quit", "alt_screen"}},
+	{name: "failed save", bindings: []string{"retry", "save_as", "continue", "help", "quit", "alt_screen"}},
+	{name: "up next", bindings: []string{"queue", "back", "help", "quit", "alt_screen"}},
+	{name: "triage", bindings: []string{"known", "learn", "submit", "back", "menu", "help", "quit", "alt_screen"}},
+	{name: "quit confirmation", bindings: []string{"submit", "back", "help", "quit", "alt_screen"}},
+	{name: "conjugation table", bindings: []string{"up", "down", "back", "conjugation", "help", "quit", "alt_screen"}},
+	{name: "verb list", typing: true, bindings: []string{"submit", "menu", "back", "help", "quit", "alt_screen"}},
+	{name: "browser", typing: true, bindings: []string{"submit", "menu", "back", "filter_form", "filter_mastery", "drill", "bulk", "help", "quit", "alt_screen"}},
+	{name: "note entry", typing: true, bindings: []string{"submit", "menu", "help", "quit", "alt_screen"}},
+	{name: "path entry", typing: true, bindings: []string{"submit", "menu", "help", "quit", "alt_screen"}},
+}
+
+func isPrintableKey(pressed string) bool {
+	return utf8.RuneCountInString(pressed) == 1
+}
+
+func (keys *keyMap) checkConflicts() error {
+	for _, context := range keyContexts {
+		// Interrupting always quits, whatever the keymap says
+		used := map[string]string{"ctrl+c": "interrupt"}
+		if context.counted {
+			for digit := '0'; digit <= '9'; digit++ {
+				used[string(digit)] = "count"
+			}
+		}
+		for _, name := range context.bindings {
+			binding, _ := keys.find(name)
+			typable := 0
+			for _, pressed := range binding.Keys() {
+				if context.typing && isPrintableKey(pressed) {
+					typable++
+					if name == "help" && typable < len(binding.Keys()) {
+						continue
+					}
+					return fmt.Errorf("%s uses %q, which is typed as text in %s", name, keyName(pressed), context.name)
+				}
+				if other, exists := used[pressed]; exists {
+					return fmt.Errorf("%s and %s both use %q in %s", other, name, keyName(pressed), context.name)
+				}
+				used[pressed] = name
+			}
+		}
+	}
+	return nil
+}
+
+// Keys missing from the file keep their default bindings
+func parseKeyMap(bytes []byte) (keyMap, error) {
+	var remapped map[string][]string
+	if err := toml.Unmarshal(bytes, &remapped); err != nil {
+		return keyMap{}, err
+	}
+	parsed := defaultKeyMap()
+	for name, pressed := range remapped {
+		binding, exists := parsed.find(name)
+		if !exists {
+			return keyMap{}, fmt.Errorf("unknown action %q, available: %s", name, strings.Join(keyActionNames(), ", "))
+		}
+		if len(pressed) == 0 {
+			return keyMap{}, fmt.Errorf("%s needs at least one key", name)
+		}
+		for i := range pressed {
+			if pressed[i] == "space" {
+				pressed[i] = " "
+			}
+		}
+		binding.SetKeys(pressed...)
+	}
+	return parsed, parsed.checkConflicts()
+}
+
+func keyActionNames() []string {
+	var names []string
+	for _, named := range keys.named() {
+		names = append(names, named.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func keyMapPath() string {
+	return filepath.Join(configDirectory(), "keys.toml")
+}
+
+// Defaults stay in use when there is no keymap file
+func loadKeyMap() error {
+	path := keyMapPath()
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	parsed, err := parseKeyMap(bytes)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	keys = parsed
+	return nil
+}
+
+// How a key is shown in help rows
+func keyName(pressed string) string {
+	switch pressed {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case " ":
+		return "space"
+	}
+	return pressed
+}
+
+// Keys not drawable in ASCII mode are left out
+func keyNames(pressedKeys []string) string {
+	var names []string
+	for _, pressed := range pressedKeys {
+		name := keyName(pressed)
+		if !symbols.asciiOnlyHelp || isASCII(name) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "/")
+}
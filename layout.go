@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+const (
+	defaultBoxWidth  = 45
+	defaultBoxHeight = 12
+	// Below these the screens no longer fit
+	minBoxWidth  = 36
+	minBoxHeight = 11
+	// Above these lines get too long to read comfortably
+	maxBoxWidth  = 72
+	maxBoxHeight = 20
+)
+
+// How the screens are placed in the terminal
+type screenLayout int
+
+const (
+	boxLayout screenLayout = iota
+	// Box takes the whole terminal, see fullscreen.go
+	fullLayout
+	// A few lines without the box, see compact.go
+	compactLayout
+)
+
+var layoutNames = [...]string{"box", "full", "compact"}
+
+var currentLayout screenLayout
+
+func (layout screenLayout) String() string {
+	return layoutNames[layout]
+}
+
+func (layout *screenLayout) Set(value string) error {
+	for i, name := range layoutNames {
+		if name == value {
+			*layout = screenLayout(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown layout %q, available: %s", value, strings.Join(layoutNames[:], ", "))
+}
+
+// Applied at once, with the size of the terminal seen last
+func cycleLayout() {
+	currentLayout = (currentLayout + 1) % screenLayout(len(layoutNames))
+	resizeBox(terminalWidth, terminalHeight)
+}
+
+func formatLayout() string {
+	if currentLayout == fullLayout {
+		return "full screen"
+	}
+	return currentLayout.String()
+}
+
+// Size of the terminal seen last, zero until it is known
+var terminalWidth, terminalHeight int
+
+// Box and its border take the whole terminal until they reach the
+// maximal size, on tiny terminals the box is clipped as before. Screens
+// without a compact view keep the usual box in the compact layout.
+func resizeBox(width int, height int) {
+	const border = 2
+	terminalWidth, terminalHeight = width, height
+	if width == 0 || height == 0 {
+		return
+	}
+	if currentLayout == fullLayout {
+		resizeFullScreen(width, height)
+	} else {
+		boxWidth = min(max(width-2*horizontalPadding-border, minBoxWidth), maxBoxWidth)
+		boxHeight = min(max(height-2*verticalPadding-border-statusBarHeight, minBoxHeight), maxBoxHeight)
+		sidebarShown = false
+	}
+	totalBoxWidth = boxWidth + 2*horizontalPadding
+	totalBoxHeight = boxHeight + 2*verticalPadding
+
+	buildStyles()
+}
+
+// Help row goes to the bottom of the box, unless
+// the body is too tall for that on a small terminal
+func renderBox(body string, footer string) string {
+	spacing := max(boxHeight-lipgloss.Height(body)-lipgloss.Height(footer), 0)
+	content := body + strings.Repeat("\n", spacing+1) + footer
+	style := boxStyle
+	if answerAnimation != nil {
+		style = answerAnimation.apply(style)
+	}
+	if flashColor != nil {
+		style = style.BorderForeground(flashColor)
+	}
+	return style.Render(content)
+}
+
+// Title with a blank line, then detail line and help row
+func listShownRows() int {
+	return boxHeight - 2 - 2
+}
+
+// Width of the box and the sidebar next to it, border included
+func layoutWidth() int {
+	if currentLayout == compactLayout {
+		return compactWidth()
+	}
+	width := lipgloss.Width(boxStyle.Render(""))
+	if sidebarShown {
+		width += sidebarWidth + 2*sidebarPadding + 2
+	}
+	return width
+}
+
+// Title of a list with the number of the selected
+// entry and the length of the list on the right
+func renderListTitle(title string, position listPosition, total int) string {
+	if total == 0 {
+		return statsTitleStyle.Render(title)
+	}
+	indicator := questionStatsStyle.Render(fmt.Sprintf("%d/%d", position.selectedIndex()+1, total))
+	return statsTitleStyle.Width(boxWidth-lipgloss.Width(indicator)).Render(title) + indicator
+}
+
+// Help entries are moved to the next line
+// instead of running past the box border
+func wrapHelpRow(entries []string) string {
+	var lines []string
+	line := ""
+	for _, entry := range entries {
+		switch {
+		case line == "":
+			line = entry
+		case lipgloss.Width(line+helpSeparator+entry) <= boxWidth:
+			line += helpSeparator + entry
+		default:
+			lines = append(lines, line)
+			line = entry
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, append(lines, line)...)
+}
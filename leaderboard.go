@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+	"github.com/kligunov-id/gem2/leaderboard"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Results are only shared when the server is set, see cmd/gem2-leaderboard
+type leaderboardSettings struct {
+	URL string `toml:"url"`
+	// Defaults to the profile, or else the user name
+	Name string `toml:"name"`
+}
+
+var leaderboardConfig leaderboardSettings
+
+// Quitting waits for the results to be posted, but not for long
+const leaderboardTimeout = 5 * time.Second
+
+func leaderboardPath() string {
+	return filepath.Join(configDirectory(), "leaderboard.toml")
+}
+
+func parseLeaderboard(data []byte) (leaderboardSettings, error) {
+	var settings leaderboardSettings
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		return leaderboardSettings{}, err
+	}
+	if settings.URL != "" {
+		if _, err := url.ParseRequestURI(settings.URL); err != nil {
+			return leaderboardSettings{}, fmt.Errorf("invalid url: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+// Nothing is shared when there is no leaderboard file
+func loadLeaderboard() error {
+	path := leaderboardPath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	settings, err := parseLeaderboard(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	leaderboardConfig = settings
+	return nil
+}
+
+func leaderboardName() string {
+	switch {
+	case leaderboardConfig.Name != "":
+		return leaderboardConfig.Name
+	case profileName != "":
+		return profileName
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return "anonymous"
+}
+
+// Made up on the first post, the server gives the name to whoever
+// posted under it first, so it is kept with the state rather than
+// the settings, which are more likely to be shared
+func leaderboardTokenPath() string {
+	return filepath.Join(stateDirectory(), "leaderboard-token")
+}
+
+func leaderboardToken() (string, error) {
+	path := leaderboardTokenPath()
+	data, err := os.ReadFile(path)
+	if err == nil && len(bytes.TrimSpace(data)) > 0 {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("could not read leaderboard token: %w", err)
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("could not make leaderboard token: %w", err)
+	}
+	token := hex.EncodeToString(random)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("could not write leaderboard token: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("could not write leaderboard token: %w", err)
+	}
+	slog.Info("Made leaderboard token", "path", path)
+	return token, nil
+}
+
+func leaderboardEndpoint() string {
+	return leaderboardConfig.URL + "/results"
+}
+
+// Totals of the whole day rather than of the session,
+// so posting again after another session replaces them
+func dailyResult(history practiceHistory, now time.Time) leaderboard.Result {
+	today := history.day(now)
+	return leaderboard.Result{
+		Name:       leaderboardName(),
+		Date:       dateKey(now),
+		Correct:    today.correct,
+		Wrong:      today.mistakes,
+		Experience: today.experience,
+	}
+}
+
+func postResult(ctx context.Context, result leaderboard.Result) error {
+	token, err := leaderboardToken()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, leaderboardEndpoint(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusNoContent {
+		return leaderboardError(response)
+	}
+	return nil
+}
+
+func leaderboardError(response *http.Response) error {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.NewDecoder(response.Body).Decode(&body) == nil && body.Error != "" {
+		return fmt.Errorf("leaderboard: %s", body.Error)
+	}
+	return fmt.Errorf("leaderboard: %s", response.Status)
+}
+
+func fetchResults(ctx context.Context, date string) ([]leaderboard.Result, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, leaderboardEndpoint()+"?date="+url.QueryEscape(date), nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, leaderboardError(response)
+	}
+	var results []leaderboard.Result
+	if err := json.NewDecoder(response.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("leaderboard: %w", err)
+	}
+	return results, nil
+}
+
+// Sessions without answers have nothing new to share
+func (session *session) postLeaderboardResult() {
+	if leaderboardConfig.URL == "" || !session.isLoaded() || session.correctAnswers+session.wrongAnswers == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), leaderboardTimeout)
+	defer cancel()
+	if err := postResult(ctx, dailyResult(*session.history, time.Now())); err != nil {
+		slog.Error("Failed to post leaderboard result", "error", err)
+		fmt.Fprintf(os.Stderr, "Results were not shared: %v\n", err)
+		return
+	}
+	slog.Info("Posted leaderboard result", "url", leaderboardConfig.URL)
+}
+
+type leaderboardLoadedMessage struct {
+	results []leaderboard.Result
+	err     error
+}
+
+// Answers of today are posted first, so that
+// the board is up to date with the session too
+func loadLeaderboardResults(result leaderboard.Result) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), leaderboardTimeout)
+		defer cancel()
+		if result.Correct+result.Wrong > 0 {
+			if err := postResult(ctx, result); err != nil {
+				return leaderboardLoadedMessage{err: err}
+			}
+		}
+		results, err := fetchResults(ctx, result.Date)
+		if err != nil {
+			return leaderboardLoadedMessage{err: err}
+		}
+		leaderboard.Rank(results)
+		return leaderboardLoadedMessage{results: results}
+	}
+}
+
+type leaderboardScreen struct {
+	listPosition
+	// Own result, which is also what is posted
+	own     leaderboard.Result
+	results []leaderboard.Result
+	loaded  bool
+	err     error
+}
+
+func newLeaderboardScreen(history practiceHistory) leaderboardScreen {
+	return leaderboardScreen{own: dailyResult(history, time.Now())}
+}
+
+func (screen leaderboardScreen) Init() tea.Cmd {
+	if leaderboardConfig.URL == "" {
+		return nil
+	}
+	return loadLeaderboardResults(screen.own)
+}
+
+func (screen leaderboardScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case leaderboardLoadedMessage:
+		if msg.err != nil {
+			slog.Error("Failed to load leaderboard", "error", msg.err)
+		}
+		screen.results, screen.err, screen.loaded = msg.results, msg.err, true
+		return screen, nil
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back):
+			return screen, popScreen
+		case key.Matches(msg, keys.Down):
+			screen.scrollDown(len(screen.results), listShownRows())
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.scrollUp()
+			return screen, nil
+		}
+	case tea.MouseMsg:
+		screen.listPosition.handleMouse(msg, len(screen.results), listShownRows())
+		return screen, nil
+	}
+	return screen, nil
+}
+
+// Own result is highlighted, so it is easy to find among classmates
+func (screen leaderboardScreen) renderResult(rank int, result leaderboard.Result, selected bool) string {
+	style := promptStatsEntryStyle
+	if result.Name == screen.own.Name {
+		style = style.Foreground(accentColor)
+	}
+	score := questionStatsStyle.Render(fmt.Sprintf(
+		"%d XP, %d/%d", result.Experience, result.Correct, result.Correct+result.Wrong,
+	))
+	entry := fmt.Sprintf("%d. %s", rank, result.Name)
+	if selected {
+		entry = "> " + entry
+	}
+	return style.
+		Bold(selected).
+		Italic(selected).
+		Width(boxWidth-lipgloss.Width(score)).
+		AlignHorizontal(lipgloss.Left).
+		Render(entry) +
+		score
+}
+
+var leaderboardScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Up}, action: "up"},
+	{bindings: []*key.Binding{&keys.Down}, action: "down"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen leaderboardScreen) View() string {
+	screen.fit(listShownRows())
+	title := "Leaderboard, " + screen.own.Date
+	lines := []string{renderListTitle(title, screen.listPosition, len(screen.results)), ""}
+	message := ""
+	switch {
+	case leaderboardConfig.URL == "":
+		message = "not set up, see leaderboard.toml in the config directory"
+	case !screen.loaded:
+		message = "loading..."
+	case screen.err != nil:
+		message = screen.err.Error()
+	case len(screen.results) == 0:
+		message = "nobody has practiced today yet"
+	}
+	if message != "" {
+		lines = append(lines, questionStatsAlignStyle.Render(questionStatsStyle.MaxWidth(boxWidth).Render(message)))
+	}
+	for row := 0; row < listShownRows(); row++ {
+		index := screen.firstShownIndex + row
+		if index >= len(screen.results) {
+			break
+		}
+		lines = append(lines, screen.renderResult(index+1, screen.results[index], row == screen.selectedRow))
+	}
+	footer := renderHelpRow(leaderboardScreenHelp[:])
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), footer)
+}
@@ -0,0 +1,289 @@
+package leaderboard
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	DateLayout = "2006-01-02"
+	// Results are a handful of numbers, anything larger is not one
+	maxRequestBytes = 4 << 10
+	maxNameLength   = 32
+	// Older days are forgotten, nobody scrolls back that far
+	keptDays = 31
+	// Far more than a study group, so that posting under
+	// made up names can not grow the board without end
+	maxPlayersPerDay = 200
+	// Random tokens of clients are far shorter, see Post
+	maxTokenLength = 128
+)
+
+var (
+	ErrNameTaken = errors.New("the name is taken by another player, pick another one in leaderboard.toml")
+	ErrDayFull   = errors.New("too many players posted results for the day")
+	// Wraps errors of writing the file, any other error is of the post
+	ErrNotSaved = errors.New("could not save the result")
+)
+
+// Totals of one player for one day, posted again after every
+// session, so the latest post replaces the earlier ones
+type Result struct {
+	Name       string `json:"name"`
+	Date       string `json:"date"`
+	Correct    uint32 `json:"correct"`
+	Wrong      uint32 `json:"wrong"`
+	Experience uint32 `json:"experience"`
+}
+
+func (result Result) Validate() error {
+	if result.Name == "" || utf8.RuneCountInString(result.Name) > maxNameLength {
+		return fmt.Errorf("name must be 1 to %d characters long", maxNameLength)
+	}
+	if _, err := time.Parse(DateLayout, result.Date); err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", result.Date)
+	}
+	return nil
+}
+
+// Most experience first, as it rewards both answering
+// a lot and answering hard questions right
+func Rank(results []Result) {
+	slices.SortFunc(results, func(a, b Result) int {
+		return cmp.Or(
+			cmp.Compare(b.Experience, a.Experience),
+			cmp.Compare(b.Correct, a.Correct),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+}
+
+// Results by day and player name, kept in a JSON file
+type Board struct {
+	mutex sync.Mutex
+	path  string
+	days  map[string]map[string]Result
+	// Hash of the token of the player who claimed each name
+	players map[string]string
+}
+
+type fileJSON struct {
+	Players map[string]string `json:"players"`
+	Results []Result          `json:"results"`
+}
+
+// Missing file is an empty board. Files written before names
+// were claimed are a list of results, their names are free.
+func Open(path string) (*Board, error) {
+	board := &Board{path: path, days: make(map[string]map[string]Result), players: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return board, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file fileJSON
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		err = json.Unmarshal(data, &file.Results)
+	} else {
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, result := range file.Results {
+		board.add(result)
+	}
+	if file.Players != nil {
+		board.players = file.Players
+	}
+	return board, nil
+}
+
+// Only the hash is kept, the file gives away no tokens
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func (board *Board) add(result Result) {
+	day, exists := board.days[result.Date]
+	if !exists {
+		day = make(map[string]Result)
+		board.days[result.Date] = day
+	}
+	day[result.Name] = result
+}
+
+// Names nobody posted under on the days kept are free again
+func (board *Board) forgetOldDays(today time.Time) {
+	oldest := today.AddDate(0, 0, -keptDays).Format(DateLayout)
+	for date := range board.days {
+		if date < oldest {
+			delete(board.days, date)
+		}
+	}
+	active := make(map[string]bool)
+	for _, day := range board.days {
+		for name := range day {
+			active[name] = true
+		}
+	}
+	for name := range board.players {
+		if !active[name] {
+			delete(board.players, name)
+		}
+	}
+}
+
+// Written to a temporary file first, so that a crash
+// while saving does not lose the whole board
+func (board *Board) save() error {
+	file := fileJSON{Players: board.players}
+	for _, day := range board.days {
+		for _, result := range day {
+			file.Results = append(file.Results, result)
+		}
+	}
+	slices.SortFunc(file.Results, func(a, b Result) int {
+		return cmp.Or(cmp.Compare(a.Date, b.Date), cmp.Compare(a.Name, b.Name))
+	})
+	data, err := json.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return err
+	}
+	temporary := board.path + ".tmp"
+	if err := os.WriteFile(temporary, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temporary, board.path)
+}
+
+// Clients make up a random token and send it with every post, the
+// first post under a name claims it for the token. Only days around
+// today on the server are accepted, whatever the time zone of the client.
+func (board *Board) Post(result Result, token string) error {
+	if err := result.Validate(); err != nil {
+		return err
+	}
+	if token == "" || len(token) > maxTokenLength {
+		return fmt.Errorf("token must be 1 to %d characters long", maxTokenLength)
+	}
+	now := time.Now()
+	yesterday, tomorrow := now.AddDate(0, 0, -1).Format(DateLayout), now.AddDate(0, 0, 1).Format(DateLayout)
+	if result.Date < yesterday || result.Date > tomorrow {
+		return fmt.Errorf("date %s is not between %s and %s", result.Date, yesterday, tomorrow)
+	}
+	board.mutex.Lock()
+	defer board.mutex.Unlock()
+	hash := hashToken(token)
+	claimed, exists := board.players[result.Name]
+	if exists && subtle.ConstantTimeCompare([]byte(claimed), []byte(hash)) != 1 {
+		return ErrNameTaken
+	}
+	day := board.days[result.Date]
+	if _, posted := day[result.Name]; !posted && len(day) >= maxPlayersPerDay {
+		return ErrDayFull
+	}
+	board.players[result.Name] = hash
+	board.add(result)
+	board.forgetOldDays(now)
+	if err := board.save(); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotSaved, err)
+	}
+	return nil
+}
+
+// Ranked results of the day
+func (board *Board) Day(date string) []Result {
+	board.mutex.Lock()
+	defer board.mutex.Unlock()
+	results := make([]Result, 0, len(board.days[date]))
+	for _, result := range board.days[date] {
+		results = append(results, result)
+	}
+	Rank(results)
+	return results
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Error("Failed to write response", "error", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (board *Board) handlePost(w http.ResponseWriter, r *http.Request) {
+	var result Result
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err := decoder.Decode(&result); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid result: %w", err))
+		return
+	}
+	if err := result.Validate(); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	token, isBearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !isBearer {
+		token = ""
+	}
+	err := board.Post(result, token)
+	switch {
+	case errors.Is(err, ErrNameTaken):
+		writeJSONError(w, http.StatusForbidden, err)
+		return
+	case errors.Is(err, ErrDayFull):
+		writeJSONError(w, http.StatusTooManyRequests, err)
+		return
+	case errors.Is(err, ErrNotSaved):
+		slog.Error("Failed to save leaderboard", "path", board.path, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, ErrNotSaved)
+		return
+	case err != nil:
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	slog.Info("Result posted", "name", result.Name, "date", result.Date, "experience", result.Experience)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Today of the server unless a date is given
+func (board *Board) handleGet(w http.ResponseWriter, r *http.Request) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().Format(DateLayout)
+	}
+	if _, err := time.Parse(DateLayout, date); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date))
+		return
+	}
+	writeJSON(w, http.StatusOK, board.Day(date))
+}
+
+func (board *Board) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /results", board.handlePost)
+	mux.HandleFunc("GET /results", board.handleGet)
+	return mux
+}
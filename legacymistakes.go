@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// Plain text blocks the very first versions appended to a file named
+// "mistakes" for every mistake, before it was kept as TOML:
+//
+//	Question ich + sein:
+//	    Correct: bin
+//	    Answer: bist
+//
+// Blocks carry no time, every mistake gets the time the file was last
+// written. Malformed blocks are skipped, as the old reader did.
+func parseLegacyMistakes(data []byte, written time.Time) (mistakes []legacyMistakeTOML, skipped int) {
+	var current legacyMistakeTOML
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Question ") && strings.HasSuffix(line, ":"):
+			if current.Verb != "" {
+				skipped++
+			}
+			question := strings.TrimSuffix(strings.TrimPrefix(line, "Question "), ":")
+			formClue, verb, found := strings.Cut(question, " + ")
+			if !found {
+				skipped++
+				current = legacyMistakeTOML{}
+				continue
+			}
+			current = legacyMistakeTOML{Time: written, FormClue: formClue, Verb: verb}
+		case strings.HasPrefix(trimmed, "Correct: "):
+			current.Correct = strings.TrimPrefix(trimmed, "Correct: ")
+		case strings.HasPrefix(trimmed, "Answer:"):
+			if current.Verb == "" {
+				skipped++
+				continue
+			}
+			current.Answer = strings.TrimSpace(strings.TrimPrefix(trimmed, "Answer:"))
+			mistakes = append(mistakes, current)
+			current = legacyMistakeTOML{}
+		}
+	}
+	if current.Verb != "" {
+		skipped++
+	}
+	return mistakes, skipped
+}
+
+// Mistakes go to the mistakes log, wrong answers to the statistics,
+// whose mistake counters already include them. The file is renamed
+// afterwards, so running the import again does not count them twice.
+func importLegacyMistakes(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logFatal("Failed to read legacy mistakes file", "path", path, "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(mistakesLoggingError)
+	}
+	written := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		written = info.ModTime()
+	}
+	imported, skipped := parseLegacyMistakes(data, written.Truncate(time.Second))
+	mistakes := readMistakes()
+	if mistakes == nil {
+		logFatal("Mistakes file can not be parsed, nothing imported", "path", mistakesPath)
+		fmt.Fprintf(os.Stderr, "Mistakes file %s can not be parsed, nothing imported\n", mistakesPath)
+		exit(mistakesLoggingError)
+	}
+	database := read_database()
+	statistics := database.loadStatistics()
+	changedAnswers := 0
+	for _, mistake := range imported {
+		prompt := prompt{FormClue: mistake.FormClue, Verb: mistake.Verb}
+		record := mistakes[prompt]
+		record.add(mistake.Correct, mistake.Answer, mistake.Time)
+		mistakes[prompt] = record
+		// Answers which are not wrong any more since the deck was edited are left out
+		if statistics.Answer(prompt) != mistake.Correct {
+			changedAnswers++
+			continue
+		}
+		statistics.CountWrongAnswer(prompt, strings.TrimSpace(mistake.Answer))
+	}
+	if readOnly {
+		fmt.Println("Read-only mode, nothing imported")
+		return
+	}
+	if err := saveMistakes(mistakes); err != nil {
+		logFatal("Failed to save imported mistakes", "path", mistakesPath, "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(mistakesLoggingError)
+	}
+	if err := statistics.save(); err != nil {
+		logFatal("Failed to save statistics with imported mistakes", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(statisticsError)
+	}
+	importedPath := path + ".imported"
+	if err := os.Rename(path, importedPath); err != nil {
+		slog.Error("Failed to rename imported legacy mistakes file", "path", path, "error", err)
+		fmt.Fprintf(os.Stderr, "Could not rename %s, remove it before importing again: %v\n", path, err)
+	}
+	slog.Info("Imported legacy mistakes", "path", path, "mistakes", len(imported), "skipped", skipped)
+	fmt.Printf("Imported %d mistakes into %s\n", len(imported), mistakesPath)
+	if changedAnswers > 0 {
+		fmt.Printf("Left %d wrong answers out of the statistics, the deck has another answer now\n", changedAnswers)
+	}
+	if skipped > 0 {
+		fmt.Printf("Skipped %d malformed entries\n", skipped)
+	}
+}
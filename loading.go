@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Shown while the deck and statistics are read in the background,
+// large decks take seconds to open
+type loadingScreen struct {
+	spinner spinner.Model
+}
+
+func newLoadingScreen() loadingScreen {
+	return loadingScreen{spinner: spinner.New(spinner.WithSpinner(spinner.Dot))}
+}
+
+// Rows of the deck read so far, updated while it is read
+// in the background and shown by the loading screen
+type loadProgress struct {
+	read  atomic.Int64
+	total atomic.Int64
+}
+
+var deckProgress loadProgress
+
+func (progress *loadProgress) update(read int, total int) {
+	progress.read.Store(int64(read))
+	progress.total.Store(int64(total))
+}
+
+func (progress *loadProgress) String() string {
+	read, total := progress.read.Load(), progress.total.Load()
+	switch {
+	case read == 0:
+		return ""
+	case total < read:
+		return fmt.Sprintf(", %d rows", read)
+	}
+	return fmt.Sprintf(", %d of %d rows", read, total)
+}
+
+// Failure to load which ends the program with its exit code,
+// reported once the terminal is restored
+type loadError struct {
+	code exitCode
+	err  error
+}
+
+func (err loadError) Error() string {
+	return err.err.Error()
+}
+
+type sessionLoadedMessage struct {
+	session *session
+	changes deckChanges
+}
+
+type loadFailedMessage struct {
+	err loadError
+}
+
+// Reads everything the quiz needs, the same way
+// the other commands do before they start
+func loadSession(composeLanguage string, frequencyList string, explicit map[string]bool) tea.Cmd {
+	return func() tea.Msg {
+		slog.Debug("Loading session")
+		database, err := readDatabase()
+		if err != nil {
+			return loadFailedMessage{loadError{databaseError, err}}
+		}
+		statistics, err := database.readStatistics()
+		if err != nil {
+			return loadFailedMessage{loadError{statisticsError, err}}
+		}
+		if err := useDeckSettings(database.Settings, explicit); err != nil {
+			return loadFailedMessage{loadError{databaseError, err}}
+		}
+		statistics.decayStaleStreaks(time.Now())
+		statistics.retireDeadRecords(time.Now())
+		history, err := readHistory()
+		if err != nil {
+			return loadFailedMessage{loadError{historyError, fmt.Errorf("could not parse history file %s: %w", historyPath, err)}}
+		}
+		if err := useComposeLanguage(composeLanguage, &statistics); err != nil {
+			return loadFailedMessage{loadError{usageError, err}}
+		}
+		if err := useFrequencyList(frequencyList, &statistics); err != nil {
+			return loadFailedMessage{loadError{usageError, err}}
+		}
+		statistics.useDeckCollation()
+		promptAudio = database.mediaFiles(database.Clip)
+		promptImages = database.mediaFiles(database.Image)
+		readNotesAndAchievements()
+		slog.Debug("Session loaded")
+		return sessionLoadedMessage{newSession(&statistics, &history), database.changes}
+	}
+}
+
+func (screen loadingScreen) Init() tea.Cmd {
+	return screen.spinner.Tick
+}
+
+func (screen loadingScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	screen.spinner, cmd = screen.spinner.Update(msg)
+	return screen, cmd
+}
+
+var loadingHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Quit}, action: "quit"},
+}
+
+func (screen loadingScreen) View() string {
+	screen.spinner.Style = background.Foreground(highlightColor)
+	body := lipgloss.JoinVertical(
+		lipgloss.Left,
+		statsTitleStyle.Render("Loading"),
+		"",
+		promptStatsEntryStyle.Width(boxWidth).Render(
+			screen.spinner.View()+" Reading "+filepath.Base(wordDatabasePath)+deckProgress.String(),
+		),
+	)
+	return renderBox(body, renderHelpRow(loadingHelp[:]))
+}
+
+// Unreadable files leave none, the quiz works without them
+func readNotesAndAchievements() {
+	if notes := readNotes(); notes != nil {
+		promptNotes = notes
+	}
+	if unlocked := readAchievements(); unlocked != nil {
+		unlockedAchievements = unlocked
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Set when data files must not be written,
+// e.g. because another instance is using them
+var readOnly = false
+
+// Kept open for the whole run, closing the file
+// (including by the garbage collector) releases the lock
+var instanceLock *os.File
+
+// Reports whether this is the only running instance,
+// the lock is released by the OS when the process exits
+func acquireInstanceLock() bool {
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0666)
+	if err != nil {
+		slog.Error("Failed to open lock file", "path", lockPath, "error", err)
+		return false
+	}
+	if err := lockFile(f); err != nil {
+		slog.Warn("Lock file is held by another instance", "path", lockPath, "error", err)
+		f.Close()
+		return false
+	}
+	// The PID is only informational, the lock itself is what matters
+	if err := f.Truncate(0); err == nil {
+		fmt.Fprintf(f, "%d\n", os.Getpid())
+	}
+	instanceLock = f
+	slog.Debug("Acquired instance lock", "path", lockPath)
+	return true
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Logged right before the program exits with an error code
+const levelFatal = slog.Level(12)
+
+func logFatal(msg string, args ...any) {
+	slog.Log(context.Background(), levelFatal, msg, args...)
+}
+
+func parseLogLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+}
+
+func replaceLevelName(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey && len(groups) == 0 {
+		if level, isLevel := attr.Value.Any().(slog.Level); isLevel && level >= levelFatal {
+			attr.Value = slog.StringValue("FATAL")
+		}
+	}
+	return attr
+}
+
+// Log is started anew once it exceeds this size
+const defaultLogMaxSize = 1 << 20
+
+// Number of previous logs kept as log.1, log.2, ...
+const defaultLogBackups = 3
+
+// Moves the log to log.1 shifting older ones, the oldest one
+// is dropped, so is the log itself when no backups are kept
+func rotateLog(path string, backups int) error {
+	for i := backups - 1; i >= 1; i-- {
+		err := os.Rename(backupPath(path, i), backupPath(path, i+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if backups == 0 {
+		return os.Remove(path)
+	}
+	return os.Rename(path, backupPath(path, 1))
+}
+
+// Rotation only happens on startup, a single session
+// never writes enough to be worth splitting
+func rotateLogIfNeeded(path string, maxSize int64, backups int) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if maxSize <= 0 || info.Size() < maxSize {
+		return nil
+	}
+	return rotateLog(path, backups)
+}
+
+type loggingOptions struct {
+	level   string
+	verbose bool
+	quiet   bool
+	maxSize int64
+	backups int
+}
+
+// Log file stays open until the process exits
+func setupLogging(options loggingOptions) {
+	if options.verbose && options.quiet {
+		fmt.Fprintln(os.Stderr, "Flags -verbose and -quiet can not be used together")
+		exit(loggingError)
+	}
+	level, err := parseLogLevel(options.level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(loggingError)
+	}
+	if options.verbose {
+		level = slog.LevelDebug
+	}
+	if options.backups < 0 {
+		fmt.Fprintln(os.Stderr, "Number of rotated logs can not be negative")
+		exit(loggingError)
+	}
+	var output io.Writer = io.Discard
+	if !readOnly && !options.quiet {
+		// Windows does not rename files open in another instance,
+		// which then just keeps appending to the old log
+		if err := rotateLogIfNeeded(logPath, options.maxSize, options.backups); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to rotate log file: %v\n", err)
+		}
+		f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open log file: %v\n", err)
+			exit(loggingError)
+		}
+		output = f
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceLevelName,
+	})))
+}
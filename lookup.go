@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const wiktionaryURL = "https://en.wiktionary.org/wiki/"
+
+// Entry of the verb, scrolled to the section of the
+// deck language when that one is known
+func wiktionaryEntry(verb string) string {
+	entry := wiktionaryURL + url.PathEscape(strings.ReplaceAll(strings.TrimSpace(verb), " ", "_"))
+	if deckLanguage != "" {
+		entry += "#" + strings.ToUpper(deckLanguage[:1]) + deckLanguage[1:]
+	}
+	return entry
+}
+
+// BROWSER is honoured like other terminal programs do,
+// otherwise the platform opener starts the default browser
+func openBrowser(address string) tea.Cmd {
+	return func() tea.Msg {
+		var command *exec.Cmd
+		if browser := strings.Fields(os.Getenv("BROWSER")); len(browser) > 0 {
+			command = exec.Command(browser[0], append(browser[1:], address)...)
+		} else {
+			command = browserCommand(address)
+		}
+		slog.Info("Opening in browser", "address", address, "browser", command.Path)
+		if err := command.Run(); err != nil {
+			return commandFailedMessage{"lookup", fmt.Errorf("could not open %s: %w", address, err)}
+		}
+		return nil
+	}
+}
+
+func (screen quizScreen) lookUpVerb() tea.Cmd {
+	notify("opening dictionary")
+	return openBrowser(wiktionaryEntry(screen.question.prompt.Verb))
+}
@@ -1,20 +1,29 @@
+// Gem2 drills verb conjugations from a word table in the terminal.
+//
+// Only the engine is importable by other programs: package deck reads
+// the word table, package stats keeps the answers and the statistics
+// file, package scheduler picks questions and works out reviews. The
+// terminal UI and the command line stay in this package, as they share
+// the process-wide display, key map and path settings, so the quiz
+// screens can neither be embedded nor tested apart from it.
 package main
 
 import (
-	"errors"
 	"fmt"
-	"io/fs"
-	"log"
-	"math/rand"
+	"log/slog"
 	"os"
+	"slices"
 	"strconv"
-	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	textinput "github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	lipgloss "github.com/charmbracelet/lipgloss"
-	toml "github.com/pelletier/go-toml/v2"
-	excelize "github.com/xuri/excelize/v2"
+
+	"github.com/kligunov-id/gem2/deck"
+	"github.com/kligunov-id/gem2/scheduler"
+	"github.com/kligunov-id/gem2/stats"
 )
 
 type exitCode int
@@ -31,269 +40,120 @@ const (
 	internalError        exitCode = 4
 	mistakesLoggingError exitCode = 5
 	statisticsError      exitCode = 6
+	historyError         exitCode = 7
+	lockError            exitCode = 8
+	profileError         exitCode = 9
+	exportError          exitCode = 10
+	usageError           exitCode = 11
+	validationError      exitCode = 12
+	nothingDue           exitCode = 13 // Not an error, see "gem2 due"
+	serverError          exitCode = 14
+	syncError            exitCode = 15
+	backupError          exitCode = 16
+	assignmentError      exitCode = 17
+	notificationError    exitCode = 18
+	downloadError        exitCode = 19
 )
 
 func exit(code exitCode) {
 	os.Exit(int(code))
 }
 
-const (
+// Overridden by -deck and -log, see pathOptions
+var (
 	wordDatabasePath = "words.xlsx"
 	logPath          = "log"
-	mistakesPath     = "mistakes"
+)
+
+// Per-user files, moved into the state directory by
+// useStandardDirectories and then into the profile one by useProfile
+var (
+	mistakesPath     = "mistakes.toml"
 	statisticsPath   = "statistics.toml"
+	historyPath      = "history.toml"
+	notesPath        = "notes.toml"
+	achievementsPath = "achievements.toml"
+	lockPath         = "gem2.lock"
 )
 
 type wordDatabase struct {
-	formClue  []string
-	verbs     []string
-	verbForms [][]string
+	deck.Deck
+	// Since the deck was last read, empty when that is not known
+	changes deckChanges
 }
 
 func read_database() wordDatabase {
-	table, err := excelize.OpenFile(wordDatabasePath)
+	database, err := readDatabase()
 	if err != nil {
-		log.Printf("[FATAL] %v\n", err)
+		logFatal("Failed to read word database", "path", wordDatabasePath, "error", err)
 		exit(databaseError)
 	}
-	defer func() {
-		if err := table.Close(); err != nil {
-			log.Printf("[FATAL] %v\n", err)
-			exit(databaseError)
-		}
-	}()
+	return database
+}
 
-	sheets := table.GetSheetList()
-	dataSheet := sheets[0]
-	rows, err := table.GetRows(dataSheet)
-	if err != nil {
-		log.Printf("[FATAL] %v\n", err)
-		exit(databaseError)
-	}
-	if len(rows) < 2 {
-		log.Println("[FATAL] Table containts less than 2 lines!")
-		exit(databaseError)
+func readDatabase() (wordDatabase, error) {
+	cached, fresh, previous := readCachedDeck(wordDatabasePath)
+	if fresh {
+		return wordDatabase{Deck: cached}, nil
 	}
-	var pronouns []string
-	verbs := make([]string, len(rows)-1)
-	verbForms := make([][]string, len(rows)-1)
-	for row_index, row := range rows {
-		if row_index == 0 {
-			pronouns = make([]string, len(row)-2)
-			copy(pronouns, row[2:])
-			continue
-		}
-		verbForms[row_index-1] = make([]string, len(row)-2)
-		for col_index, cell := range row {
-			if col_index == 1 {
-				verbs[row_index-1] = cell
-			}
-			if col_index >= 2 {
-				verbForms[row_index-1][col_index-2] = cell
-			}
-		}
+	database, err := deck.ReadWithProgress(wordDatabasePath, deckProgress.update)
+	if err != nil {
+		return wordDatabase{}, fmt.Errorf("could not read word database %s: %w", wordDatabasePath, err)
 	}
-	return wordDatabase{
-		pronouns,
-		verbs,
-		verbForms,
+	cacheDeck(wordDatabasePath, database)
+	var changes deckChanges
+	if previous != nil {
+		changes = compareDecks(*previous, database)
 	}
+	return wordDatabase{database, changes}, nil
 }
 
-type questionStats struct {
-	streak   uint16
-	correct  uint16
-	mistakes uint16
-}
+type prompt = deck.Prompt
 
-func (stats questionStats) probWeight() float32 {
-	return 1 / (1 + float32(stats.streak))
-}
+type questionStats = stats.Record
 
 type statisticsDatabase struct {
-	statistics      map[prompt]questionStats
-	answers         map[prompt]string
-	totalProbWeight float32
-	// These are fields present in file
-	// yet not existing in word database
-	deadRecords map[string]promptDataTOML
-}
-
-const statisticsPromptSeparator = "+"
-
-func (statistics statisticsDatabase) sortPromptsArbitraryOrder() []prompt {
-	orderedPromptList := make([]prompt, len(statistics.statistics))
-	i := 0
-	for prompt := range statistics.statistics {
-		orderedPromptList[i] = prompt
-		i++
-	}
-	return orderedPromptList
-}
-
-func (prompt prompt) encode() string {
-	return fmt.Sprintf("%s%s%s", prompt.formClue, statisticsPromptSeparator, prompt.verb)
-}
-
-func decodePrompt(encodedPrompt string) prompt {
-	prompt_tokens := strings.Split(encodedPrompt, statisticsPromptSeparator)
-	if len(prompt_tokens) != 2 {
-		log.Printf("[FATAL] Invalid key \"%s\" in statistics file\n", encodedPrompt)
-		exit(statisticsError)
-	}
-	return prompt{prompt_tokens[0], prompt_tokens[1]}
-}
-
-type promptDataTOML struct {
-	Streak   uint16
-	Correct  uint16
-	Mistakes uint16
-	Answer   string
+	stats.Database
 }
 
-type statisticsDatabaseTOML struct {
-	Statistics map[string]promptDataTOML
+// Copied, so the list a screen keeps is its own
+func (statistics statisticsDatabase) alphabeticalPrompts() []prompt {
+	prompts := slices.Clone(statistics.Prompts())
+	sortPromptsAlphabetically(prompts)
+	return prompts
 }
 
-func (statistics statisticsDatabase) expand(statisticsTOML statisticsDatabaseTOML) {
-	log.Println("[INFO] Updating statistics with content from file...")
-	resetRecordsCount := 0
-	for encodedPrompt, data := range statisticsTOML.Statistics {
-		prompt := decodePrompt(encodedPrompt)
-		_, exists := statistics.statistics[prompt]
-		if !exists {
-			statistics.deadRecords[encodedPrompt] = data
-			continue
-		}
-		if data.Answer != statistics.answers[prompt] {
-			resetRecordsCount++
-			continue
-		}
-		stats := questionStats{data.Streak, data.Correct, data.Mistakes}
-		statistics.updateStats(prompt, stats)
-	}
-	if len(statistics.deadRecords) > 0 {
-		log.Printf(
-			"[INFO] %d questions no longer exist, ignoring statistics for them\n",
-			len(statistics.deadRecords),
-		)
-	}
-	if resetRecordsCount > 0 {
-		log.Printf(
-			"[WARNING] %d questions have their answer changed, resetting statistics for them\n",
-			resetRecordsCount,
-		)
-	}
-}
-
-func (statisticsDatabase statisticsDatabase) pack() statisticsDatabaseTOML {
-	statistics := statisticsDatabase.deadRecords
-	for prompt, stats := range statisticsDatabase.statistics {
-		if stats.correct == 0 && stats.mistakes == 0 {
-			continue
-		}
-		statistics[prompt.encode()] = promptDataTOML{
-			stats.streak,
-			stats.correct,
-			stats.mistakes,
-			statisticsDatabase.answers[prompt],
-		}
-	}
-	return statisticsDatabaseTOML{statistics}
-}
-
-func (screen quizScreen) saveStatistics() {
-	bytes, err := toml.Marshal(screen.statistics.pack())
-	if err != nil {
-		log.Printf("[FATAL] Unachievable TOML encoding error\n")
-		exit(internalError)
-	}
-	err = os.WriteFile(statisticsPath, bytes, 0666)
-	if err != nil {
-		log.Printf("[FATAL] Could not write to statistics.toml\n")
-		exit(statisticsError)
+// Failing to write is not fatal, statistics stay in memory
+// and the caller decides how to proceed
+func (statistics *statisticsDatabase) save() error {
+	if readOnly {
+		slog.Info("Read-only mode, statistics not saved")
+		return nil
 	}
-	log.Println("[INFO] Statistics saved")
-}
-
-func (statistics statisticsDatabase) updateStats(
-	prompt prompt,
-	newStats questionStats,
-) {
-	statistics.totalProbWeight -= statistics.statistics[prompt].probWeight()
-	statistics.statistics[prompt] = newStats
-	statistics.totalProbWeight += statistics.statistics[prompt].probWeight()
-}
-
-func (statistics statisticsDatabase) endStreak(prompt prompt) {
-	oldStats := statistics.statistics[prompt]
-	statistics.updateStats(
-		prompt,
-		questionStats{streak: 0, correct: oldStats.correct, mistakes: oldStats.mistakes + 1},
-	)
-}
-
-func (statistics statisticsDatabase) continueStreak(prompt prompt) {
-	oldStats := statistics.statistics[prompt]
-	statistics.updateStats(
-		prompt,
-		questionStats{streak: oldStats.streak + 1, correct: oldStats.correct + 1, mistakes: oldStats.mistakes},
-	)
+	return store.saveStatistics(statistics)
 }
 
 func (database wordDatabase) emptyStatistics() statisticsDatabase {
-	log.Printf("[INFO] Initializing statistics...\n")
-	statistics := make(map[prompt]questionStats)
-	answers := make(map[prompt]string)
-	var totalProbWeight float32 = 0
-	missing_fields_counter := 0
-	for verbIndex, verb := range database.verbs {
-		for clueIndex, clue := range database.formClue {
-			if len(database.verbForms[verbIndex]) <= clueIndex ||
-				database.verbForms[verbIndex][clueIndex] == "" {
-				missing_fields_counter++
-				continue
-			}
-			answer := database.verbForms[verbIndex][clueIndex]
-			statistics[prompt{clue, verb}] = questionStats{}
-			answers[prompt{clue, verb}] = answer
-			totalProbWeight++
-		}
+	slog.Debug("Initializing statistics")
+	questions, missing := database.Questions()
+	if missing > 0 {
+		slog.Warn("Missing database fields", "count", missing)
 	}
-	if missing_fields_counter > 0 {
-		log.Printf(
-			"[WARNING] %d missing database fields\n",
-			missing_fields_counter,
-		)
-	}
-	return statisticsDatabase{statistics, answers, totalProbWeight, map[string]promptDataTOML{}}
+	return statisticsDatabase{stats.New(questions)}
 }
 
 func (database wordDatabase) loadStatistics() statisticsDatabase {
-	statistics := database.emptyStatistics()
-	log.Printf("[INFO] Trying to read statistics file...")
-	bytes, err := os.ReadFile(statisticsPath)
+	statistics, err := database.readStatistics()
 	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			log.Println("[INFO] Statistics file not found")
-		} else {
-			log.Println("[ERROR] Failed to read statistics file")
-		}
-	} else {
-		var statisticsTOML statisticsDatabaseTOML
-		err = toml.Unmarshal(bytes, &statisticsTOML)
-		if err != nil {
-			log.Printf("[FATAL] Failed to parse TOML statistics file:\n %s \n", err)
-			exit(statisticsError)
-		}
-		statistics.expand(statisticsTOML)
+		logFatal("Failed to load statistics", "path", statisticsPath, "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(statisticsError)
 	}
 	return statistics
 }
 
-type prompt struct {
-	formClue string
-	verb     string
+func (database wordDatabase) readStatistics() (statisticsDatabase, error) {
+	return store.loadStatistics(database)
 }
 
 type question struct {
@@ -302,15 +162,13 @@ type question struct {
 }
 
 func (statistics statisticsDatabase) getRandomQuestion() question {
-	random_float_index := rand.Float32() * statistics.totalProbWeight
-	for prompt, questionStats := range statistics.statistics {
-		random_float_index -= questionStats.probWeight()
-		if random_float_index <= 0 {
-			return question{prompt, statistics.answers[prompt]}
-		}
-	}
-	log.Print("[WARNING] Random question selection floating arithmetic problem, recalculating...")
-	return statistics.getRandomQuestion()
+	prompt := scheduler.RandomPrompt(statistics.Database)
+	return question{prompt, statistics.Answer(prompt)}
+}
+
+func (statistics statisticsDatabase) getNextQuestion() question {
+	prompt := statistics.nextPrompt()
+	return question{prompt, statistics.Answer(prompt)}
 }
 
 type mode int
@@ -321,54 +179,123 @@ const (
 )
 
 type model struct {
-	screen        tea.Model
+	// Opened on top of each other, the last one is shown
+	screens []tea.Model
+	// Shared with every screen showing the quiz,
+	// empty until load has finished
+	session       *session
+	load          tea.Cmd
+	failure       *loadError
 	isInAltscreen bool
 	height        int
 	width         int
+	// Screen the user was on last that a run may start on
+	lastScreen string
 }
 
 type quizScreen struct {
-	statistics     *statisticsDatabase
-	mode           mode
-	question       question
-	inputField     textinput.Model
-	wrongAnswers   uint16
-	correctAnswers uint16
-	streak         uint16
+	*session
+	mode     mode
+	question question
+	// Session time the question was shown at
+	questionShown time.Duration
+	inputField    textinput.Model
+	// Set when the last answer broke a record
+	sessionRecord bool
+	promptRecord  bool
+	// Prompts to be asked before any random ones
+	replayQueue []prompt
+	// Prompts pinned for this session, asked after any replay
+	pinnedQueue []prompt
+	// Easy prompts the session starts with, asked after any replay
+	warmUpQueue []prompt
+	picker      characterPicker
+	// Set when the time limit ran out before an answer was submitted
+	timedOut bool
+	// Set when the question is asked again from the again pile
+	againQuestion bool
+	// Set when the question was pinned for this session
+	pinnedQuestion bool
+	// Set when the last answer cleared the again pile
+	pileCleared bool
+	// Rest of the run from easy to hard in ladder order
+	ladder []prompt
+	// Answers to pick from in multiple-choice mode, one of them correct
+	choices []string
+	// Keystrokes of the answer being typed, and session time of the first
+	typing        typingStats
+	typingStarted time.Duration
 }
 
 type statisticsScreen struct {
-	previousScreen    *quizScreen
 	statistics        *statisticsDatabase
 	orderedPromptList []prompt
-	firstShownIndex   int
-	selectedRow       int
+	listPosition
+	listNavigation
 }
 
-func initialModel() model {
-	database := read_database()
-	statistics := database.loadStatistics()
-	question := statistics.getRandomQuestion()
+func newQuizScreen(session *session) quizScreen {
+	question := session.statistics.getNextQuestion()
 	inputField := textinput.New()
 	inputField.Focus()
 	inputField.Prompt = ""
-	inputField.Width = 15
-	inputField.CharLimit = 30
+	inputField.CharLimit = question.charLimit()
+	inputField.KeyMap.Paste.SetEnabled(false)
+	return quizScreen{
+		session:       session,
+		question:      question,
+		questionShown: session.clock.elapsed(),
+		inputField:    inputField,
+		mode:          input,
+		choices:       session.statistics.choicesFor(question),
+	}
+}
+
+// Starts on the loading screen, restoreMode is
+// the screen to open once the session is loaded
+func initialModel(load tea.Cmd, restoreMode string) model {
+	lastScreen := "home"
+	if isRestorableScreen(restoreMode) {
+		lastScreen = restoreMode
+	}
 	return model{
-		screen: quizScreen{
-			statistics:     &statistics,
-			question:       question,
-			inputField:     inputField,
-			mode:           input,
-			wrongAnswers:   0,
-			correctAnswers: 0,
-		},
+		screens:       []tea.Model{newLoadingScreen()},
+		session:       &session{},
+		load:          load,
 		isInAltscreen: true,
+		lastScreen:    lastScreen,
+	}
+}
+
+// Screens need the session, so they are only opened once it is loaded
+func (m model) start(loaded *session, changes deckChanges) (model, tea.Cmd) {
+	*m.session = *loaded
+	quiz := newQuizScreen(m.session)
+	quiz.startWarmUp()
+	home := homeScreen{quiz: &quiz}
+	m.screens = []tea.Model{home}
+	if len(m.session.statistics.ChangedAnswers) > 0 {
+		m.screens = []tea.Model{newReconciliationScreen(home, m.session.statistics)}
+	}
+	if !changes.isEmpty() {
+		m.screens = []tea.Model{deckChangesScreen{changes, m.screens[0]}}
+	}
+	restoreMode := m.lastScreen
+	m.lastScreen = "home"
+	m = m.restoreScreen(restoreMode)
+	return m, m.top().Init()
+}
+
+func newStatisticsScreen(statistics *statisticsDatabase) statisticsScreen {
+	return statisticsScreen{
+		statistics:        statistics,
+		orderedPromptList: statistics.alphabeticalPrompts(),
+		listPosition:      listPosition{firstShownIndex: 0, selectedRow: 0},
 	}
 }
 
 func (screen quizScreen) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, screen.questionTimer())
 }
 
 func (screen statisticsScreen) Init() tea.Cmd {
@@ -376,59 +303,107 @@ func (screen statisticsScreen) Init() tea.Cmd {
 }
 
 func (m model) Init() tea.Cmd {
-	return m.screen.Init()
+	return tea.Batch(m.top().Init(), tea.SetWindowTitle(windowTitle(0, 0)), m.load)
 }
 
 func exitNonExistingMode() {
-	log.Println("[FATAL] Screen is in a non-existing mode")
+	logFatal("Screen is in a non-existing mode")
 	exit(internalError)
 }
 
-type ExitScreenMessage struct{}
-type ScreenExitedMessage struct{}
-
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	if updated, isModel := updated.(model); isModel {
+		updated.keepSessionTime()
+	}
+	return updated, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg.(type) {
+	case tea.KeyMsg, tea.MouseMsg:
+		if mode := screenMode(m.top()); isRestorableScreen(mode) {
+			m.lastScreen = mode
+		}
+	}
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.height = msg.Height
 		m.width = msg.Width
+		resizeBox(m.width, m.height)
 		return m, nil
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "esc":
-			log.Println("[INFO] Quitting...")
-			return m, func() tea.Msg { return ExitScreenMessage{} }
-		case "ctrl+a":
+		switch {
+		case key.Matches(msg, keys.Quit) && m.needsQuitConfirmation():
+			return m, m.push(quitConfirmScreen{m.session})
+		case msg.Type == tea.KeyCtrlC || key.Matches(msg, keys.Quit):
+			slog.Info("Quitting")
+			return m, requestQuit
+		case key.Matches(msg, keys.AltScreen):
 			return m.toggleAltScreen()
+		case key.Matches(msg, keys.Help) && !typedAsText(m.top(), msg):
+			m.toggleKeyHelp()
+			return m, nil
 		}
-	case ScreenExitedMessage:
+	case tea.MouseMsg:
+		msg = m.boxCoordinates(msg)
+		screen, cmd := m.top().Update(msg)
+		m.setTop(screen)
+		return m, tea.Batch(cmd, expireToastLater())
+	case pushScreenMessage, popScreenMessage, replaceScreenMessage, resetScreensMessage:
+		return m.updateStack(msg)
+	case quitMessage:
+		var cmd tea.Cmd
+		m, cmd = m.teardown()
+		return m, tea.Batch(cmd, expireToastLater())
+	case flashEndedMessage:
+		endFlash(msg)
+		return m, nil
+	case questionTimerMessage:
+		return m.updateQuestionTimer(msg)
+	case animationFrameMessage:
+		return m, advanceAnimation(msg)
+	case toastExpiredMessage:
+		expireToast(msg)
+		return m, nil
+	case commandFailedMessage:
+		slog.Error("External command failed", "action", msg.action, "error", msg.err)
+		notify(msg.action + " failed")
+		return m, expireToastLater()
+	case sessionLoadedMessage:
+		return m.start(msg.session, msg.changes)
+	case loadFailedMessage:
+		m.failure = &msg.err
 		return m, tea.Quit
 	}
-	var cmd tea.Cmd
-	m.screen, cmd = m.screen.Update(msg)
-	return m, cmd
+	screen, cmd := m.top().Update(msg)
+	m.setTop(screen)
+	return m, tea.Batch(cmd, expireToastLater())
 }
 
 func (screen quizScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case questionTimerMessage:
+		return screen.timerUpdate(msg)
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+s":
-			screen.saveStatistics()
-			return statisticsScreen{
-				previousScreen:    &screen,
-				statistics:        screen.statistics,
-				orderedPromptList: screen.statistics.sortPromptsArbitraryOrder(),
-				firstShownIndex:   0,
-				selectedRow:       0,
-			}, nil
+		switch {
+		case key.Matches(msg, keys.Stats):
+			return screen.saveAndOpen(newStatisticsScreen(screen.statistics))
+		case key.Matches(msg, keys.Dashboard):
+			return screen.saveAndOpen(dashboardScreen{statistics: screen.statistics, history: screen.history})
+		case key.Matches(msg, keys.Menu):
+			return screen.saveAndOpen(menuScreen{quiz: &screen})
+		case key.Matches(msg, keys.Queue):
+			return screen, pushScreen(newUpNextScreen(screen))
+		case key.Matches(msg, keys.PlayAudio):
+			return screen, screen.playAudio()
 		}
-	case ExitScreenMessage:
-		screen.saveStatistics()
-		return screen, func() tea.Msg { return ScreenExitedMessage{} }
 	}
 	switch screen.mode {
 	case input:
+		if screen.picker.open {
+			return screen.pickerUpdate(msg)
+		}
 		return screen.inputUpdate(msg)
 	case validation:
 		return screen.validateUpdate(msg)
@@ -440,86 +415,194 @@ func (screen quizScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (screen statisticsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
-	case ExitScreenMessage:
-		return screen, func() tea.Msg { return ScreenExitedMessage{} }
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+s", "backspace":
-			return screen.previousScreen, nil
-		case "j", "down":
-			screen.scrollDown()
+		switch {
+		case key.Matches(msg, keys.Stats, keys.Back):
+			return screen, popScreen
+		case key.Matches(msg, keys.Conjugation):
+			if index := screen.selectedIndex(); index < len(screen.orderedPromptList) {
+				return screen, pushScreen(newConjugationScreen(screen.statistics, screen.orderedPromptList[index].Verb))
+			}
 			return screen, nil
-		case "k", "up":
-			screen.scrollUp()
+		case key.Matches(msg, keys.Pin):
+			if index := screen.selectedIndex(); index < len(screen.orderedPromptList) {
+				screen.statistics.togglePin(screen.orderedPromptList[index])
+			}
 			return screen, nil
 		}
+		screen.handleKey(msg, &screen.listPosition, len(screen.orderedPromptList), listShownRows())
+		return screen, nil
+	case tea.MouseMsg:
+		screen.listPosition.handleMouse(msg, len(screen.orderedPromptList), listShownRows())
+		return screen, nil
 	}
 	return screen, nil
 }
 
-func (screen quizScreen) logMistake() {
-	f, err := os.OpenFile(mistakesPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	defer f.Close()
-	if err != nil {
-		log.Println("[ERROR] Failed to log mistake")
-		return
+// Updates counters, statistics and history
+// with the answer typed into the input field
+func (screen *quizScreen) submitAnswer() {
+	screen.unsavedAnswers++
+	screen.recordTyping()
+	screen.journalAnswer(screen.isAnswerCorrect())
+	if screen.isAnswerCorrect() {
+		record := screen.statistics.Record(screen.question.prompt)
+		screen.correctAnswers++
+		screen.streak++
+		screen.promptRecord = screen.statistics.ContinueStreak(screen.question.prompt)
+		screen.sessionRecord = screen.statistics.RecordSessionStreak(screen.streak)
+		if screen.sessionRecord || screen.promptRecord {
+			slog.Info("New streak record", "session", screen.sessionRecord, "question", screen.promptRecord)
+			notify("new record!")
+		}
+		screen.history.recordAnswer(true, screen.questionTime())
+		screen.pileCleared = screen.clearAgain(screen.question.prompt)
+		screen.awardExperience(record)
+		slog.Debug(
+			"Answer is correct",
+			"prompt", screen.question.prompt,
+			"weight", screen.statistics.Record(screen.question.prompt).Weight(),
+		)
+	} else {
+		screen.logMistake()
+		rememberMistake(screen.question, screen.inputField.Value())
+		screen.sessionRecord = false
+		screen.promptRecord = false
+		screen.streak = 0
+		screen.wrongAnswers++
+		screen.statistics.EndStreak(screen.question.prompt, screen.inputField.Value())
+		screen.history.recordAnswer(false, screen.questionTime())
+		screen.pileAgain(screen.question.prompt)
+		slog.Debug(
+			"Answer is wrong",
+			"prompt", screen.question.prompt,
+			"weight", screen.statistics.Record(screen.question.prompt).Weight(),
+		)
 	}
-	f.WriteString(
-		fmt.Sprintf(
-			"Question %s + %s:\n    Correct: %s\n    Answer: %s\n\n",
-			screen.question.prompt.formClue,
-			screen.question.prompt.verb,
-			screen.question.correctAnswer,
-			screen.inputField.Value(),
-		),
+	screen.statistics.RecordResponseTime(screen.question.prompt, min(screen.questionTime(), maxCountedAnswerTime))
+	screen.unpinAnswered()
+	screen.unlockAchievements()
+}
+
+func (screen quizScreen) answer() (tea.Model, tea.Cmd) {
+	screen.submitAnswer()
+	screen.autosave()
+	screen.inputField.Blur() // Removes focus
+	screen.mode = validation
+	correct := screen.isAnswerCorrect()
+	return screen, tea.Batch(
+		signalAnswer(correct),
+		startAnimation(correct),
+		screen.updateWindowTitle(),
+		screen.runRecordBrokenHook(),
 	)
-	log.Println("[INFO] Logged mistake")
 }
 
 func (screen quizScreen) inputUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
-			if screen.isAnswerCorrect() {
-				screen.correctAnswers++
-				screen.streak++
-				screen.statistics.continueStreak(screen.question.prompt)
-				log.Printf(
-					"[INFO] Answer is correct, new score is %.2f\n",
-					screen.statistics.statistics[screen.question.prompt].probWeight(),
-				)
-			} else {
-				screen.logMistake()
-				screen.streak = 0
-				screen.wrongAnswers++
-				screen.statistics.endStreak(screen.question.prompt)
-				log.Printf(
-					"[INFO] Answer is wrong, new score is %.2f\n",
-					screen.statistics.statistics[screen.question.prompt].probWeight(),
-				)
-			}
-			screen.inputField.Blur() // Removes focus
-			screen.mode = validation
+		switch {
+		case key.Matches(msg, keys.Picker):
+			screen.togglePicker()
 			return screen, nil
+		case key.Matches(msg, keys.Submit):
+			return screen.answer()
+		}
+		if model, cmd, chosen := screen.chooseAnswer(msg); chosen {
+			return model, cmd
 		}
 	}
+	if isPaste(msg) {
+		rejectPaste()
+		return screen, nil
+	}
+	if msg, isKey := msg.(tea.KeyMsg); isKey {
+		screen.countKeystroke(msg)
+	}
 	var cmd tea.Cmd
-	screen.inputField, cmd = screen.inputField.Update(msg)
+	// Scrolling of long answers is worked out while updating
+	screen.inputField.Width = inputFieldWidth()
+	screen.inputField, cmd = screen.inputField.Update(screen.question.orientArrows(msg))
+	if msg, isKey := msg.(tea.KeyMsg); isKey && msg.Type == tea.KeyRunes {
+		screen.composeBeforeCursor()
+		screen.completeAccentBeforeCursor()
+	}
+	screen.composeCombiningMarks()
 	return screen, cmd
 }
 
+// Questions queued for replay are asked first, then the pinned ones and
+// the warm-up, weighted random selection resumes afterwards
+func (screen *quizScreen) nextQuestion() {
+	screen.againQuestion = false
+	screen.pinnedQuestion = false
+	if len(screen.replayQueue) > 0 {
+		prompt := screen.replayQueue[0]
+		screen.replayQueue = screen.replayQueue[1:]
+		screen.question = question{prompt, screen.statistics.Answer(prompt)}
+	} else if len(screen.pinnedQueue) > 0 {
+		prompt := screen.pinnedQueue[0]
+		screen.pinnedQueue = screen.pinnedQueue[1:]
+		screen.question = question{prompt, screen.statistics.Answer(prompt)}
+		screen.pinnedQuestion = true
+	} else if prompt, due := screen.nextAgain(screen.reachedSessionLength()); due {
+		screen.question = question{prompt, screen.statistics.Answer(prompt)}
+		screen.againQuestion = true
+	} else if len(screen.warmUpQueue) > 0 {
+		prompt := screen.warmUpQueue[0]
+		screen.warmUpQueue = screen.warmUpQueue[1:]
+		screen.question = question{prompt, screen.statistics.Answer(prompt)}
+	} else {
+		// Emptied rather than just empty, the warm-up is over
+		screen.warmUpQueue = nil
+		if nextQuestionOrder == orderLadder {
+			screen.question = screen.nextRung()
+		} else {
+			screen.question = screen.statistics.getNextQuestion()
+		}
+	}
+	screen.choices = screen.statistics.choicesFor(screen.question)
+	screen.inputField.CharLimit = screen.question.charLimit()
+	screen.typing = typingStats{}
+	screen.questionShown = screen.clock.elapsed()
+	screen.timedOut = false
+}
+
 func (screen quizScreen) validateUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
-			log.Println("[INFO] New question requested")
-			screen.question = screen.statistics.getRandomQuestion()
+		switch {
+		case key.Matches(msg, keys.Lookup):
+			return screen, screen.lookUpVerb()
+		case key.Matches(msg, keys.Copy):
+			return screen, screen.copyAnswer()
+		case key.Matches(msg, keys.CopyAll):
+			return screen, screen.copyQuestion()
+		case key.Matches(msg, keys.Conjugation):
+			return screen, pushScreen(newConjugationScreen(screen.statistics, screen.question.prompt.Verb))
+		case key.Matches(msg, keys.Note):
+			return screen, pushScreen(newNoteScreen(screen.question.prompt))
+		case key.Matches(msg, keys.Pin):
+			screen.statistics.togglePin(screen.question.prompt)
+			return screen, nil
+		case key.Matches(msg, keys.Submit):
+			if screen.pileCleared {
+				// Comes back to this validation, the next enter goes on
+				screen.pileCleared = false
+				summary := againSummaryScreen{screen.againCleared}
+				screen.againCleared = nil
+				return screen, pushScreen(summary)
+			}
+			if screen.isSessionComplete() {
+				slog.Info("Session length reached", "answers", sessionLength)
+				return screen, requestQuit
+			}
+			slog.Debug("New question requested")
+			screen.nextQuestion()
 			screen.inputField.Reset()
 			screen.inputField.Focus() // Removes focus
 			screen.mode = input
-			return screen, textinput.Blink
+			return screen, tea.Batch(textinput.Blink, screen.questionTimer())
 		}
 	}
 	var cmd tea.Cmd
@@ -536,79 +619,134 @@ func (m *model) toggleAltScreen() (*model, tea.Cmd) {
 	}
 }
 
+func (screen quizScreen) isTyping() bool {
+	return screen.mode == input && !screen.picker.open
+}
+
 func (screen quizScreen) isAnswerCorrect() bool {
-	return strings.TrimSpace(screen.question.correctAnswer) == strings.TrimSpace(screen.inputField.Value())
+	return !screen.timedOut && answerChecking.accepts(screen.question.correctAnswer, screen.inputField.Value())
 }
 
 func (screen quizScreen) renderValidationRow() string {
+	style, text := screen.validationMessage()
+	return style.Render(text)
+}
+
+// Style is centered in the box
+func (screen quizScreen) validationMessage() (lipgloss.Style, string) {
+	if screen.timedOut {
+		return wrongAnswerStyle, italic("Time is up!") + " Correct answer is: " + bold(isolate(screen.question.correctAnswer))
+	}
 	if screen.isAnswerCorrect() {
-		return correctAnswerStyle.Italic(true).Render("Correct!")
-	} else {
-		return wrongAnswerStyle.Render(
-			italic("Wrong!") + " Correct answer is: " + bold(screen.question.correctAnswer),
+		return correctAnswerStyle.Italic(true), "Correct!"
+	}
+	correct := markMissingWords(screen.question.correctAnswer, screen.inputField.Value())
+	return wrongAnswerStyle, italic("Wrong!") + " Correct answer is: " + bold(isolate(correct))
+}
+
+func renderReadOnlyRow() string {
+	if !readOnly {
+		return ""
+	}
+	return wrongAnswerStyle.Italic(true).Render("read-only, progress is not saved")
+}
+
+func (screen quizScreen) renderRecordRow() string {
+	switch {
+	case screen.sessionRecord:
+		return recordStyle.Render("New best streak: " + bold(strconv.Itoa(int(screen.streak))) + "!")
+	case screen.promptRecord:
+		return recordStyle.Render(
+			"New best streak for this question: " +
+				bold(strconv.Itoa(int(screen.statistics.Record(screen.question.prompt).Streak))) + "!",
 		)
 	}
+	return ""
 }
 
+// Colors by their role, set from the current theme by applyTheme
 var (
-	lightPink1    = lipgloss.ANSIColor(217)
-	lightPink3    = lipgloss.ANSIColor(174)
-	lightPink4    = lipgloss.ANSIColor(95)
-	darkSeaGreen1 = lipgloss.ANSIColor(193)
-	darkSeaGreen4 = lipgloss.ANSIColor(65)
-	darkSeaGreen2 = lipgloss.ANSIColor(157)
-	darkOrange    = lipgloss.ANSIColor(208)
-	darkOrange3   = lipgloss.ANSIColor(166)
-	salmon1       = lipgloss.ANSIColor(209)
-	lightSalmon3  = lipgloss.ANSIColor(173)
-	wheat4        = lipgloss.ANSIColor(101)
-	// Not using ANSI here since first 16 ones could be redefined
-	black = lipgloss.Color("#000000")
+	textColor       lipgloss.TerminalColor
+	highlightColor  lipgloss.TerminalColor
+	mutedColor      lipgloss.TerminalColor
+	accentColor     lipgloss.TerminalColor
+	wrongColor      lipgloss.TerminalColor
+	recordColor     lipgloss.TerminalColor
+	backgroundColor lipgloss.TerminalColor
+	heatmapColors   []lipgloss.TerminalColor
 )
 
 const (
-	boxWidth          = 45
-	boxHeight         = 12
 	horizontalPadding = 3
 	verticalPadding   = 1
-	totalBoxWidth     = boxWidth + 2*horizontalPadding
-	totalBoxHeight    = boxHeight + 2*verticalPadding
 )
 
+// Recomputed from the terminal size by resizeBox
+var (
+	boxWidth       = defaultBoxWidth
+	boxHeight      = defaultBoxHeight
+	totalBoxWidth  = boxWidth + 2*horizontalPadding
+	totalBoxHeight = boxHeight + 2*verticalPadding
+)
+
+// Rebuilt by buildStyles whenever the theme or the box size changes
 var (
-	background            = lipgloss.NewStyle().Background(black)
-	promptStyle           = background.Italic(true).Foreground(darkSeaGreen4)
-	promptStatsEntryStyle = background.Italic(false).Foreground(darkSeaGreen4)
-	questionStatsStyle    = background.Italic(true).Foreground(wheat4)
-	questionStyle         = background.Foreground(darkSeaGreen2)
-	statsTitleStyle       = background.Foreground(darkSeaGreen4).Width(boxWidth)
-	helpMsgStyle          = background.Foreground(lightPink4)
-	helpKeyStyle          = helpMsgStyle.Bold(true)
+	background              lipgloss.Style
+	promptStyle             lipgloss.Style
+	promptStatsEntryStyle   lipgloss.Style
+	questionStatsStyle      lipgloss.Style
+	questionStyle           lipgloss.Style
+	statsTitleStyle         lipgloss.Style
+	helpMsgStyle            lipgloss.Style
+	helpKeyStyle            lipgloss.Style
+	helpSeparator           string
+	questionStatsAlignStyle lipgloss.Style
+	correctAnswerStyle      lipgloss.Style
+	wrongAnswerStyle        lipgloss.Style
+	recordStyle             lipgloss.Style
+	boxStyle                lipgloss.Style
+)
+
+func buildStyles() {
+	background = lipgloss.NewStyle().Background(backgroundColor)
+	promptStyle = background.Italic(true).Foreground(textColor)
+	promptStatsEntryStyle = background.Italic(false).Foreground(textColor)
+	questionStatsStyle = background.Italic(true).Foreground(mutedColor)
+	questionStyle = background.Foreground(highlightColor)
+	statsTitleStyle = background.Foreground(textColor).Width(boxWidth)
+	helpMsgStyle = background.Foreground(accentColor)
+	helpKeyStyle = helpMsgStyle.Bold(true)
+	helpSeparator = helpMsgStyle.Render(symbols.helpSeparator)
 
 	questionStatsAlignStyle = background.
-				AlignHorizontal(lipgloss.Center).
-				Width(boxWidth)
+		AlignHorizontal(lipgloss.Center).
+		Width(boxWidth)
 	correctAnswerStyle = background.
-				AlignHorizontal(lipgloss.Center).
-				Width(boxWidth).
-				Foreground(darkSeaGreen2)
+		AlignHorizontal(lipgloss.Center).
+		Width(boxWidth).
+		Foreground(highlightColor)
 	wrongAnswerStyle = background.
-				AlignHorizontal(lipgloss.Center).
-				Width(boxWidth).
-				Foreground(lightPink1)
+		AlignHorizontal(lipgloss.Center).
+		Width(boxWidth).
+		Foreground(wrongColor)
+	recordStyle = background.
+		AlignHorizontal(lipgloss.Center).
+		Width(boxWidth).
+		Italic(true).
+		Foreground(recordColor)
 	boxStyle = background.
-			Align(lipgloss.Left, lipgloss.Center).
-			PaddingTop(0).
-			PaddingBottom(0).
-			PaddingLeft(horizontalPadding).
-			PaddingTop(verticalPadding).
-			PaddingBottom(verticalPadding).
-			Width(totalBoxWidth).
-			Height(totalBoxHeight).
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lightPink4).
-			BorderBackground(black)
-)
+		Align(lipgloss.Left, lipgloss.Center).
+		PaddingTop(0).
+		PaddingBottom(0).
+		PaddingLeft(horizontalPadding).
+		PaddingTop(verticalPadding).
+		PaddingBottom(verticalPadding).
+		Width(totalBoxWidth).
+		Height(totalBoxHeight).
+		BorderStyle(symbols.border).
+		BorderForeground(accentColor).
+		BorderBackground(backgroundColor)
+}
 
 // I could not find a way to inline
 // bold and italic tokens in lipgloss
@@ -643,6 +781,8 @@ const BoldSequence = csi + "1m"
 const notBoldSequence = csi + "22m"
 const ItalicSequence = csi + "3m"
 const notItalicSequence = csi + "23m"
+const underlineSequence = csi + "4m"
+const notUnderlineSequence = csi + "24m"
 
 func italic(s string) string {
 	return ItalicSequence + s + notItalicSequence
@@ -652,31 +792,43 @@ func bold(s string) string {
 	return BoldSequence + s + notBoldSequence
 }
 
+func underline(s string) string {
+	return underlineSequence + s + notUnderlineSequence
+}
+
+// Bindings are pointers into keys, so that
+// help shows keys remapped by the user
 type helpEntry struct {
-	bindings []string
+	bindings []*key.Binding
 	action   string
 }
 
-var helpSeparator = helpMsgStyle.Render(" • ")
-
 func renderHelpRow(entries []helpEntry) string {
 	rendered_entries := make([]string, len(entries))
 	for i, entry := range entries {
-		rendered_entries[i] = helpKeyStyle.Render(strings.Join(entry.bindings, "/")) +
+		var pressedKeys []string
+		for _, binding := range entry.bindings {
+			// Entries for several actions name only one key of each
+			if len(entry.bindings) > 1 {
+				pressedKeys = append(pressedKeys, binding.Keys()[0])
+			} else {
+				pressedKeys = append(pressedKeys, binding.Keys()...)
+			}
+		}
+		rendered_entries[i] = helpKeyStyle.Render(keyNames(pressedKeys)) +
 			helpMsgStyle.Render(" "+entry.action)
 	}
-	help_row := strings.Join(rendered_entries, helpSeparator)
-	return lipgloss.NewStyle().Inline(true).Render(help_row)
+	return wrapHelpRow(rendered_entries)
 }
 
 func renderStatsTrisymbol(baseStyle lipgloss.Style, stats questionStats) string {
 	// questionStats probably would be changed for something like visibleStats
-	correctCounterStyle := baseStyle.Foreground(darkSeaGreen4)
-	mistakesCounterStyle := baseStyle.Foreground(lightPink4)
-	streakCounterStyle := baseStyle.Foreground(wheat4)
-	return correctCounterStyle.Render(strconv.Itoa(int(stats.correct))+" ● ") +
-		mistakesCounterStyle.Render(strconv.Itoa(int(stats.mistakes))+" ● ") +
-		streakCounterStyle.Render(strconv.Itoa(int(stats.streak))+" ●")
+	correctCounterStyle := baseStyle.Foreground(textColor)
+	mistakesCounterStyle := baseStyle.Foreground(accentColor)
+	streakCounterStyle := baseStyle.Foreground(mutedColor)
+	return correctCounterStyle.Render(strconv.Itoa(int(stats.Correct))+" "+symbols.correct+" ") +
+		mistakesCounterStyle.Render(strconv.Itoa(int(stats.Mistakes))+" "+symbols.mistake+" ") +
+		streakCounterStyle.Render(strconv.Itoa(int(stats.Streak))+" "+symbols.streak)
 }
 
 func (screen quizScreen) renderGlobalStatsRow() string {
@@ -685,111 +837,139 @@ func (screen quizScreen) renderGlobalStatsRow() string {
 		// The current one is unanswered
 		current_question++
 	}
-	statsStyle := background.Foreground(darkSeaGreen4)
+	statsStyle := background.Foreground(textColor)
 	statsTrisymbol := renderStatsTrisymbol(
 		statsStyle.Bold(true),
-		questionStats{screen.streak, screen.correctAnswers, screen.wrongAnswers},
+		questionStats{Streak: screen.streak, Correct: screen.correctAnswers, Mistakes: screen.wrongAnswers},
 	)
+	note := "best " + strconv.Itoa(int(screen.statistics.BestSessionStreak))
+	if len(screen.replayQueue) > 0 {
+		note = strconv.Itoa(len(screen.replayQueue)) + " more to replay"
+	} else if screen.againQuestion {
+		note = "again"
+	} else if screen.pinnedQuestion {
+		note = "pinned"
+	} else if screen.warmUpQueue != nil {
+		note = "warm-up"
+	}
+	note += screen.renderTimeLeft()
 	return statsStyle.Width(boxWidth-lipgloss.Width(statsTrisymbol)).AlignHorizontal(lipgloss.Left).
-		Render("Question "+bold(strconv.Itoa(current_question))+".       ") +
+		Render("Question "+bold(strconv.Itoa(current_question))+screen.renderSessionLength()+".  "+italic(note)) +
 		statsTrisymbol
 }
 
 func (screen quizScreen) renderQuestion() string {
-	prompts := []string{
-		promptStyle.Render("Form Clue: "),
-		promptStyle.Render("Verb: "),
-		promptStyle.Render("Verb Form: "),
-	}
-	prompt_block := lipgloss.JoinVertical(
-		lipgloss.Right,
-		prompts...,
-	)
-	maxlen := 0
-	for _, prompt := range prompts {
-		maxlen = max(maxlen, lipgloss.Width(prompt))
+	questionBoxStyle := questionStyle.Width(questionBlockWidth()).AlignHorizontal(screen.question.alignment())
+	screen.inputField.Width = inputFieldWidth()
+	// Once submitted the answer no longer scrolls, it is shown whole
+	answer := screen.inputField.View()
+	if screen.mode == validation {
+		answer = screen.inputField.Value()
 	}
-	questionBlockWidth := boxWidth - maxlen
-	questionBoxStyle := questionStyle.Width(questionBlockWidth)
-	question_block := lipgloss.JoinVertical(
-		lipgloss.Left,
-		questionBoxStyle.Render(screen.question.prompt.formClue),
-		questionBoxStyle.Render(screen.question.prompt.verb),
-		questionBoxStyle.Render(screen.inputField.View()),
-	)
-	return lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		prompt_block,
-		question_block,
+	return renderLabelledRows(
+		questionBoxStyle,
+		[]string{"Form Clue:", "Verb:", "Verb Form:"},
+		[]string{screen.question.prompt.FormClue, screen.question.prompt.Verb, answer},
 	)
 }
 
 var inputHelp = [...]helpEntry{
-	{bindings: []string{"enter"}, action: "submit"},
-	{bindings: []string{"ctrl+s"}, action: "stats"},
-	{bindings: []string{"esc"}, action: "exit"},
+	{bindings: []*key.Binding{&keys.Submit}, action: "submit"},
+	{bindings: []*key.Binding{&keys.Menu}, action: "menu"},
+	{bindings: []*key.Binding{&keys.Picker}, action: "accents"},
+	{bindings: []*key.Binding{&keys.Help}, action: "keys"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
 }
 
 func (screen quizScreen) renderQuestionStatsRow() string {
 	return questionStatsAlignStyle.Render(questionStatsStyle.Render("[question stats: ") +
-		renderStatsTrisymbol(background.Italic(true), screen.statistics.statistics[screen.question.prompt]) +
-		questionStatsStyle.Render("]"))
+		renderStatsTrisymbol(background.Italic(true), screen.statistics.Record(screen.question.prompt)) +
+		questionStatsStyle.Render(
+			" best "+strconv.Itoa(int(screen.statistics.Record(screen.question.prompt).BestStreak))+"]",
+		))
 }
 
 func (screen quizScreen) inputView() string {
+	var choicesRow string
+	if len(screen.choices) > 0 {
+		choicesRow = questionStatsAlignStyle.Render(screen.renderChoices())
+	}
 	body := lipgloss.JoinVertical(
 		lipgloss.Left,
 		screen.renderGlobalStatsRow(),
 		"",
 		screen.renderQuestion(),
 		"",
-		"",
+		choicesRow,
 		screen.renderQuestionStatsRow(),
+		renderReadOnlyRow(),
 	)
-	footer := renderHelpRow(inputHelp[:])
-	spacing := boxHeight - lipgloss.Height(body) - lipgloss.Height(footer)
-	content := body + strings.Repeat("\n", spacing+1) + footer
-	return boxStyle.Render(content)
+	footer := renderHelpRow(screen.withChoiceHelp(screen.withPlayHelp(inputHelp[:])))
+	if screen.picker.open {
+		body = lipgloss.JoinVertical(
+			lipgloss.Left,
+			screen.renderGlobalStatsRow(),
+			"",
+			screen.renderQuestion(),
+			"",
+			"",
+			screen.renderPicker(),
+			renderReadOnlyRow(),
+		)
+		footer = renderHelpRow(pickerHelp[:])
+	}
+	return renderBox(screen.withImage(body), footer)
 }
 
 var validationHelp = [...]helpEntry{
-	{bindings: []string{"enter"}, action: "next"},
-	{bindings: []string{"ctrl+s"}, action: "stats"},
-	{bindings: []string{"esc"}, action: "exit"},
+	{bindings: []*key.Binding{&keys.Submit}, action: "next"},
+	{bindings: []*key.Binding{&keys.Menu}, action: "menu"},
+	{bindings: []*key.Binding{&keys.Lookup}, action: "dictionary"},
+	{bindings: []*key.Binding{&keys.Copy, &keys.CopyAll}, action: "copy"},
+	{bindings: []*key.Binding{&keys.Conjugation}, action: "table"},
+	{bindings: []*key.Binding{&keys.Note}, action: "note"},
+	{bindings: []*key.Binding{&keys.Pin}, action: "pin"},
+	{bindings: []*key.Binding{&keys.Help}, action: "keys"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
 }
 
 func (screen quizScreen) validationView() string {
-	footer := renderHelpRow(validationHelp[:])
+	footer := renderHelpRow(screen.withPlayHelp(validationHelp[:]))
 	body := lipgloss.JoinVertical(
 		lipgloss.Left,
 		screen.renderGlobalStatsRow(),
 		"",
 		screen.renderQuestion(),
 		"",
-		"",
+		screen.renderNoteRow(),
 		screen.renderValidationRow(),
+		screen.renderRecordRow(),
+		renderReadOnlyRow(),
 	)
-	spacing := boxHeight - lipgloss.Height(body) - lipgloss.Height(footer)
-	content := body + strings.Repeat("\n", spacing+1) + footer
-	return boxStyle.Render(content)
+	return renderBox(screen.withImage(body), footer)
 }
 
-func (screen statisticsScreen) renderStatEntry(prompt prompt, selected bool) string {
+func (screen statisticsScreen) renderStatEntry(prompt prompt, selected bool, difficulty difficultyEstimator) string {
 	statsTrisymbol := renderStatsTrisymbol(
 		background.Bold(selected).Italic(selected),
-		screen.statistics.statistics[prompt],
+		screen.statistics.Record(prompt),
 	)
 	if selected {
-		bracketStyle := background.Italic(true).Foreground(wheat4)
+		bracketStyle := background.Italic(true).Foreground(mutedColor)
 		statsTrisymbol = bracketStyle.Render("[") + statsTrisymbol + bracketStyle.Render("]")
 	} else {
 		statsTrisymbol += background.Render(" ")
 	}
-	promptFormated := fmt.Sprintf("%s + %s", prompt.formClue, prompt.verb)
+	statsTrisymbol = difficulty.withBadge(screen.statistics.Record(prompt), statsTrisymbol)
+	promptFormated := fmt.Sprintf("%s + %s", prompt.FormClue, prompt.Verb)
+	if screen.statistics.IsPinned(prompt) {
+		promptFormated += ", pinned"
+	}
 	if selected {
 		promptFormated = "> " + promptFormated
 	}
 	return promptStatsEntryStyle.
+		Foreground(stageOf(screen.statistics.Record(prompt)).color()).
 		Bold(selected).
 		Italic(selected).
 		Width(boxWidth-lipgloss.Width(statsTrisymbol)).
@@ -799,43 +979,69 @@ func (screen statisticsScreen) renderStatEntry(prompt prompt, selected bool) str
 }
 
 var statisticsScreenHelp = [...]helpEntry{
-	{bindings: []string{"k", "↑"}, action: "up"},
-	{bindings: []string{"j", "↓"}, action: "down"},
-	{bindings: []string{"backspace"}, action: "back"},
-	{bindings: []string{"esc"}, action: "exit"},
+	{bindings: []*key.Binding{&keys.Up}, action: "up"},
+	{bindings: []*key.Binding{&keys.Down}, action: "down"},
+	{bindings: []*key.Binding{&keys.Conjugation}, action: "table"},
+	{bindings: []*key.Binding{&keys.Pin}, action: "pin"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
 }
 
-func (screen *statisticsScreen) scrollDown() {
+// Scrolling state of a screen showing a long list,
+// selectedRow is relative to the first shown entry
+type listPosition struct {
+	firstShownIndex int
+	selectedRow     int
+}
+
+func (position listPosition) selectedIndex() int {
+	return position.firstShownIndex + position.selectedRow
+}
+
+// Keeps the selected row on screen after the box got smaller
+func (position *listPosition) fit(shownRows int) {
+	if position.selectedRow >= shownRows {
+		position.firstShownIndex += position.selectedRow - shownRows + 1
+		position.selectedRow = shownRows - 1
+	}
+}
+
+func (position *listPosition) scrollDown(total int, shownRows int) {
 	keepOnScreen := 2
-	shownRows := boxHeight - 2 - 2
-	if screen.selectedRow < shownRows-keepOnScreen-1 {
-		screen.selectedRow++
+	position.fit(shownRows)
+	if position.selectedIndex() >= total-1 {
+		return
+	}
+	if position.selectedRow < shownRows-keepOnScreen-1 {
+		position.selectedRow++
 		return
 	}
-	if screen.firstShownIndex+shownRows < len(screen.orderedPromptList) {
-		screen.firstShownIndex++
-	} else if screen.selectedRow < shownRows-1 {
-		screen.selectedRow++
+	if position.firstShownIndex+shownRows < total {
+		position.firstShownIndex++
+	} else if position.selectedRow < shownRows-1 {
+		position.selectedRow++
 	}
 }
 
-func (screen *statisticsScreen) scrollUp() {
+func (position *listPosition) scrollUp() {
 	keepOnScreen := 2
-	if screen.selectedRow > keepOnScreen {
-		screen.selectedRow--
+	if position.selectedRow > keepOnScreen {
+		position.selectedRow--
 		return
 	}
-	if screen.firstShownIndex > 0 {
-		screen.firstShownIndex--
-	} else if screen.selectedRow > 0 {
-		screen.selectedRow--
+	if position.firstShownIndex > 0 {
+		position.firstShownIndex--
+	} else if position.selectedRow > 0 {
+		position.selectedRow--
 	}
 }
 
 func (screen statisticsScreen) View() string {
 	footer := renderHelpRow(statisticsScreenHelp[:])
-	renderedLines := []string{statsTitleStyle.Render("Statistics"), ""}
-	shownRows := boxHeight - 2 - 2
+	shownRows := listShownRows()
+	screen.fit(shownRows)
+	renderedLines := []string{renderListTitle("Statistics", screen.listPosition, len(screen.orderedPromptList)), ""}
+	difficulty := screen.statistics.difficultyEstimator()
 	for row := 0; row < shownRows; row++ {
 		promptIndex := screen.firstShownIndex + row
 		if promptIndex >= len(screen.orderedPromptList) {
@@ -845,15 +1051,44 @@ func (screen statisticsScreen) View() string {
 		renderedLines = append(renderedLines, screen.renderStatEntry(
 			entryPrompt,
 			row == screen.selectedRow,
+			difficulty,
 		))
 	}
 	body := lipgloss.JoinVertical(
 		lipgloss.Left,
 		renderedLines...,
 	)
-	spacing := boxHeight - lipgloss.Height(body) - lipgloss.Height(footer)
-	content := body + strings.Repeat("\n", spacing+1) + footer
-	return boxStyle.Render(content)
+	if selectedIndex := screen.selectedIndex(); selectedIndex < len(screen.orderedPromptList) {
+		selectedStats := screen.statistics.Record(screen.orderedPromptList[selectedIndex])
+		detail := stageOf(selectedStats).String() + symbols.helpSeparator + renderPracticeRecency(selectedStats, time.Now())
+		if len(selectedStats.WrongAnswers) > 0 {
+			// Whether the same mistake keeps coming back or a different one each time
+			detail += symbols.helpSeparator + "wrong: " + summarizeAnswers(selectedStats.WrongAnswers)
+		}
+		footer = lipgloss.JoinVertical(
+			lipgloss.Left,
+			questionStatsAlignStyle.Render(questionStatsStyle.Inline(true).MaxWidth(boxWidth).Render(detail)),
+			footer,
+		)
+	}
+	return renderBox(body, footer)
+}
+
+func renderPracticeRecency(stats questionStats, now time.Time) string {
+	if stats.LastPracticed.IsZero() {
+		if stats.Correct == 0 && stats.Mistakes == 0 {
+			return "never practiced"
+		}
+		return "last practice date unknown"
+	}
+	switch days := daysBetween(stats.LastPracticed, now); days {
+	case 0:
+		return "practiced today"
+	case 1:
+		return "not practiced since yesterday"
+	default:
+		return fmt.Sprintf("not practiced in %d days", days)
+	}
 }
 
 func (screen quizScreen) View() string {
@@ -868,7 +1103,18 @@ func (screen quizScreen) View() string {
 }
 
 func (m model) View() string {
-	content := m.screen.View()
+	if currentLayout == compactLayout {
+		return m.compactView()
+	}
+	screen := clearImages(m.top().View())
+	if currentLayout == fullLayout && m.session.isLoaded() {
+		screen = renderFullScreen(screen, m.session.statistics, m.session.history)
+	}
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		screen,
+		renderStatusBar(m.top(), m.session),
+	)
 	if !m.isInAltscreen {
 		// Terminal wants everything to end
 		// with explicit newline character
@@ -878,24 +1124,132 @@ func (m model) View() string {
 		Align(lipgloss.Center, lipgloss.Center).
 		Width(m.width).
 		Height(m.height).
-		Background(black).
+		Background(backgroundColor).
 		Render(content)
 }
 
-func main() {
-	f, err := tea.LogToFile(logPath, "")
-	defer f.Close()
-	if err != nil {
-		log.Printf("[FATAL] %v\n", err)
-		exit(loggingError)
+func runQuiz(args []string) {
+	flags, options := newFlagSet("quiz", "")
+	var display displayOptions
+	display.register(flags)
+	flags.BoolVar(&accentCompletion, "accent-completion", false, "put accents on letters typed without them where the answer has them")
+	flags.BoolVar(&animations, "animations", true, "pulse the border on answers and shake it on wrong ones, false turns all animation off")
+	flags.IntVar(&warmUpLength, "warm-up", warmUpLength, "`number` of easy questions to start with before the usual selection")
+	flags.DurationVar(&timeLimit, "time-limit", 0, "mark questions not answered within this `duration` wrong, 0 means no limit")
+	flags.Var(&answerChecking, "answer-checking", "how closely answers must match: exact, ignore-case or ignore-accents")
+	flags.IntVar(&answerLimit, "answer-limit", answerLimit, "most `number` of letters an answer can have, longer answers of the deck always fit")
+	flags.IntVar(&multipleChoice, "choices", 0, "`number` of answers to choose from with the digit keys instead of typing one, 0 means typing")
+	flags.Var(&distractors, "distractors", "how wrong answers to choose from are picked: similar, for forms of the same verb and look-alikes, or random")
+	flags.Var(&nextQuestionOrder, "order", "how the next question is chosen: weighted, due-first for due reviews before the rest, or ladder for runs from easy to hard")
+	flags.DurationVar(&streakDecayHorizon, "streak-decay", 0, "on load, cut the streaks of questions not practiced for longer than this `duration`, 0 keeps them")
+	flags.Var(&deadRecordsRetention, "dead-records", "records of questions missing from the deck: keep, drop, or a number of days since last practiced to keep them for; retired records are moved to a file next to the statistics")
+	flags.IntVar(&sessionLength, "session-length", 0, "end the quiz after this `number` of answers, 0 means no limit")
+	flags.IntVar(&autosaveEvery, "autosave", 0, "save progress after every `number` of answers, 0 saves only when leaving the quiz")
+	flags.Var(&bellSignal, "bell", "answers which ring the terminal bell: off, wrong, correct or all")
+	flags.Var(&flashSignal, "flash", "answers which flash the border: off, wrong, correct or all")
+	screenReader := flags.Bool("screen-reader", false, "plain labelled lines instead of the box, colors and cursor movement")
+	flags.StringVar(&audioPlayer, "player", "", "`command` playing the audio clips of the deck, given the clip as its last argument")
+	imagesFlag := flags.String("images", "auto", "`protocol` drawing the images of the deck: kitty, iterm, sixel or text, auto picks one for the terminal")
+	composeLanguage := flags.String("compose", "auto", "`language` of sequences like 'e for accented letters, auto picks one for the deck, off disables them")
+	frequencyList := flags.String("frequency", "auto", "verb frequency `list` introducing new questions, most frequent verbs first: auto, off, a language or a file")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	// Logs would otherwise end up on top of the UI, so in
+	// read-only mode they are discarded instead
+	options.apply()
+	preferences := loadPreferences()
+	explicit := explicitFlags(flags)
+	preferences.applySettings(flags, explicit)
+	if timeLimit < 0 || sessionLength < 0 || autosaveEvery < 0 || streakDecayHorizon < 0 {
+		fmt.Fprintln(os.Stderr, "Time limit, session length, autosave and streak decay can not be negative")
+		exit(usageError)
 	}
-
-	log.Println("[INFO] Starting app...")
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	log.Println("[INFO] Starting UI loop...")
-	if _, err := p.Run(); err != nil {
-		log.Printf("[FATAL] Program finished with error:\n%v", err)
+	if answerLimit <= 0 {
+		fmt.Fprintln(os.Stderr, "Answer limit must be positive")
+		exit(usageError)
+	}
+	if multipleChoice == 1 || multipleChoice < 0 || multipleChoice > 9 {
+		fmt.Fprintln(os.Stderr, "Multiple choice needs from 2 to 9 answers, or 0 to type them")
+		exit(usageError)
+	}
+	preferences.applyDeck(options.paths)
+	if err := display.apply(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(usageError)
+	}
+	preferences.applyDisplay(explicit)
+	if err := useImageProtocol(*imagesFlag); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(usageError)
+	}
+	if err := loadKeyMap(); err != nil {
+		logFatal("Failed to load keymap", "error", err)
+		fmt.Fprintf(os.Stderr, "Failed to load keymap: %v\n", err)
+		exit(usageError)
+	}
+	loadSessionSettings()
+
+	slog.Info("Starting app", "build", currentBuild().String())
+	lockOrFallBackToReadOnly()
+	if *screenReader {
+		slog.Info("Using screen reader mode")
+		database := read_database()
+		statistics := database.loadStatistics()
+		history := loadHistory()
+		useDeckSettingsOrExit(database.Settings, explicit)
+		statistics.decayStaleStreaks(time.Now())
+		statistics.retireDeadRecords(time.Now())
+		if err := useComposeLanguage(*composeLanguage, &statistics); err != nil {
+			logFatal("Invalid compose language", "error", err)
+			fmt.Fprintln(os.Stderr, err)
+			exit(usageError)
+		}
+		if err := useFrequencyList(*frequencyList, &statistics); err != nil {
+			logFatal("Invalid frequency list", "error", err)
+			fmt.Fprintln(os.Stderr, err)
+			exit(usageError)
+		}
+		statistics.useDeckCollation()
+		quiz := newQuizScreen(newSession(&statistics, &history))
+		quiz.startWarmUp()
+		drill(quiz, 0, true)
+		return
+	}
+	initial := initialModel(loadSession(*composeLanguage, *frequencyList, explicit), preferences.Screen)
+	initial.isInAltscreen = preferences.AltScreen
+	programOptions := []tea.ProgramOption{
+		tea.WithMouseCellMotion(),
+		// Both are replaced with handlers that save progress first
+		tea.WithoutSignalHandler(),
+		tea.WithoutCatchPanics(),
+	}
+	if initial.isInAltscreen {
+		programOptions = append(programOptions, tea.WithAltScreen())
+	}
+	p := tea.NewProgram(initial, programOptions...)
+	defer recoverAndSave(p, initial.session)
+	forwardSignals(p)
+	slog.Debug("Starting UI loop")
+	final, err := p.Run()
+	if err != nil {
+		logFatal("Program finished with error", "error", err)
+		initial.session.emergencySave()
 		exit(teaError)
 	}
-	log.Println("[INFO] Finished successfully")
+	if final, isModel := final.(model); isModel {
+		if final.failure != nil {
+			logFatal("Failed to load", "error", final.failure)
+			fmt.Fprintln(os.Stderr, final.failure)
+			exit(final.failure.code)
+		}
+		preferences := currentPreferences(final)
+		preferences.Settings = currentSettings(flags)
+		preferences.save()
+		final.session.finish()
+	}
+	slog.Info("Finished successfully")
+}
+
+func main() {
+	runCommandLine(os.Args[1:])
 }
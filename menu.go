@@ -0,0 +1,202 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+type menuEntry struct {
+	title string
+	// Optional current value shown next to the title
+	detail func() string
+	open   func(screen menuScreen) (tea.Model, tea.Cmd)
+}
+
+var menuEntries = [...]menuEntry{
+	{
+		title: "Continue quiz",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, popScreen
+		},
+	},
+	{
+		title: "Statistics",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(newStatisticsScreen(screen.quiz.statistics))
+		},
+	},
+	{
+		title: "Session summary",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(sessionSummaryScreen{screen.quiz.session})
+		},
+	},
+	{
+		title: "Dashboard",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(dashboardScreen{
+				statistics: screen.quiz.statistics,
+				history:    screen.quiz.history,
+			})
+		},
+	},
+	{
+		title: "Practice calendar",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(heatmapScreen{history: screen.quiz.history})
+		},
+	},
+	{
+		title: "Mistakes",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(newMistakesScreen(screen.quiz))
+		},
+	},
+	{
+		title: "Confusions",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(newConfusionScreen(screen.quiz.statistics))
+		},
+	},
+	{
+		title: "Achievements",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(achievementsScreen{})
+		},
+	},
+	{
+		title: "Leaderboard",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(newLeaderboardScreen(*screen.quiz.history))
+		},
+	},
+	{
+		title: "Browse deck",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(newBrowserScreen(screen.quiz))
+		},
+	},
+	{
+		title: "Conjugations",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(newVerbListScreen(screen.quiz.statistics))
+		},
+	},
+	{
+		title: "Triage new questions",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(newTriageScreen(screen.quiz))
+		},
+	},
+	{
+		title: "Duel",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(newDuelSetupScreen(screen.quiz))
+		},
+	},
+	{
+		title: "Settings",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			return screen, pushScreen(settingsScreen{})
+		},
+	},
+	{
+		title: "Quit",
+		open: func(screen menuScreen) (tea.Model, tea.Cmd) {
+			if len(screen.quiz.againPile) > 0 {
+				return screen, pushScreen(quitConfirmScreen{screen.quiz.session})
+			}
+			return screen, requestQuit
+		},
+	},
+}
+
+// Quiz screen saves statistics before opening the menu,
+// so the menu and screens opened from it can exit directly
+type menuScreen struct {
+	quiz *quizScreen
+	menuSelection
+}
+
+type menuSelection struct {
+	selected int
+}
+
+func (screen *menuSelection) moveDown(count int) {
+	screen.selected = min(screen.selected+1, count-1)
+}
+
+func (screen *menuSelection) moveUp() {
+	screen.selected = max(screen.selected-1, 0)
+}
+
+func (screen menuScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen menuScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Menu, keys.Back):
+			return screen, popScreen
+		case key.Matches(msg, keys.Down):
+			screen.moveDown(len(menuEntries))
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.moveUp()
+			return screen, nil
+		case key.Matches(msg, keys.Submit):
+			return menuEntries[screen.selected].open(screen)
+		}
+	case tea.MouseMsg:
+		if screen.handleMouse(msg, listFirstRow, len(menuEntries)) {
+			return menuEntries[screen.selected].open(screen)
+		}
+		return screen, nil
+	}
+	return screen, nil
+}
+
+var menuScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Up}, action: "up"},
+	{bindings: []*key.Binding{&keys.Down}, action: "down"},
+	{bindings: []*key.Binding{&keys.Submit}, action: "open"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+// Title with the profile in use, version goes to the right
+func renderMenuTitle(title string) string {
+	if profileName != "" {
+		title += " " + italic("("+profileName+")")
+	}
+	build := questionStatsStyle.Render(currentBuild().shortVersion())
+	return statsTitleStyle.Width(boxWidth-lipgloss.Width(build)).Render(title) + build
+}
+
+// Detail is an optional current value shown on the right
+func renderMenuEntry(title string, detail func() string, selected bool) string {
+	if selected {
+		title = "> " + title
+	}
+	renderedDetail := ""
+	if detail != nil {
+		renderedDetail = questionStatsStyle.Render(detail())
+	}
+	return promptStatsEntryStyle.
+		Bold(selected).
+		Italic(selected).
+		Width(boxWidth-lipgloss.Width(renderedDetail)).
+		Render(title) + renderedDetail
+}
+
+func (screen menuScreen) View() string {
+	lines := []string{renderMenuTitle("Menu"), ""}
+	for i, entry := range menuEntries {
+		lines = append(lines, renderMenuEntry(entry.title, entry.detail, i == screen.selected))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	footer := renderHelpRow(menuScreenHelp[:])
+	return renderBox(body, footer)
+}
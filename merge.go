@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kligunov-id/gem2/stats"
+)
+
+func printMergeReport(report stats.MergeReport) {
+	fmt.Printf("Merged statistics for %d questions\n", report.Merged)
+	fmt.Printf("Added statistics for %d new questions\n", report.Added)
+	if report.DeadRecords > 0 {
+		fmt.Printf("Kept %d records for questions missing from the word database\n", report.DeadRecords)
+	}
+	if len(report.Conflicts) == 0 {
+		return
+	}
+	fmt.Printf("Skipped %d questions with conflicting answers:\n", len(report.Conflicts))
+	for _, conflict := range report.Conflicts {
+		fmt.Printf(
+			"    %s: %q here, %q in merged file\n",
+			conflict.Prompt,
+			conflict.LocalAnswer,
+			conflict.OtherAnswer,
+		)
+	}
+}
+
+func mergeStatisticsFile(path string) {
+	database := read_database()
+	statistics := database.loadStatistics()
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		logFatal("Failed to read statistics file to merge", "path", path, "error", err)
+		exit(statisticsError)
+	}
+	statisticsTOML, _, err := stats.Parse(bytes)
+	if err != nil {
+		logFatal("Failed to parse statistics file to merge", "path", path, "error", err)
+		exit(statisticsError)
+	}
+	report := statistics.Merge(statisticsTOML)
+	if err := statistics.save(); err != nil {
+		logFatal("Failed to save merged statistics", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(statisticsError)
+	}
+	printMergeReport(report)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Keeps a copy of the file as it was before migration,
+// so a faulty migration can never lose data for good
+func backupBeforeMigration(path string, bytes []byte, version int64) error {
+	if readOnly {
+		return nil
+	}
+	migrationBackupPath := fmt.Sprintf("%s.v%d", path, version)
+	if _, err := os.Stat(migrationBackupPath); err == nil {
+		return nil
+	}
+	if err := os.WriteFile(migrationBackupPath, bytes, 0666); err != nil {
+		return fmt.Errorf("could not back up statistics before migration: %w", err)
+	}
+	slog.Info("Backed up statistics before migration", "path", migrationBackupPath)
+	return nil
+}
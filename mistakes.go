@@ -0,0 +1,390 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Times of the latest mistakes kept per prompt, enough for replaying
+// recent ones, the count keeps the total while older times are dropped
+const keptMistakeTimes = 20
+
+// Repeated mistakes on the same prompt are merged into one record,
+// remembering how often each distinct wrong answer was given
+type mistakeRecord struct {
+	correctAnswer string
+	count         uint32
+	answers       map[string]uint32
+	times         []time.Time
+}
+
+type mistakeRecordTOML struct {
+	Correct string
+	Count   uint32
+	Answers map[string]uint32
+	Times   []time.Time
+}
+
+// Nested as Mistakes.formClue.verb, so any characters round-trip
+type mistakesLogTOML struct {
+	Mistakes map[string]map[string]mistakeRecordTOML
+}
+
+// Format written before mistakes were deduplicated,
+// with one [[Mistakes]] table appended per mistake
+type legacyMistakeTOML struct {
+	Time     time.Time
+	FormClue string
+	Verb     string
+	Correct  string
+	Answer   string
+}
+
+type legacyMistakesLogTOML struct {
+	Mistakes []legacyMistakeTOML
+}
+
+func (record *mistakeRecord) add(correctAnswer string, answer string, t time.Time) {
+	if record.answers == nil {
+		record.answers = make(map[string]uint32)
+	}
+	record.correctAnswer = correctAnswer
+	record.count++
+	record.answers[strings.TrimSpace(answer)]++
+	record.times = append(record.times, t)
+	record.times = record.times[max(len(record.times)-keptMistakeTimes, 0):]
+}
+
+func (record mistakeRecord) last() time.Time {
+	var last time.Time
+	for _, t := range record.times {
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+func (record mistakeRecord) countSince(since time.Time) int {
+	count := 0
+	for _, t := range record.times {
+		if !t.Before(since) {
+			count++
+		}
+	}
+	return count
+}
+
+func packMistakes(mistakes map[prompt]mistakeRecord) mistakesLogTOML {
+	nested := make(map[string]map[string]mistakeRecordTOML)
+	for prompt, record := range mistakes {
+		if _, exists := nested[prompt.FormClue]; !exists {
+			nested[prompt.FormClue] = make(map[string]mistakeRecordTOML)
+		}
+		nested[prompt.FormClue][prompt.Verb] = mistakeRecordTOML{
+			Correct: record.correctAnswer,
+			Count:   record.count,
+			Answers: record.answers,
+			Times:   record.times,
+		}
+	}
+	return mistakesLogTOML{nested}
+}
+
+func parseMistakes(bytes []byte) (map[prompt]mistakeRecord, error) {
+	mistakes := make(map[prompt]mistakeRecord)
+	var mistakesTOML mistakesLogTOML
+	err := toml.Unmarshal(bytes, &mistakesTOML)
+	if err == nil {
+		for formClue, verbs := range mistakesTOML.Mistakes {
+			for verb, data := range verbs {
+				if data.Answers == nil {
+					data.Answers = make(map[string]uint32)
+				}
+				mistakes[prompt{FormClue: formClue, Verb: verb}] = mistakeRecord{
+					correctAnswer: data.Correct,
+					count:         data.Count,
+					answers:       data.Answers,
+					// Files of older builds kept every time
+					times: data.Times[max(len(data.Times)-keptMistakeTimes, 0):],
+				}
+			}
+		}
+		return mistakes, nil
+	}
+	var legacyTOML legacyMistakesLogTOML
+	if legacyErr := toml.Unmarshal(bytes, &legacyTOML); legacyErr != nil {
+		return nil, err
+	}
+	slog.Info("Converting mistakes file with one entry per mistake")
+	for _, mistake := range legacyTOML.Mistakes {
+		prompt := prompt{FormClue: mistake.FormClue, Verb: mistake.Verb}
+		record := mistakes[prompt]
+		record.add(mistake.Correct, mistake.Answer, mistake.Time)
+		mistakes[prompt] = record
+	}
+	return mistakes, nil
+}
+
+func readMistakes() map[prompt]mistakeRecord {
+	bytes, err := os.ReadFile(mistakesPath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Error("Failed to read mistakes file", "path", mistakesPath, "error", err)
+		}
+		return map[prompt]mistakeRecord{}
+	}
+	mistakes, err := parseMistakes(bytes)
+	if err != nil {
+		slog.Error("Failed to parse mistakes file", "path", mistakesPath, "error", err)
+		return nil
+	}
+	return mistakes
+}
+
+func (screen quizScreen) logMistake() {
+	if readOnly {
+		return
+	}
+	mistakes := readMistakes()
+	if mistakes == nil {
+		// Rewriting an unparsable file would lose its content
+		slog.Error("Failed to log mistake", "prompt", screen.question.prompt)
+		return
+	}
+	record := mistakes[screen.question.prompt]
+	record.add(
+		screen.question.correctAnswer,
+		screen.inputField.Value(),
+		time.Now().Truncate(time.Second),
+	)
+	mistakes[screen.question.prompt] = record
+	if err := saveMistakes(mistakes); err != nil {
+		slog.Error("Failed to log mistake", "prompt", screen.question.prompt, "error", err)
+		return
+	}
+	slog.Info("Logged mistake", "prompt", screen.question.prompt)
+	notify("mistake logged")
+}
+
+func saveMistakes(mistakes map[prompt]mistakeRecord) error {
+	bytes, err := toml.Marshal(packMistakes(mistakes))
+	if err != nil {
+		logFatal("Unachievable TOML encoding error", "error", err)
+		exit(internalError)
+	}
+	return writeFileAtomic(mistakesPath, bytes, 0)
+}
+
+type mistakeGroup struct {
+	prompt prompt
+	record mistakeRecord
+	last   time.Time
+}
+
+// Most recently missed prompts first
+func groupMistakes(mistakes map[prompt]mistakeRecord) []mistakeGroup {
+	groups := make([]mistakeGroup, 0, len(mistakes))
+	for prompt, record := range mistakes {
+		groups = append(groups, mistakeGroup{prompt, record, record.last()})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if !groups[i].last.Equal(groups[j].last) {
+			return groups[i].last.After(groups[j].last)
+		}
+		return comparePrompts(groups[i].prompt, groups[j].prompt) < 0
+	})
+	return groups
+}
+
+// Prompts missed since the given time which still exist in the
+// word database, most often missed first
+func (statistics statisticsDatabase) replayQueue(mistakes map[prompt]mistakeRecord, since time.Time) []prompt {
+	counts := make(map[prompt]int)
+	var queue []prompt
+	for prompt, record := range mistakes {
+		if _, exists := statistics.Lookup(prompt); !exists {
+			continue
+		}
+		if count := record.countSince(since); count > 0 {
+			counts[prompt] = count
+			queue = append(queue, prompt)
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		if counts[queue[i]] != counts[queue[j]] {
+			return counts[queue[i]] > counts[queue[j]]
+		}
+		return comparePrompts(queue[i], queue[j]) < 0
+	})
+	return queue
+}
+
+type mistakesScreen struct {
+	quiz     *quizScreen
+	mistakes map[prompt]mistakeRecord
+	groups   []mistakeGroup
+	listPosition
+}
+
+func newMistakesScreen(quiz *quizScreen) mistakesScreen {
+	mistakes := readMistakes()
+	return mistakesScreen{
+		quiz:     quiz,
+		mistakes: mistakes,
+		groups:   groupMistakes(mistakes),
+	}
+}
+
+func (screen mistakesScreen) startReplay(period time.Duration) (tea.Model, tea.Cmd) {
+	queue := screen.quiz.statistics.replayQueue(screen.mistakes, time.Now().Add(-period))
+	if len(queue) == 0 {
+		return screen, nil
+	}
+	slog.Info("Replaying missed questions", "count", len(queue), "period", period)
+	return screen, replayPrompts(*screen.quiz, queue)
+}
+
+// Quiz asking the queued prompts first, it replaces
+// the quiz the menu was opened from, if any
+func replayPrompts(quiz quizScreen, queue []prompt) tea.Cmd {
+	quiz.replayQueue = queue
+	quiz.nextQuestion()
+	quiz.inputField.Reset()
+	quiz.inputField.Focus()
+	quiz.mode = input
+	return resetScreens(quiz)
+}
+
+func (screen mistakesScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen mistakesScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back):
+			return screen, popScreen
+		case key.Matches(msg, keys.Down):
+			screen.scrollDown(len(screen.groups), listShownRows())
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.scrollUp()
+			return screen, nil
+		case key.Matches(msg, keys.ReplayDay):
+			return screen.startReplay(24 * time.Hour)
+		case key.Matches(msg, keys.ReplayWeek):
+			return screen.startReplay(7 * 24 * time.Hour)
+		}
+	}
+	return screen, nil
+}
+
+func formatTimeAgo(t time.Time, now time.Time) string {
+	switch days := daysBetween(t, now); days {
+	case 0:
+		return "today"
+	case 1:
+		return "yesterday"
+	default:
+		return fmt.Sprintf("%dd ago", days)
+	}
+}
+
+func (screen mistakesScreen) renderGroupEntry(group mistakeGroup, selected bool) string {
+	info := background.Bold(selected).Foreground(accentColor).Render(fmt.Sprintf(
+		"%d%s %s",
+		group.record.count,
+		symbols.times,
+		formatTimeAgo(group.last, time.Now()),
+	))
+	entry := fmt.Sprintf("%s %s %s", group.prompt, symbols.arrow, group.record.correctAnswer)
+	if selected {
+		entry = "> " + entry
+	}
+	return promptStatsEntryStyle.
+		Bold(selected).
+		Italic(selected).
+		Width(boxWidth-lipgloss.Width(info)).
+		AlignHorizontal(lipgloss.Left).
+		Render(entry) +
+		info
+}
+
+func (group mistakeGroup) describe() string {
+	return "answered " + group.record.summarizeAnswers()
+}
+
+func (record mistakeRecord) summarizeAnswers() string {
+	return summarizeAnswers(record.answers)
+}
+
+// Distinct wrong answers, most frequent first
+func summarizeAnswers(counts map[string]uint32) string {
+	answers := make([]string, 0, len(counts))
+	for answer := range counts {
+		answers = append(answers, answer)
+	}
+	sort.Slice(answers, func(i, j int) bool {
+		if counts[answers[i]] != counts[answers[j]] {
+			return counts[answers[i]] > counts[answers[j]]
+		}
+		return compareText(answers[i], answers[j]) < 0
+	})
+	described := make([]string, len(answers))
+	for i, answer := range answers {
+		described[i] = answer
+		if answer == "" {
+			described[i] = "(empty)"
+		}
+		if count := counts[answer]; count > 1 {
+			described[i] += fmt.Sprintf(" %d%s", count, symbols.times)
+		}
+	}
+	return strings.Join(described, ", ")
+}
+
+var mistakesScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Down, &keys.Up}, action: "move"},
+	{bindings: []*key.Binding{&keys.ReplayDay, &keys.ReplayWeek}, action: "replay 1d/7d"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+}
+
+func (screen mistakesScreen) View() string {
+	screen.fit(listShownRows())
+	footer := renderHelpRow(mistakesScreenHelp[:])
+	lines := []string{renderListTitle("Recent mistakes", screen.listPosition, len(screen.groups)), ""}
+	if len(screen.groups) == 0 {
+		lines = append(lines, questionStatsAlignStyle.Render(questionStatsStyle.Render("no mistakes yet")))
+	}
+	for row := 0; row < listShownRows(); row++ {
+		index := screen.firstShownIndex + row
+		if index >= len(screen.groups) {
+			break
+		}
+		lines = append(lines, screen.renderGroupEntry(screen.groups[index], row == screen.selectedRow))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	if selectedIndex := screen.selectedIndex(); selectedIndex < len(screen.groups) {
+		footer = lipgloss.JoinVertical(
+			lipgloss.Left,
+			questionStatsAlignStyle.Render(questionStatsStyle.Inline(true).MaxWidth(boxWidth).Render(
+				screen.groups[selectedIndex].describe(),
+			)),
+			footer,
+		)
+	}
+	return renderBox(body, footer)
+}
@@ -0,0 +1,72 @@
+package main
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Lists start after their title and a blank line
+const listFirstRow = 2
+
+// Mouse position relative to the first line of the box contents. The
+// box is only at a known place when centered in the alternate screen,
+// otherwise the position is outside of the box so that clicks miss.
+func (m model) boxCoordinates(msg tea.MouseMsg) tea.MouseMsg {
+	if !m.isInAltscreen || currentLayout == compactLayout {
+		msg.X, msg.Y = -1, -1
+		return msg
+	}
+	const border = 1
+	contentWidth := layoutWidth()
+	contentHeight := totalBoxHeight + 2*border + statusBarHeight
+	if currentLayout == fullLayout {
+		contentHeight += tickerHeight
+	}
+	left := max(m.width-contentWidth, 0) / 2
+	top := max(m.height-contentHeight, 0) / 2
+	msg.X -= left + border + horizontalPadding
+	msg.Y -= top + border + verticalPadding
+	return msg
+}
+
+func isClick(msg tea.MouseMsg) bool {
+	return msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft
+}
+
+// Row of a list under a click, rows start firstRow lines into the box
+func clickedRow(msg tea.MouseMsg, firstRow int, rows int) (int, bool) {
+	row := msg.Y - firstRow
+	if !isClick(msg) || msg.X < 0 || msg.X >= boxWidth || row < 0 || row >= rows {
+		return 0, false
+	}
+	return row, true
+}
+
+// Moves the selection of a menu, clicking the
+// selected entry once more opens it
+func (screen *menuSelection) handleMouse(msg tea.MouseMsg, firstRow int, count int) (open bool) {
+	switch msg.Button {
+	case tea.MouseButtonWheelDown:
+		screen.selected = min(screen.selected+1, count-1)
+	case tea.MouseButtonWheelUp:
+		screen.selected = max(screen.selected-1, 0)
+	}
+	row, clicked := clickedRow(msg, firstRow, count)
+	if !clicked {
+		return false
+	}
+	if row == screen.selected {
+		return true
+	}
+	screen.selected = row
+	return false
+}
+
+func (position *listPosition) handleMouse(msg tea.MouseMsg, total int, shownRows int) {
+	switch msg.Button {
+	case tea.MouseButtonWheelDown:
+		position.scrollDown(total, shownRows)
+	case tea.MouseButtonWheelUp:
+		position.scrollUp()
+	}
+	if row, clicked := clickedRow(msg, listFirstRow, min(shownRows, total-position.firstShownIndex)); clicked {
+		position.selectedRow = row
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Selects the entry at the index, scrolling as little as possible
+func (position *listPosition) jumpTo(index int, total int, shownRows int) {
+	if total == 0 {
+		return
+	}
+	index = min(max(index, 0), total-1)
+	if index < position.firstShownIndex {
+		position.firstShownIndex = index
+	} else if index >= position.firstShownIndex+shownRows {
+		position.firstShownIndex = index - shownRows + 1
+	}
+	position.selectedRow = index - position.firstShownIndex
+}
+
+// Moves both the view and the selection, as paging does
+func (position *listPosition) scrollBy(delta int, total int, shownRows int) {
+	index := position.selectedIndex() + delta
+	position.firstShownIndex = min(max(position.firstShownIndex+delta, 0), max(total-shownRows, 0))
+	position.jumpTo(index, total, shownRows)
+}
+
+// Vim style count prefix and the two keys of gg,
+// kept between key presses of a list screen
+type listNavigation struct {
+	count      int
+	pendingTop bool
+}
+
+// Returns false for keys which are not navigation,
+// these also cancel a count typed so far
+func (navigation *listNavigation) handleKey(msg tea.KeyMsg, position *listPosition, total int, shownRows int) bool {
+	if digit := msg.String(); len(digit) == 1 && digit[0] >= '0' && digit[0] <= '9' && (digit != "0" || navigation.count > 0) {
+		navigation.count = min(navigation.count*10+int(digit[0]-'0'), total)
+		return true
+	}
+	counted := navigation.count > 0
+	count := max(navigation.count, 1)
+	pendingTop := navigation.pendingTop
+	*navigation = listNavigation{}
+	switch {
+	case key.Matches(msg, keys.Down):
+		for range count {
+			position.scrollDown(total, shownRows)
+		}
+	case key.Matches(msg, keys.Up):
+		for range count {
+			position.scrollUp()
+		}
+	case key.Matches(msg, keys.Top):
+		// Letters have to be pressed twice, as in vim
+		if isPrintableKey(msg.String()) && !pendingTop {
+			navigation.pendingTop = true
+			return true
+		}
+		position.jumpTo(0, total, shownRows)
+	case key.Matches(msg, keys.Bottom):
+		// With a count it goes to that entry instead
+		if counted {
+			position.jumpTo(count-1, total, shownRows)
+		} else {
+			position.jumpTo(total-1, total, shownRows)
+		}
+	case key.Matches(msg, keys.HalfPageDown):
+		position.scrollBy(count*max(shownRows/2, 1), total, shownRows)
+	case key.Matches(msg, keys.HalfPageUp):
+		position.scrollBy(-count*max(shownRows/2, 1), total, shownRows)
+	case key.Matches(msg, keys.PageDown):
+		position.scrollBy(count*shownRows, total, shownRows)
+	case key.Matches(msg, keys.PageUp):
+		position.scrollBy(-count*shownRows, total, shownRows)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Nested as Notes.formClue.verb like the mistakes file
+type notesTOML struct {
+	Notes map[string]map[string]string
+}
+
+// Notes of the user by prompt, none until the session is loaded
+var promptNotes = map[prompt]string{}
+
+func packNotes(notes map[prompt]string) notesTOML {
+	nested := make(map[string]map[string]string)
+	for prompt, note := range notes {
+		if _, exists := nested[prompt.FormClue]; !exists {
+			nested[prompt.FormClue] = make(map[string]string)
+		}
+		nested[prompt.FormClue][prompt.Verb] = note
+	}
+	return notesTOML{nested}
+}
+
+func parseNotes(bytes []byte) (map[prompt]string, error) {
+	var notesTOML notesTOML
+	if err := toml.Unmarshal(bytes, &notesTOML); err != nil {
+		return nil, err
+	}
+	notes := make(map[prompt]string)
+	for formClue, verbs := range notesTOML.Notes {
+		for verb, note := range verbs {
+			notes[prompt{FormClue: formClue, Verb: verb}] = note
+		}
+	}
+	return notes, nil
+}
+
+// Nil when the file can not be parsed, so that it is not overwritten
+func readNotes() map[prompt]string {
+	bytes, err := os.ReadFile(notesPath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Error("Failed to read notes file", "path", notesPath, "error", err)
+		}
+		return map[prompt]string{}
+	}
+	notes, err := parseNotes(bytes)
+	if err != nil {
+		slog.Error("Failed to parse notes file", "path", notesPath, "error", err)
+		return nil
+	}
+	return notes
+}
+
+// Empty note removes the one the prompt had. The file is read again
+// before writing, another session may have changed it meanwhile.
+func saveNote(prompt prompt, note string) error {
+	notes := readNotes()
+	if notes == nil {
+		return errors.New("notes file can not be parsed")
+	}
+	if note == "" {
+		delete(notes, prompt)
+	} else {
+		notes[prompt] = note
+	}
+	promptNotes = notes
+	if readOnly {
+		return nil
+	}
+	bytes, err := toml.Marshal(packNotes(notes))
+	if err != nil {
+		logFatal("Unachievable TOML encoding error", "error", err)
+	}
+	return writeFileAtomic(notesPath, bytes, 0)
+}
+
+func (screen quizScreen) renderNoteRow() string {
+	note := promptNotes[screen.question.prompt]
+	if note == "" {
+		return ""
+	}
+	// Cut to one row, the quiz has no room for more
+	return questionStatsAlignStyle.Render(questionStatsStyle.MaxWidth(boxWidth).Render("note: " + note))
+}
+
+// Edits the note of one prompt, opened while checking the answer
+type noteScreen struct {
+	prompt prompt
+	input  textinput.Model
+}
+
+func newNoteScreen(prompt prompt) noteScreen {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "mnemonic, similar verb, anything"
+	input.SetValue(promptNotes[prompt])
+	input.CursorEnd()
+	input.Focus()
+	return noteScreen{prompt: prompt, input: input}
+}
+
+func (screen noteScreen) isTyping() bool {
+	return true
+}
+
+func (screen noteScreen) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (screen noteScreen) save() (tea.Model, tea.Cmd) {
+	note := strings.TrimSpace(screen.input.Value())
+	if err := saveNote(screen.prompt, note); err != nil {
+		slog.Error("Failed to save note", "prompt", screen.prompt, "error", err)
+		notify("note not saved")
+		return screen, tea.Batch(popScreen, expireToastLater())
+	}
+	slog.Info("Saved note", "prompt", screen.prompt)
+	if note == "" {
+		notify("note removed")
+	} else {
+		notify("note saved")
+	}
+	return screen, tea.Batch(popScreen, expireToastLater())
+}
+
+func (screen noteScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Submit):
+			return screen.save()
+		case key.Matches(msg, keys.Menu):
+			return screen, popScreen
+		}
+	}
+	var cmd tea.Cmd
+	screen.input, cmd = screen.input.Update(msg)
+	return screen, cmd
+}
+
+var noteScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit}, action: "save"},
+	{bindings: []*key.Binding{&keys.Menu}, action: "cancel"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen noteScreen) View() string {
+	screen.input.Width = boxWidth - lipgloss.Width(screen.input.Prompt) - 1
+	body := lipgloss.JoinVertical(
+		lipgloss.Left,
+		statsTitleStyle.Render("Note"),
+		"",
+		promptStatsEntryStyle.Width(boxWidth).Render(screen.prompt.String()),
+		"",
+		screen.input.View(),
+		"",
+		questionStatsStyle.Render("Leave empty to remove the note."),
+	)
+	return renderBox(body, renderHelpRow(noteScreenHelp[:]))
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// AppleScript string literals are quoted the way Go quotes them
+func notificationCommand(title string, text string) *exec.Cmd {
+	script := "display notification " + strconv.Quote(text) + " with title " + strconv.Quote(title)
+	return exec.Command("osascript", "-e", script)
+}
@@ -0,0 +1,9 @@
+//go:build !windows && !darwin
+
+package main
+
+import "os/exec"
+
+func notificationCommand(title string, text string) *exec.Cmd {
+	return exec.Command("notify-send", "--app-name=gem2", title, text)
+}
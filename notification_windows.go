@@ -0,0 +1,22 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// Balloon of a tray icon, which needs nothing beyond PowerShell itself
+const notificationScript = `Add-Type -AssemblyName System.Windows.Forms
+$icon = New-Object System.Windows.Forms.NotifyIcon
+$icon.Icon = [System.Drawing.SystemIcons]::Information
+$icon.Visible = $true
+$icon.ShowBalloonTip(10000, '%TITLE%', '%TEXT%', 'Info')
+Start-Sleep -Seconds 10
+$icon.Dispose()`
+
+// Single quotes are the only character special in single quoted strings
+func notificationCommand(title string, text string) *exec.Cmd {
+	quote := func(s string) string { return strings.ReplaceAll(s, "'", "''") }
+	script := strings.NewReplacer("%TITLE%", quote(title), "%TEXT%", quote(text)).Replace(notificationScript)
+	return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+}
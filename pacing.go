@@ -0,0 +1,73 @@
+package main
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+var (
+	// Answers after which the quiz ends, 0 goes on until quitting
+	sessionLength int
+	// Answers after which progress is saved without leaving the quiz,
+	// 0 saves only when another screen is opened or when quitting
+	autosaveEvery int
+)
+
+// Offered by the settings screen, any other number can be given as a flag
+var (
+	sessionLengthChoices = [...]int{0, 10, 20, 50, 100}
+	autosaveChoices      = [...]int{0, 1, 5, 10, 25}
+)
+
+// Values not among the choices start over from the first one
+func cycleChoice[T comparable](value *T, choices []T) {
+	for i, choice := range choices {
+		if choice == *value {
+			*value = choices[(i+1)%len(choices)]
+			return
+		}
+	}
+	*value = choices[0]
+}
+
+func formatAnswerCount(count int) string {
+	if count == 0 {
+		return "off"
+	}
+	return strconv.Itoa(count) + " answers"
+}
+
+func (screen quizScreen) reachedSessionLength() bool {
+	return sessionLength > 0 && int(screen.answers()) >= sessionLength
+}
+
+// Questions left on the again pile are asked before the session ends
+func (screen quizScreen) isSessionComplete() bool {
+	return screen.reachedSessionLength() && len(screen.againPile) == 0
+}
+
+func (screen quizScreen) renderSessionLength() string {
+	if sessionLength == 0 {
+		return ""
+	}
+	return " of " + strconv.Itoa(sessionLength)
+}
+
+// A failed autosave is tried again with the next answer,
+// and leaving the quiz reports it if it keeps failing
+func (screen *quizScreen) autosave() {
+	if autosaveEvery == 0 || screen.unsavedAnswers < autosaveEvery {
+		return
+	}
+	if err := screen.saveQuietly(); err != nil {
+		slog.Error("Autosave failed", "error", err)
+		notify("autosave failed")
+	}
+}
+
+func formatAutosave() string {
+	if autosaveEvery == 0 {
+		return "off"
+	}
+	return "every " + formatAnswerCount(autosaveEvery)
+}
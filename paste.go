@@ -0,0 +1,18 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Answers have to be typed, an answer pasted from the validation
+// of an earlier question would count as knowing it. Bracketed
+// paste comes as a single message, and ctrl+v of the input
+// field, which reads the clipboard, is turned off.
+func isPaste(msg tea.Msg) bool {
+	key, isKey := msg.(tea.KeyMsg)
+	return isKey && key.Paste
+}
+
+func rejectPaste() {
+	notify("answers can not be pasted")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// Locations of data files given on the command line or in
+// the environment, empty ones keep the default location
+type pathOptions struct {
+	deck     string
+	stats    string
+	mistakes string
+	log      string
+}
+
+// Environment variables provide the defaults,
+// so that flags still win over them
+func (paths *pathOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&paths.deck, "deck", os.Getenv("GEM2_DECK"), "word database `file`, also GEM2_DECK")
+	flags.StringVar(&paths.stats, "stats", os.Getenv("GEM2_STATS"), "statistics `file`, also GEM2_STATS")
+	flags.StringVar(&paths.mistakes, "mistakes", os.Getenv("GEM2_MISTAKES"), "mistakes `file`, also GEM2_MISTAKES")
+	flags.StringVar(&paths.log, "log", os.Getenv("GEM2_LOG"), "log `file`, also GEM2_LOG")
+}
+
+// History, notes, achievements and the lock file are kept next to the statistics,
+// so that separate statistics files do not share them
+func (paths pathOptions) apply() {
+	if paths.deck != "" {
+		wordDatabasePath = paths.deck
+	}
+	if paths.stats != "" {
+		directory := filepath.Dir(paths.stats)
+		statisticsPath = paths.stats
+		historyPath = filepath.Join(directory, filepath.Base(historyPath))
+		notesPath = filepath.Join(directory, filepath.Base(notesPath))
+		achievementsPath = filepath.Join(directory, filepath.Base(achievementsPath))
+		lockPath = filepath.Join(directory, filepath.Base(lockPath))
+	}
+	if paths.mistakes != "" {
+		mistakesPath = paths.mistakes
+	}
+	slog.Debug(
+		"Using data files",
+		"deck", wordDatabasePath,
+		"statistics", statisticsPath,
+		"mistakes", mistakesPath,
+		"history", historyPath,
+		"notes", notesPath,
+		"achievements", achievementsPath,
+	)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"iter"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Characters beyond ASCII for keyboard layouts lacking them,
+// shown in place of question statistics while open
+type characterPicker struct {
+	open       bool
+	characters []rune
+	selected   int
+}
+
+// Letters of the answers in the deck which are not on a US keyboard
+func (statistics statisticsDatabase) specialCharacters() []rune {
+	return specialCharactersOf(func(yield func(string) bool) {
+		for _, answer := range statistics.Answers() {
+			if !yield(answer) {
+				return
+			}
+		}
+	})
+}
+
+func specialCharactersOf(answers iter.Seq[string]) []rune {
+	seen := make(map[rune]bool)
+	var characters []rune
+	for answer := range answers {
+		for _, character := range answer {
+			if character > unicode.MaxASCII && unicode.IsLetter(character) && !seen[character] {
+				seen[character] = true
+				characters = append(characters, character)
+			}
+		}
+	}
+	slices.SortFunc(characters, func(a, b rune) int { return compareText(string(a), string(b)) })
+	return characters
+}
+
+func (screen *quizScreen) togglePicker() {
+	if screen.picker.open {
+		screen.picker.open = false
+		return
+	}
+	screen.picker = characterPicker{open: true, characters: screen.statistics.specialCharacters()}
+}
+
+// Inserted at the cursor, the limit of the input field still applies
+func (screen *quizScreen) insertCharacter(character rune) {
+	value := []rune(screen.inputField.Value())
+	if screen.inputField.CharLimit > 0 && len(value) >= screen.inputField.CharLimit {
+		return
+	}
+	position := screen.inputField.Position()
+	value = append(value[:position], append([]rune{character}, value[position:]...)...)
+	screen.inputField.SetValue(string(value))
+	screen.inputField.SetCursor(position + 1)
+}
+
+func (screen quizScreen) pickerUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, isKey := msg.(tea.KeyMsg)
+	if !isKey {
+		return screen, nil
+	}
+	picker := &screen.picker
+	switch {
+	case key.Matches(keyMsg, keys.Picker, keys.Back):
+		picker.open = false
+	case len(picker.characters) == 0:
+		return screen, nil
+	case key.Matches(keyMsg, keys.Right):
+		picker.selected = (picker.selected + 1) % len(picker.characters)
+	case key.Matches(keyMsg, keys.Left):
+		picker.selected = (picker.selected + len(picker.characters) - 1) % len(picker.characters)
+	case key.Matches(keyMsg, keys.Submit):
+		screen.insertCharacter(picker.characters[picker.selected])
+		picker.open = false
+	}
+	return screen, nil
+}
+
+func (screen quizScreen) renderPicker() string {
+	if len(screen.picker.characters) == 0 {
+		return questionStatsAlignStyle.Render(questionStatsStyle.Render("no special characters in the deck"))
+	}
+	// Characters are a cell apart, as many as fit are
+	// shown with the selected one kept in the window
+	characters := screen.picker.characters
+	first, end := characterWindow(characters, screen.picker.selected, boxWidth)
+	rendered := make([]string, end-first)
+	for i, character := range characters[first:end] {
+		style := questionStyle
+		if first+i == screen.picker.selected {
+			style = style.Bold(true).Reverse(true)
+		}
+		rendered[i] = style.Render(string(character))
+	}
+	return questionStatsAlignStyle.Render(strings.Join(rendered, background.Render(" ")))
+}
+
+var pickerHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Left, &keys.Right}, action: "move"},
+	{bindings: []*key.Binding{&keys.Submit}, action: "insert"},
+	{bindings: []*key.Binding{&keys.Picker}, action: "close"},
+}
@@ -0,0 +1,30 @@
+package main
+
+// Questions pinned for the session which are still in the deck,
+// asked first whatever the schedule says, suspended ones too
+func (statistics statisticsDatabase) pinnedPrompts() []prompt {
+	var pinned []prompt
+	for _, prompt := range statistics.Pinned {
+		if _, exists := statistics.Lookup(prompt); exists {
+			pinned = append(pinned, prompt)
+		}
+	}
+	return pinned
+}
+
+// Pinning during the session is for the next one,
+// the question is not added to the pinned queue
+func (statistics *statisticsDatabase) togglePin(prompt prompt) {
+	if statistics.TogglePin(prompt) {
+		notify("pinned for the next session")
+	} else {
+		notify("unpinned")
+	}
+}
+
+// Answered, a pinned question is done with, unless pinned again
+func (screen *quizScreen) unpinAnswered() {
+	if screen.pinnedQuestion {
+		screen.statistics.Unpin(screen.question.prompt)
+	}
+}
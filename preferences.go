@@ -0,0 +1,147 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+// How the UI was left on the last run, restored on the next one
+// unless flags given explicitly ask for something else
+type uiPreferences struct {
+	AltScreen bool
+	Theme     string
+	Layout    string
+	Deck      string
+	Screen    string
+	// Flag values of the settings screen entries
+	Settings map[string]string
+}
+
+var defaultPreferences = uiPreferences{AltScreen: true, Layout: "box", Screen: "home"}
+
+// Empty when there is nowhere to keep it
+func preferencesPath() string {
+	state := stateDirectory()
+	if state == "" {
+		return ""
+	}
+	return filepath.Join(state, "ui.toml")
+}
+
+// Preferences are a convenience, so problems
+// with them only mean starting with defaults
+func loadPreferences() uiPreferences {
+	preferences, err := store.loadPreferences()
+	if err != nil {
+		slog.Error("Failed to load UI preferences", "error", err)
+	}
+	return preferences
+}
+
+// Another instance may be saving its own, so read-only mode keeps them
+func (preferences uiPreferences) save() {
+	if readOnly {
+		return
+	}
+	if err := store.savePreferences(preferences); err != nil {
+		slog.Error("Failed to save UI preferences", "error", err)
+	}
+}
+
+func explicitFlags(flags *flag.FlagSet) map[string]bool {
+	explicit := make(map[string]bool)
+	flags.Visit(func(flag *flag.Flag) { explicit[flag.Name] = true })
+	return explicit
+}
+
+// Deck is only restored when neither a flag nor the environment
+// names one, a remembered deck which is gone is forgotten
+func (preferences uiPreferences) applyDeck(paths pathOptions) {
+	if paths.deck == "" && preferences.Deck != "" && fileExists(preferences.Deck) {
+		wordDatabasePath = preferences.Deck
+	}
+}
+
+// Applied after the display flags, a remembered theme
+// which no longer exists keeps the default one
+func (preferences uiPreferences) applyDisplay(explicit map[string]bool) {
+	if !explicit["theme"] && preferences.Theme != "" {
+		if err := useTheme(preferences.Theme); err != nil {
+			slog.Warn("Remembered theme is not available", "error", err)
+		}
+	}
+	if !explicit["layout"] {
+		if err := currentLayout.Set(preferences.Layout); err != nil {
+			slog.Warn("Remembered layout is not valid", "error", err)
+		}
+	}
+}
+
+// Applied right after parsing, so remembered values are
+// checked like the flags, an invalid one is forgotten
+func (preferences uiPreferences) applySettings(flags *flag.FlagSet, explicit map[string]bool) {
+	for name, value := range preferences.Settings {
+		if explicit[name] || flags.Lookup(name) == nil {
+			continue
+		}
+		if err := flags.Set(name, value); err != nil {
+			slog.Warn("Remembered setting is not valid", "flag", name, "value", value, "error", err)
+		}
+	}
+}
+
+func currentSettings(flags *flag.FlagSet) map[string]string {
+	settings := make(map[string]string)
+	for _, entry := range settingsEntries {
+		if entry.flag != "" {
+			settings[entry.flag] = flags.Lookup(entry.flag).Value.String()
+		}
+	}
+	return settings
+}
+
+func currentPreferences(m model) uiPreferences {
+	deck, err := filepath.Abs(wordDatabasePath)
+	if err != nil {
+		deck = wordDatabasePath
+	}
+	return uiPreferences{
+		AltScreen: m.isInAltscreen,
+		Theme:     currentThemeName,
+		Layout:    currentLayout.String(),
+		Deck:      deck,
+		Screen:    m.lastScreen,
+	}
+}
+
+// Screens opened from the home screen may be where the next run starts
+func isRestorableScreen(mode string) bool {
+	if mode == "home" {
+		return true
+	}
+	_, exists := restorableEntry(mode)
+	return exists
+}
+
+func restorableEntry(mode string) (homeEntry, bool) {
+	for _, entry := range homeEntries {
+		if entry.restorable && strings.ToLower(entry.title) == mode {
+			return entry, true
+		}
+	}
+	return homeEntry{}, false
+}
+
+// Pending reconciliation of changed answers comes first
+func (m model) restoreScreen(mode string) model {
+	home, isHome := m.top().(homeScreen)
+	entry, exists := restorableEntry(mode)
+	if !isHome || !exists {
+		return m
+	}
+	m.push(entry.open(home))
+	m.lastScreen = mode
+	return m
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Moved into the state directory by useStandardDirectories
+var profilesDirectory = "profiles"
+
+// Empty for the default profile, which keeps
+// its files next to the word database
+var profileName = ""
+
+func validateProfileName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	if strings.ContainsAny(name, `/\:`) {
+		return fmt.Errorf("profile name %q must not contain path separators", name)
+	}
+	return nil
+}
+
+// Redirects all per-user files into the profile directory,
+// the word database stays shared between profiles
+func useProfile(name string) {
+	if err := validateProfileName(name); err != nil {
+		logFatal("Invalid profile", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(profileError)
+	}
+	directory := filepath.Join(profilesDirectory, name)
+	if !readOnly {
+		if err := os.MkdirAll(directory, 0755); err != nil {
+			logFatal("Failed to create profile directory", "path", directory, "error", err)
+			exit(profileError)
+		}
+	}
+	profileName = name
+	mistakesPath = filepath.Join(directory, filepath.Base(mistakesPath))
+	statisticsPath = filepath.Join(directory, filepath.Base(statisticsPath))
+	historyPath = filepath.Join(directory, filepath.Base(historyPath))
+	notesPath = filepath.Join(directory, filepath.Base(notesPath))
+	achievementsPath = filepath.Join(directory, filepath.Base(achievementsPath))
+	lockPath = filepath.Join(directory, filepath.Base(lockPath))
+	slog.Info("Using profile", "profile", name)
+}
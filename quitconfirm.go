@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Asked before quitting with answers not yet saved or questions
+// left on the again pile, quitting again saves them and exits
+type quitConfirmScreen struct {
+	session *session
+}
+
+// Failed save screen already says what quitting from it loses
+func (m model) needsQuitConfirmation() bool {
+	switch m.top().(type) {
+	case quitConfirmScreen, saveFailedScreen:
+		return false
+	}
+	return m.session.unsavedAnswers > 0 || len(m.session.againPile) > 0
+}
+
+func (screen quitConfirmScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen quitConfirmScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Submit):
+			return screen, requestQuit
+		case key.Matches(msg, keys.Back):
+			return screen, popScreen
+		}
+	}
+	return screen, nil
+}
+
+var quitConfirmHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit, &keys.Quit}, action: "save and quit"},
+	{bindings: []*key.Binding{&keys.Back}, action: "keep practicing"},
+}
+
+var readOnlyQuitConfirmHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit, &keys.Quit}, action: "quit"},
+	{bindings: []*key.Binding{&keys.Back}, action: "keep practicing"},
+}
+
+func (screen quitConfirmScreen) View() string {
+	unsavedAnswers := screen.session.unsavedAnswers
+	answers := "answers are"
+	if unsavedAnswers == 1 {
+		answers = "answer is"
+	}
+	lines := []string{statsTitleStyle.Render("Quit?"), ""}
+	footer := renderHelpRow(quitConfirmHelp[:])
+	if readOnly {
+		footer = renderHelpRow(readOnlyQuitConfirmHelp[:])
+	}
+	switch {
+	case unsavedAnswers > 0 && readOnly:
+		lines = append(lines, promptStatsEntryStyle.Width(boxWidth).Render(fmt.Sprintf("%d %s lost in read-only mode.", unsavedAnswers, answers)))
+	case unsavedAnswers > 0:
+		lines = append(lines, promptStatsEntryStyle.Width(boxWidth).Render(fmt.Sprintf("%d %s not saved yet.", unsavedAnswers, answers)))
+	}
+	if cards := len(screen.session.againPile); cards > 0 {
+		lines = append(lines, promptStatsEntryStyle.Width(boxWidth).Render(againPileDetail(cards)))
+	}
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), footer)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+// Names of the separators Quizlet offers when exporting a set,
+// anything else is taken as the separator itself
+var quizletSeparators = map[string]string{
+	"tab":       "\t",
+	"comma":     ",",
+	"semicolon": ";",
+	"newline":   "\n",
+}
+
+func quizletSeparator(value string) string {
+	if separator, named := quizletSeparators[strings.ToLower(value)]; named {
+		return separator
+	}
+	return value
+}
+
+type quizletCard struct {
+	term       string
+	definition string
+}
+
+// Blank cards, such as after the last one, are skipped. A card without the
+// term separator most likely means the separators are not the exported ones.
+func parseQuizletExport(text string, termSeparator string, cardSeparator string) ([]quizletCard, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var cards []quizletCard
+	for i, card := range strings.Split(text, cardSeparator) {
+		if strings.TrimSpace(card) == "" {
+			continue
+		}
+		term, definition, found := strings.Cut(card, termSeparator)
+		if !found {
+			return nil, fmt.Errorf("card %d has no term separator: %q", i+1, card)
+		}
+		cards = append(cards, quizletCard{strings.TrimSpace(term), strings.TrimSpace(definition)})
+	}
+	if len(cards) == 0 {
+		return nil, errors.New("there are no cards")
+	}
+	return cards, nil
+}
+
+// Terms become the verbs. Definitions are a single form of the clue, or
+// with a form separator, split into the forms of the clues in order.
+func quizletDeck(cards []quizletCard, clues []string, formSeparator string) (deck.Deck, error) {
+	converted := deck.Deck{FormClues: clues}
+	for i, card := range cards {
+		forms := []string{card.definition}
+		if formSeparator != "" {
+			forms = strings.Split(card.definition, formSeparator)
+			for j := range forms {
+				forms[j] = strings.TrimSpace(forms[j])
+			}
+		}
+		if len(forms) > len(clues) {
+			return deck.Deck{}, fmt.Errorf("card %d %q has %d forms, but there are only %d columns", i+1, card.term, len(forms), len(clues))
+		}
+		converted.Verbs = append(converted.Verbs, card.term)
+		converted.Forms = append(converted.Forms, forms)
+	}
+	return converted, nil
+}
+
+// Quizlet exports a set as text, a card per line by default
+// with a tab between the term and the definition
+func runQuizlet(args []string) {
+	flags, options := newFlagSet("quizlet", "export deck.xlsx")
+	termSeparator := flags.String("term-separator", "tab", "`separator` between term and definition: tab, comma or the separator itself")
+	cardSeparator := flags.String("card-separator", "newline", "`separator` between cards: newline, semicolon or the separator itself")
+	clue := flags.String("clue", "definition", "form clue `name` of the definitions, unless they are split into -columns")
+	columns := flags.String("columns", "", "comma separated form `clues` the definitions are split into, e.g. \"ich,du,er/sie/es\"")
+	formSeparator := flags.String("form-separator", "comma", "`separator` between the forms of a definition split into -columns")
+	parseFlags(flags, args)
+	expectArguments(flags, 2)
+	options.apply()
+	exportPath, deckPath := flags.Arg(0), flags.Arg(1)
+	if fileExists(deckPath) {
+		fmt.Fprintf(os.Stderr, "%s already exists, the deck is not written over\n", deckPath)
+		exit(usageError)
+	}
+
+	text, err := os.ReadFile(exportPath)
+	if err != nil {
+		logFatal("Failed to read Quizlet export", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(databaseError)
+	}
+	cards, err := parseQuizletExport(string(text), quizletSeparator(*termSeparator), quizletSeparator(*cardSeparator))
+	if err != nil {
+		logFatal("Failed to parse Quizlet export", "error", err)
+		fmt.Fprintf(os.Stderr, "%s: %v\n", exportPath, err)
+		exit(databaseError)
+	}
+	clues, separator := []string{*clue}, ""
+	if *columns != "" {
+		clues, separator = strings.Split(*columns, ","), quizletSeparator(*formSeparator)
+		for i := range clues {
+			clues[i] = strings.TrimSpace(clues[i])
+		}
+	}
+	converted, err := quizletDeck(cards, clues, separator)
+	if err == nil {
+		err = deck.Write(deckPath, converted)
+	}
+	if err != nil {
+		logFatal("Failed to convert Quizlet export", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(databaseError)
+	}
+	slog.Info("Converted Quizlet export", "cards", len(cards), "clues", len(clues), "path", deckPath)
+	fmt.Printf("Wrote %d verbs to %s, practice them with: gem2 -deck %s\n", len(cards), deckPath, deckPath)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+
+	"github.com/kligunov-id/gem2/stats"
+)
+
+// Shown on startup when answers were edited in the word database,
+// lets the user reset statistics of the questions that really changed
+type reconciliationScreen struct {
+	next       tea.Model
+	statistics *statisticsDatabase
+	changes    []stats.AnswerChange
+	reset      []bool
+	listPosition
+}
+
+func newReconciliationScreen(next tea.Model, statistics *statisticsDatabase) reconciliationScreen {
+	changes := make([]stats.AnswerChange, len(statistics.ChangedAnswers))
+	copy(changes, statistics.ChangedAnswers)
+	sort.Slice(changes, func(i, j int) bool {
+		return comparePrompts(changes[i].Prompt, changes[j].Prompt) < 0
+	})
+	return reconciliationScreen{
+		next:       next,
+		statistics: statistics,
+		changes:    changes,
+		reset:      make([]bool, len(changes)),
+	}
+}
+
+func (screen reconciliationScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen reconciliationScreen) apply() {
+	resetCount := 0
+	for i, change := range screen.changes {
+		if screen.reset[i] {
+			screen.statistics.ResetStats(change.Prompt)
+			resetCount++
+		}
+	}
+	screen.statistics.ChangedAnswers = nil
+	slog.Info(
+		"Reconciled questions with changed answers",
+		"reset", resetCount,
+		"kept", len(screen.changes)-resetCount,
+	)
+}
+
+func (screen reconciliationScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Down):
+			screen.scrollDown(len(screen.changes), listShownRows())
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.scrollUp()
+			return screen, nil
+		case key.Matches(msg, keys.Toggle):
+			// Slice is shared between copies of the screen,
+			// so the copy has to be made before modifying it
+			reset := make([]bool, len(screen.reset))
+			copy(reset, screen.reset)
+			reset[screen.selectedIndex()] = !reset[screen.selectedIndex()]
+			screen.reset = reset
+			return screen, nil
+		case key.Matches(msg, keys.Submit):
+			screen.apply()
+			return screen, replaceScreen(screen.next)
+		}
+	}
+	return screen, nil
+}
+
+func (screen reconciliationScreen) renderChangeEntry(index int, selected bool) string {
+	change := screen.changes[index]
+	decision := background.Bold(selected).Foreground(textColor).Render("keep")
+	if screen.reset[index] {
+		decision = background.Bold(selected).Foreground(wrongColor).Render("reset")
+	}
+	entry := fmt.Sprintf(
+		"%s: %s %s %s",
+		change.Prompt,
+		change.OldAnswer,
+		symbols.arrow,
+		screen.statistics.Answer(change.Prompt),
+	)
+	if selected {
+		entry = "> " + entry
+	}
+	return promptStatsEntryStyle.
+		Bold(selected).
+		Italic(selected).
+		Width(boxWidth-lipgloss.Width(decision)).
+		MaxWidth(boxWidth-lipgloss.Width(decision)).
+		AlignHorizontal(lipgloss.Left).
+		Render(entry) +
+		decision
+}
+
+var reconciliationScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Down, &keys.Up}, action: "move"},
+	{bindings: []*key.Binding{&keys.Toggle}, action: "keep/reset"},
+	{bindings: []*key.Binding{&keys.Submit}, action: "done"},
+}
+
+func (screen reconciliationScreen) View() string {
+	screen.fit(listShownRows())
+	footer := renderHelpRow(reconciliationScreenHelp[:])
+	lines := []string{statsTitleStyle.Render("Answers changed in the word database"), ""}
+	for row := 0; row < listShownRows(); row++ {
+		index := screen.firstShownIndex + row
+		if index >= len(screen.changes) {
+			break
+		}
+		lines = append(lines, screen.renderChangeEntry(index, row == screen.selectedRow))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	footer = lipgloss.JoinVertical(
+		lipgloss.Left,
+		questionStatsAlignStyle.Render(questionStatsStyle.Render("statistics are kept unless reset")),
+		footer,
+	)
+	return renderBox(body, footer)
+}
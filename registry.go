@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+// Index of community decks, another one can be given with -registry
+const defaultRegistry = "https://raw.githubusercontent.com/kligunov-id/gem2-decks/main/index.json"
+
+const (
+	registryTimeout = time.Minute
+	// Far above any spreadsheet of verbs, only guards against a wrong URL
+	maxDeckSize = 64 << 20
+)
+
+// The index is a JSON manifest of the decks, their URLs
+// may be relative to the URL of the index itself
+type registryIndex struct {
+	Decks []registryDeck `json:"decks"`
+}
+
+type registryDeck struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	SHA256      string `json:"sha256"`
+}
+
+func decksDirectory() string {
+	if data := dataDirectory(); data != "" {
+		return filepath.Join(data, "decks")
+	}
+	return "decks"
+}
+
+// Names become file names, so they must not lead out of the decks directory
+func validDeckName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\:`)
+}
+
+func (index registryIndex) find(name string) (registryDeck, bool) {
+	for _, entry := range index.Decks {
+		if strings.EqualFold(entry.Name, name) {
+			return entry, true
+		}
+	}
+	return registryDeck{}, false
+}
+
+func download(ctx context.Context, address string, limit int64) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", address, response.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(response.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s: larger than %d bytes", address, limit)
+	}
+	return data, nil
+}
+
+func fetchRegistry(ctx context.Context, address string) (registryIndex, error) {
+	data, err := download(ctx, address, maxDeckSize)
+	if err != nil {
+		return registryIndex{}, err
+	}
+	var index registryIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return registryIndex{}, fmt.Errorf("%s: %w", address, err)
+	}
+	return index, nil
+}
+
+// Checked against the index before anything is written
+func (entry registryDeck) verify(data []byte) error {
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), entry.SHA256) {
+		return fmt.Errorf("checksum of %s does not match the index", entry.Name)
+	}
+	return nil
+}
+
+// The downloaded file has to read as a deck before it
+// replaces an earlier download of the same deck
+func installDeck(entry registryDeck, data []byte) (string, error) {
+	directory := decksDirectory()
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(directory, entry.Name+".xlsx")
+	temp := filepath.Join(directory, entry.Name+".download.xlsx")
+	defer os.Remove(temp) // No-op once renamed
+	if err := writeFileAtomic(temp, data, 0); err != nil {
+		return "", err
+	}
+	if _, err := deck.Read(temp); err != nil {
+		return "", fmt.Errorf("%s is not a valid deck: %w", entry.Name, err)
+	}
+	return path, os.Rename(temp, path)
+}
+
+func getDeck(ctx context.Context, registry *url.URL, entry registryDeck) (string, error) {
+	reference, err := url.Parse(entry.URL)
+	if err != nil {
+		return "", err
+	}
+	data, err := download(ctx, registry.ResolveReference(reference).String(), maxDeckSize)
+	if err != nil {
+		return "", err
+	}
+	if err := entry.verify(data); err != nil {
+		return "", err
+	}
+	return installDeck(entry, data)
+}
+
+func printRegistry(index registryIndex) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, entry := range index.Decks {
+		fmt.Fprintf(writer, "%s\t%s\n", entry.Name, entry.Description)
+	}
+	writer.Flush()
+}
+
+// Lists the decks of the registry, or installs the named one,
+// getting it again replaces the earlier download with its update
+func runGet(args []string) {
+	flags, options := newFlagSet("get", "[name]")
+	registry := flags.String("registry", os.Getenv("GEM2_REGISTRY"), "`URL` of the deck index, also GEM2_REGISTRY")
+	parseFlags(flags, args)
+	if flags.NArg() > 1 {
+		expectArguments(flags, 1)
+	}
+	options.apply()
+	if *registry == "" {
+		*registry = defaultRegistry
+	}
+	base, err := url.Parse(*registry)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid registry URL: %v\n", err)
+		exit(usageError)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+	index, err := fetchRegistry(ctx, *registry)
+	if err != nil {
+		logFatal("Failed to fetch deck registry", "registry", *registry, "error", err)
+		fmt.Fprintf(os.Stderr, "Failed to fetch deck registry: %v\n", err)
+		exit(downloadError)
+	}
+	if flags.NArg() == 0 {
+		printRegistry(index)
+		return
+	}
+	entry, found := index.find(flags.Arg(0))
+	if !found {
+		fmt.Fprintf(os.Stderr, "There is no deck %q, run gem2 get to list them\n", flags.Arg(0))
+		exit(usageError)
+	}
+	if !validDeckName(entry.Name) {
+		fmt.Fprintf(os.Stderr, "Deck name %q of the registry is not a valid file name\n", entry.Name)
+		exit(downloadError)
+	}
+	path, err := getDeck(ctx, base, entry)
+	if err != nil {
+		logFatal("Failed to install deck", "name", entry.Name, "error", err)
+		fmt.Fprintf(os.Stderr, "Failed to install %s: %v\n", entry.Name, err)
+		exit(downloadError)
+	}
+	slog.Info("Installed deck", "name", entry.Name, "path", path)
+	fmt.Printf("Installed %s, practice it with: gem2 -deck %s\n", entry.Name, path)
+}
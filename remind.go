@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Text of the reminder, empty when there is nothing to remind of: the daily
+// goal is met, or without a goal, something was practiced today or nothing
+// is due and weak
+func reminderText(statistics statisticsDatabase, history practiceHistory, now time.Time) string {
+	answered := history.day(now).answered()
+	due := statistics.dueSummary(now)
+	questions := fmt.Sprintf("%d questions due, %d weak", due.DueNow, due.Weak)
+	if habits.DailyGoal == 0 {
+		if answered > 0 || due.DueNow+due.Weak == 0 {
+			return ""
+		}
+		return "Not practiced today yet, " + questions
+	}
+	if answered >= habits.DailyGoal {
+		return ""
+	}
+	return fmt.Sprintf("%d of %d answers of the daily goal, %s", answered, habits.DailyGoal, questions)
+}
+
+// Meant to be run by cron or a systemd timer, so it stays quiet unless
+// there is something to remind of. The notifier needs the graphical
+// session, under cron e.g. DBUS_SESSION_BUS_ADDRESS for notify-send.
+func runRemind(args []string) {
+	flags, options := newFlagSet("remind", "")
+	printOnly := flags.Bool("print", false, "print the reminder instead of showing a desktop notification")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	options.readsOnly = true
+	options.apply()
+	if err := loadHabits(); err != nil {
+		logFatal("Failed to load habit settings", "error", err)
+		fmt.Fprintf(os.Stderr, "Failed to load habit settings: %v\n", err)
+		exit(usageError)
+	}
+	database := read_database()
+	statistics := database.loadStatistics()
+	history := loadHistory()
+	text := reminderText(statistics, history, time.Now())
+	if text == "" {
+		slog.Info("Nothing to remind of")
+		return
+	}
+	if *printOnly {
+		fmt.Println(text)
+		return
+	}
+	if output, err := notificationCommand("gem2", text).CombinedOutput(); err != nil {
+		logFatal("Failed to show notification", "error", err, "output", string(output))
+		fmt.Fprintf(os.Stderr, "Failed to show notification: %v\n%s", err, output)
+		exit(notificationError)
+	}
+	slog.Info("Reminded", "text", text)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const worstPromptsShown = 10
+
+// Most mistakes first, questions never missed are left out
+func (statistics statisticsDatabase) worstPrompts(count int) []prompt {
+	var prompts []prompt
+	for prompt, stats := range statistics.All() {
+		if stats.Mistakes > 0 {
+			prompts = append(prompts, prompt)
+		}
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		a, b := statistics.Record(prompts[i]), statistics.Record(prompts[j])
+		if a.Mistakes != b.Mistakes {
+			return a.Mistakes > b.Mistakes
+		}
+		if a.Correct != b.Correct {
+			return a.Correct < b.Correct
+		}
+		return comparePrompts(prompts[i], prompts[j]) < 0
+	})
+	return prompts[:min(count, len(prompts))]
+}
+
+func runStats(args []string) {
+	flags, options := newFlagSet("stats", "")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	// Only reading here, an old statistics file is not migrated on disk
+	options.readsOnly = true
+	options.apply()
+	database := read_database()
+	useDeckSettingsOrExit(database.Settings, nil)
+	statistics := database.loadStatistics()
+	statistics.useDeckCollation()
+	history := loadHistory()
+	printStatisticsReport(statistics, history)
+}
+
+// Prints how many questions are due and exits with nothingDue
+// when there are none, so a shell prompt can test for it
+func runDue(args []string) {
+	flags, options := newFlagSet("due", "")
+	withinDay := flags.Bool("day", false, "also count questions becoming due by the end of the study day")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	options.readsOnly = true
+	options.apply()
+	database := read_database()
+	statistics := database.loadStatistics()
+	due := statistics.dueSummary(time.Now())
+	count := due.DueNow
+	if *withinDay {
+		count = due.DueWithinDay
+	}
+	fmt.Println(count)
+	if count == 0 {
+		exit(nothingDue)
+	}
+}
+
+func printStatisticsReport(statistics statisticsDatabase, history practiceHistory) {
+	summary := statistics.summary()
+	answered := summary.correct + summary.mistakes
+	fmt.Printf(
+		"Deck: %d questions, %d started, %d untouched\n",
+		summary.questions,
+		summary.started,
+		summary.questions-summary.started,
+	)
+	fmt.Printf("Mature: %d, mastered: %d\n", summary.mature, summary.mastered)
+	fmt.Printf(
+		"Lifetime: %d answered, %s correct\n",
+		answered,
+		formatPercentage(summary.correct, answered),
+	)
+	fmt.Printf("Best session streak: %d\n", statistics.BestSessionStreak)
+	fmt.Printf("Study time: %s\n", formatStudyTime(history.totalStudyTime()))
+	today := time.Now()
+	fmt.Println(formatAccuracy("Today", history.day(today), plain))
+	fmt.Println(formatAccuracy("Last 7 days", history.sumDays(today, rollingAccuracyDays), plain))
+	due := statistics.dueSummary(today)
+	fmt.Printf("Due: %d now, %d by the end of the day, %d new\n", due.DueNow, due.DueWithinDay, due.New)
+
+	worst := statistics.worstPrompts(worstPromptsShown)
+	if len(worst) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("Most missed questions:")
+	for _, prompt := range worst {
+		stats := statistics.Record(prompt)
+		fmt.Printf("%5d wrong %5d correct   %s\n", stats.Mistakes, stats.Correct, prompt)
+	}
+}
+
+func plain(text string) string {
+	return text
+}
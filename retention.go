@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kligunov-id/gem2/stats"
+)
+
+// How long records of questions missing from the deck are kept,
+// in days, for the question to come back with its progress when the
+// deck is edited back. Retired records are moved to a file of their own.
+type deadRecordRetention int
+
+const (
+	keepDeadRecords deadRecordRetention = 0
+	dropDeadRecords deadRecordRetention = -1
+)
+
+var deadRecordsRetention deadRecordRetention
+
+// Offered by the settings screen, any other number of days can be given as a flag
+var deadRecordsRetentionChoices = [...]deadRecordRetention{keepDeadRecords, 30, 90, 365, dropDeadRecords}
+
+func (retention deadRecordRetention) String() string {
+	switch retention {
+	case keepDeadRecords:
+		return "keep"
+	case dropDeadRecords:
+		return "drop"
+	}
+	return strconv.Itoa(int(retention))
+}
+
+// Makes it usable as a flag
+func (retention *deadRecordRetention) Set(value string) error {
+	switch value {
+	case "keep":
+		*retention = keepDeadRecords
+		return nil
+	case "drop":
+		*retention = dropDeadRecords
+		return nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		return fmt.Errorf("unknown value %q, available: keep, drop or a number of days", value)
+	}
+	*retention = deadRecordRetention(days)
+	return nil
+}
+
+func formatDeadRecordsRetention() string {
+	switch deadRecordsRetention {
+	case keepDeadRecords:
+		return "keep forever"
+	case dropDeadRecords:
+		return "drop at once"
+	}
+	return fmt.Sprintf("after %d days", deadRecordsRetention)
+}
+
+// Records which were never practiced are as old as can be
+func (retention deadRecordRetention) expired(record stats.RecordTOML, now time.Time) bool {
+	switch retention {
+	case keepDeadRecords:
+		return false
+	case dropDeadRecords:
+		return true
+	}
+	return now.Sub(record.LastPracticed) > time.Duration(retention)*24*time.Hour
+}
+
+// Next to the statistics, imported back with gem2 import
+func retiredRecordsPath() string {
+	return strings.TrimSuffix(statisticsPath, filepath.Ext(statisticsPath)) + ".retired.toml"
+}
+
+// Records retired earlier are kept, those retired again replace them
+func writeRetiredRecords(retired map[prompt]stats.RecordTOML) error {
+	path := retiredRecordsPath()
+	all := map[prompt]stats.RecordTOML{}
+	bytes, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		file, _, err := stats.Parse(bytes)
+		if err != nil {
+			return fmt.Errorf("could not parse retired records %s: %w", path, err)
+		}
+		all = file.PromptRecords()
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("could not read retired records %s: %w", path, err)
+	}
+	maps.Copy(all, retired)
+	encoded, err := stats.EncodeRecords(all)
+	if err != nil {
+		logFatal("Unachievable TOML encoding error", "error", err)
+		exit(internalError)
+	}
+	if err := writeFileAtomic(path, encoded, 0); err != nil {
+		return fmt.Errorf("could not write retired records %s: %w", path, err)
+	}
+	return nil
+}
+
+// Records stay in the statistics unless they were written out first,
+// nothing is lost when the retired records file can not be written
+func (statistics *statisticsDatabase) retireDeadRecords(now time.Time) {
+	if deadRecordsRetention == keepDeadRecords || readOnly {
+		return
+	}
+	retired := map[prompt]stats.RecordTOML{}
+	for prompt, record := range statistics.DeadRecords {
+		if deadRecordsRetention.expired(record, now) {
+			retired[prompt] = record
+		}
+	}
+	if len(retired) == 0 {
+		return
+	}
+	if err := writeRetiredRecords(retired); err != nil {
+		slog.Error("Failed to retire dead records, keeping them", "error", err)
+		return
+	}
+	for prompt := range retired {
+		delete(statistics.DeadRecords, prompt)
+	}
+	slog.Info("Retired dead records", "records", len(retired), "retention", deadRecordsRetention, "path", retiredRecordsPath())
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+var rightToLeftScripts = []*unicode.RangeTable{
+	unicode.Arabic,
+	unicode.Hebrew,
+	unicode.Syriac,
+	unicode.Thaana,
+	unicode.Nko,
+}
+
+// Direction is taken from the first letter, as bidi
+// algorithms do for text without explicit marks
+func isRightToLeft(text string) bool {
+	for _, character := range text {
+		if unicode.IsLetter(character) {
+			return unicode.In(character, rightToLeftScripts...)
+		}
+	}
+	return false
+}
+
+func (question question) isRightToLeft() bool {
+	return isRightToLeft(question.correctAnswer) ||
+		isRightToLeft(question.prompt.FormClue) ||
+		isRightToLeft(question.prompt.Verb)
+}
+
+// Alignment of the question block, following the direction of the deck
+func (question question) alignment() lipgloss.Position {
+	if question.isRightToLeft() {
+		return lipgloss.Right
+	}
+	return lipgloss.Left
+}
+
+// Input field keeps text in logical order, so for right-to-left
+// answers the arrows are swapped to move the way they point
+func (question question) orientArrows(msg tea.Msg) tea.Msg {
+	keyMsg, isKey := msg.(tea.KeyMsg)
+	if !isKey || !isRightToLeft(question.correctAnswer) {
+		return msg
+	}
+	switch keyMsg.Type {
+	case tea.KeyLeft:
+		keyMsg.Type = tea.KeyRight
+	case tea.KeyRight:
+		keyMsg.Type = tea.KeyLeft
+	case tea.KeyCtrlLeft:
+		keyMsg.Type = tea.KeyCtrlRight
+	case tea.KeyCtrlRight:
+		keyMsg.Type = tea.KeyCtrlLeft
+	}
+	return keyMsg
+}
+
+// Keeps text of one direction from reordering the text around it,
+// with first strong isolate and pop directional isolate
+func isolate(text string) string {
+	return "\u2068" + text + "\u2069"
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Saves statistics and history before opening the next screen on top
+func (screen quizScreen) saveAndOpen(next tea.Model) (tea.Model, tea.Cmd) {
+	err := screen.saveStatistics()
+	if err != nil {
+		return screen, pushScreen(newSaveFailedScreen(screen.session, next, err))
+	}
+	return screen, pushScreen(next)
+}
+
+// Failed save screen makes way for the screen it was opened
+// instead of, or goes back to the one below if there is none
+func (screen saveFailedScreen) proceed() (tea.Model, tea.Cmd) {
+	if screen.next == nil {
+		return screen, popScreen
+	}
+	return screen, replaceScreen(screen.next)
+}
+
+// Shown when statistics or history could not be written,
+// everything stays in memory until some save succeeds
+type saveFailedScreen struct {
+	session      *session
+	next         tea.Model
+	err          error
+	choosingPath bool
+	pathInput    textinput.Model
+}
+
+func newSaveFailedScreen(session *session, next tea.Model, err error) saveFailedScreen {
+	pathInput := textinput.New()
+	pathInput.Prompt = "> "
+	pathInput.Placeholder = "path to statistics file"
+	return saveFailedScreen{
+		session:   session,
+		next:      next,
+		err:       err,
+		pathInput: pathInput,
+	}
+}
+
+func (screen saveFailedScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen saveFailedScreen) retry() (tea.Model, tea.Cmd) {
+	if err := screen.session.saveStatistics(); err != nil {
+		screen.err = err
+		return screen, nil
+	}
+	if screen.next == nil {
+		return screen, tea.Quit
+	}
+	return screen.proceed()
+}
+
+// History is kept next to the alternative statistics file,
+// later saves of this session go to the new place as well
+func (screen saveFailedScreen) saveElsewhere() (tea.Model, tea.Cmd) {
+	path := strings.TrimSpace(screen.pathInput.Value())
+	screen.choosingPath = false
+	screen.pathInput.Blur()
+	if path == "" {
+		return screen, nil
+	}
+	statisticsPath = path
+	historyPath = filepath.Join(filepath.Dir(path), filepath.Base(historyPath))
+	slog.Warn("Saving to an alternative location", "statistics", statisticsPath, "history", historyPath)
+	return screen.retry()
+}
+
+func (screen saveFailedScreen) isTyping() bool {
+	return screen.choosingPath
+}
+
+func (screen saveFailedScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if screen.choosingPath {
+			switch {
+			case key.Matches(msg, keys.Submit):
+				return screen.saveElsewhere()
+			case key.Matches(msg, keys.Menu):
+				screen.choosingPath = false
+				screen.pathInput.Blur()
+				return screen, nil
+			}
+			var cmd tea.Cmd
+			screen.pathInput, cmd = screen.pathInput.Update(msg)
+			return screen, cmd
+		}
+		switch {
+		case key.Matches(msg, keys.Retry):
+			return screen.retry()
+		case key.Matches(msg, keys.SaveAs):
+			screen.choosingPath = true
+			screen.pathInput.SetValue(statisticsPath)
+			screen.pathInput.CursorEnd()
+			return screen, screen.pathInput.Focus()
+		case key.Matches(msg, keys.Continue):
+			// Quitting is cancelled rather than done without saving
+			return screen.proceed()
+		}
+	}
+	return screen, nil
+}
+
+var saveFailedHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Retry}, action: "retry"},
+	{bindings: []*key.Binding{&keys.SaveAs}, action: "save as"},
+	{bindings: []*key.Binding{&keys.Continue}, action: "continue"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "quit"},
+}
+
+var choosingPathHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit}, action: "save"},
+	{bindings: []*key.Binding{&keys.Menu}, action: "cancel"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "quit unsaved"},
+}
+
+func (screen saveFailedScreen) View() string {
+	footer := renderHelpRow(saveFailedHelp[:])
+	lines := []string{
+		statsTitleStyle.Render("Could not save progress"),
+		"",
+		wrongAnswerStyle.AlignHorizontal(lipgloss.Left).MaxHeight(4).Render(screen.err.Error()),
+		"",
+		questionStatsStyle.Width(boxWidth).Render("Progress is kept until you quit, quitting now loses it"),
+	}
+	if screen.choosingPath {
+		screen.pathInput.Width = boxWidth - lipgloss.Width(screen.pathInput.Prompt) - 1
+		footer = renderHelpRow(choosingPathHelp[:])
+		lines = append(lines, "", screen.pathInput.View())
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return renderBox(body, footer)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kligunov-id/gem2/scheduler"
+)
+
+// How the next question is chosen once the replay and warm-up queues are empty
+type questionOrder int
+
+const (
+	// Random, weighted towards questions answered wrong more often
+	orderWeighted questionOrder = iota
+	// Due reviews first, the longest waiting one first, then weighted
+	orderDueFirst
+	// Picked as weighted, but asked in runs from easiest to hardest
+	orderLadder
+)
+
+var questionOrderNames = [...]string{"weighted", "due-first", "ladder"}
+
+var nextQuestionOrder questionOrder
+
+func (order questionOrder) String() string {
+	return questionOrderNames[order]
+}
+
+// Makes it usable as a flag
+func (order *questionOrder) Set(value string) error {
+	for i, name := range questionOrderNames {
+		if name == value {
+			*order = questionOrder(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown value %q, available: %s", value, strings.Join(questionOrderNames[:], ", "))
+}
+
+func (order *questionOrder) cycle() {
+	*order = (*order + 1) % questionOrder(len(questionOrderNames))
+}
+
+func (statistics statisticsDatabase) nextPrompt() prompt {
+	if nextQuestionOrder == orderDueFirst {
+		if prompt, due := scheduler.MostOverdue(statistics.Database, time.Now()); due {
+			return prompt
+		}
+	}
+	prompt := scheduler.RandomPrompt(statistics.Database)
+	// New questions come up as often as before, the
+	// frequency list only decides which one it is
+	if !scheduler.IsStarted(statistics.Record(prompt)) {
+		if introduced, found := statistics.nextIntroduction(); found {
+			return introduced
+		}
+	}
+	return prompt
+}
+
+func (statistics statisticsDatabase) dueSummary(now time.Time) scheduler.DueSummary {
+	return scheduler.Summarize(statistics.Database, now, endOfStudyDay(now))
+}
@@ -0,0 +1,150 @@
+// Package scheduler decides which question is asked next
+// and when answered questions are due for review.
+package scheduler
+
+import (
+	"cmp"
+	"log/slog"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/kligunov-id/gem2/deck"
+	"github.com/kligunov-id/gem2/stats"
+)
+
+// Longest wait between repetitions of a question, however long its streak
+const MaxReviewInterval = 60 * 24 * time.Hour
+
+// Interval doubles with every correct answer in a row:
+// a day after the first one, two days after the second...
+func ReviewInterval(streak uint32) time.Duration {
+	if streak == 0 {
+		return 0
+	}
+	if streak > 7 {
+		return MaxReviewInterval
+	}
+	return min(time.Duration(1<<(streak-1))*24*time.Hour, MaxReviewInterval)
+}
+
+// Questions never answered are new rather than due
+func IsStarted(record stats.Record) bool {
+	return record.Correct > 0 || record.Mistakes > 0
+}
+
+// Files written before practice times were stored have
+// no last practice time, such questions are due at once
+func DueAt(record stats.Record) time.Time {
+	if record.LastPracticed.IsZero() {
+		return time.Time{}
+	}
+	return record.LastPracticed.Add(ReviewInterval(record.Streak))
+}
+
+// Suspended questions are never due
+func IsDue(record stats.Record, now time.Time) bool {
+	return IsStarted(record) && !record.Suspended && !DueAt(record).After(now)
+}
+
+// Last answer to a weak question was wrong
+func IsWeak(record stats.Record) bool {
+	return record.Mistakes > 0 && record.Streak == 0
+}
+
+type DueSummary struct {
+	DueNow       int
+	DueWithinDay int
+	New          int
+	Weak         int
+}
+
+// Questions due within the day are those due before its end
+func Summarize(statistics stats.Database, now time.Time, endOfDay time.Time) DueSummary {
+	var summary DueSummary
+	for _, record := range statistics.All() {
+		if record.Suspended {
+			continue
+		}
+		if IsWeak(record) {
+			summary.Weak++
+		}
+		switch {
+		case !IsStarted(record):
+			summary.New++
+		case IsDue(record, now):
+			summary.DueNow++
+			summary.DueWithinDay++
+		case IsDue(record, endOfDay):
+			summary.DueWithinDay++
+		}
+	}
+	return summary
+}
+
+// Questions are picked with probability proportional to their weight,
+// suspended ones weigh nothing. With all of them suspended one is
+// asked anyway, the quiz has nothing else to show.
+func RandomPrompt(statistics stats.Database) deck.Prompt {
+	randomWeight := rand.Float32() * statistics.TotalWeight()
+	weighted := false
+	for prompt, record := range statistics.All() {
+		if record.Weight() == 0 {
+			continue
+		}
+		weighted = true
+		randomWeight -= record.Weight()
+		if randomWeight <= 0 {
+			return prompt
+		}
+	}
+	if !weighted {
+		return statistics.Prompts()[0]
+	}
+	slog.Warn("Random question selection floating arithmetic problem, recalculating")
+	return RandomPrompt(statistics)
+}
+
+// Chance of each prompt to be picked by RandomPrompt
+func Chance(statistics stats.Database, record stats.Record) float32 {
+	return record.Weight() / statistics.TotalWeight()
+}
+
+// All prompts, the likeliest to be picked by RandomPrompt first
+func ByChance(statistics stats.Database) []deck.Prompt {
+	prompts := slices.Clone(statistics.Prompts())
+	slices.SortStableFunc(prompts, func(a deck.Prompt, b deck.Prompt) int {
+		return cmp.Compare(statistics.Record(b).Weight(), statistics.Record(a).Weight())
+	})
+	return prompts
+}
+
+// Due prompts in the order MostOverdue picks them in, at most count
+func Overdue(statistics stats.Database, now time.Time, count int) []deck.Prompt {
+	var due []deck.Prompt
+	for prompt, record := range statistics.All() {
+		if IsDue(record, now) {
+			due = append(due, prompt)
+		}
+	}
+	slices.SortStableFunc(due, func(a deck.Prompt, b deck.Prompt) int {
+		return DueAt(statistics.Record(a)).Compare(DueAt(statistics.Record(b)))
+	})
+	return due[:min(count, len(due))]
+}
+
+// Due question which has waited the longest since it became due
+func MostOverdue(statistics stats.Database, now time.Time) (deck.Prompt, bool) {
+	var oldest deck.Prompt
+	var oldestDue time.Time
+	found := false
+	for prompt, record := range statistics.All() {
+		if !IsDue(record, now) {
+			continue
+		}
+		if due := DueAt(record); !found || due.Before(oldestDue) {
+			oldest, oldestDue, found = prompt, due, true
+		}
+	}
+	return oldest, found
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kligunov-id/gem2/deck"
+	"github.com/kligunov-id/gem2/stats"
+)
+
+func TestReviewInterval(t *testing.T) {
+	const day = 24 * time.Hour
+	tests := []struct {
+		streak uint32
+		want   time.Duration
+	}{
+		{0, 0},
+		{1, day},
+		{2, 2 * day},
+		{4, 8 * day},
+		{6, 32 * day},
+		{7, MaxReviewInterval},
+		{100, MaxReviewInterval},
+	}
+	for _, test := range tests {
+		if got := ReviewInterval(test.streak); got != test.want {
+			t.Errorf("ReviewInterval(%d) = %v, want %v", test.streak, got, test.want)
+		}
+	}
+}
+
+func TestIsDue(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		record stats.Record
+		want   bool
+	}{
+		{"new", stats.Record{}, false},
+		{"wrong last time", stats.Record{Mistakes: 1, LastPracticed: now}, true},
+		{"interval passed", stats.Record{Streak: 2, Correct: 2, LastPracticed: now.Add(-48 * time.Hour)}, true},
+		{"interval not passed", stats.Record{Streak: 2, Correct: 2, LastPracticed: now.Add(-47 * time.Hour)}, false},
+		{"never timed", stats.Record{Streak: 5, Correct: 5}, true},
+		{"suspended", stats.Record{Mistakes: 1, LastPracticed: now, Suspended: true}, false},
+	}
+	for _, test := range tests {
+		if got := IsDue(test.record, now); got != test.want {
+			t.Errorf("%s: IsDue = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func testDatabase(records ...stats.Record) (stats.Database, []deck.Prompt) {
+	questions := make([]deck.Question, len(records))
+	prompts := make([]deck.Prompt, len(records))
+	for i := range records {
+		prompts[i] = deck.Prompt{FormClue: "ich", Verb: string(rune('a' + i))}
+		questions[i] = deck.Question{Prompt: prompts[i], Answer: prompts[i].Verb}
+	}
+	statistics := stats.New(questions)
+	for i, record := range records {
+		statistics.UpdateStats(prompts[i], record)
+	}
+	return statistics, prompts
+}
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	endOfDay := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	statistics, _ := testDatabase(
+		stats.Record{},
+		stats.Record{Mistakes: 1, LastPracticed: now},
+		stats.Record{Streak: 1, Correct: 1, LastPracticed: now.Add(-18 * time.Hour)},
+		stats.Record{Streak: 3, Correct: 3, LastPracticed: now},
+		stats.Record{Mistakes: 2, LastPracticed: now, Suspended: true},
+	)
+	want := DueSummary{DueNow: 1, DueWithinDay: 2, New: 1, Weak: 1}
+	if got := Summarize(statistics, now, endOfDay); got != want {
+		t.Errorf("Summarize = %+v, want %+v", got, want)
+	}
+}
+
+func TestOverdue(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	statistics, prompts := testDatabase(
+		stats.Record{Mistakes: 1, LastPracticed: now.Add(-time.Hour)},
+		stats.Record{Streak: 3, Correct: 3, LastPracticed: now},
+		stats.Record{Mistakes: 1, LastPracticed: now.Add(-3 * time.Hour)},
+		stats.Record{Mistakes: 1, LastPracticed: now.Add(-2 * time.Hour)},
+	)
+	want := []deck.Prompt{prompts[2], prompts[3], prompts[0]}
+	if got := Overdue(statistics, now, 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("Overdue = %v, want %v", got, want)
+	}
+	if got := Overdue(statistics, now, 2); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("Overdue of 2 = %v, want %v", got, want[:2])
+	}
+	if oldest, found := MostOverdue(statistics, now); !found || oldest != prompts[2] {
+		t.Errorf("MostOverdue = %v, %v, want %v", oldest, found, prompts[2])
+	}
+	if _, found := MostOverdue(statistics, now.Add(-4*time.Hour)); found {
+		t.Error("MostOverdue found a question before any was due")
+	}
+}
+
+func TestRandomPromptSkipsSuspended(t *testing.T) {
+	statistics, prompts := testDatabase(
+		stats.Record{Suspended: true},
+		stats.Record{},
+		stats.Record{Suspended: true},
+	)
+	for range 100 {
+		if prompt := RandomPrompt(statistics); prompt != prompts[1] {
+			t.Fatalf("RandomPrompt picked suspended %v", prompt)
+		}
+	}
+	if chance := Chance(statistics, statistics.Record(prompts[1])); chance != 1 {
+		t.Errorf("chance of the only question %v, want 1", chance)
+	}
+}
+
+// With nothing to weigh, a question is still asked
+func TestRandomPromptAllSuspended(t *testing.T) {
+	statistics, prompts := testDatabase(stats.Record{Suspended: true}, stats.Record{Suspended: true})
+	if prompt := RandomPrompt(statistics); prompt != prompts[0] {
+		t.Errorf("RandomPrompt = %v, want the first question %v", prompt, prompts[0])
+	}
+}
+
+func TestByChance(t *testing.T) {
+	statistics, prompts := testDatabase(
+		stats.Record{Streak: 3, Correct: 3},
+		stats.Record{},
+		stats.Record{Streak: 1, Correct: 1},
+		stats.Record{Suspended: true},
+	)
+	want := []deck.Prompt{prompts[1], prompts[2], prompts[0], prompts[3]}
+	if got := ByChance(statistics); !reflect.DeepEqual(got, want) {
+		t.Errorf("ByChance = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultServeAddress = "127.0.0.1:8417"
+	// Answers are a few words, anything larger is not one
+	maxRequestBytes = 64 << 10
+	// Every save rotates the statistics backups, saving after each
+	// answer would leave backups only a few answers old
+	serveSaveAnswers = 10
+)
+
+// Answering page for browsers, using the same endpoints
+// as any other frontend, so it needs no server side of its own
+//
+//go:embed web/index.html
+var webPage []byte
+
+// The quiz of one session shared by every client, requests
+// take turns as answering changes statistics and the question
+type quizServer struct {
+	mutex sync.Mutex
+	quiz  quizScreen
+	// Host of the address listened on, empty for all interfaces
+	host string
+}
+
+type questionJSON struct {
+	FormClue string `json:"form_clue"`
+	Verb     string `json:"verb"`
+}
+
+type answerRequestJSON struct {
+	Answer string `json:"answer"`
+	// Question the answer is for, clients sharing the server may
+	// have answered it already, omitted the current one is meant
+	Question *questionJSON `json:"question,omitempty"`
+}
+
+type conflictResponseJSON struct {
+	Error string       `json:"error"`
+	Next  questionJSON `json:"next"`
+}
+
+type answerResponseJSON struct {
+	Correct       bool         `json:"correct"`
+	CorrectAnswer string       `json:"correct_answer"`
+	Streak        uint32       `json:"streak"`
+	Record        bool         `json:"record"`
+	Next          questionJSON `json:"next"`
+}
+
+type statsResponseJSON struct {
+	Questions   uint32 `json:"questions"`
+	Started     uint32 `json:"started"`
+	Mature      uint32 `json:"mature"`
+	Mastered    uint32 `json:"mastered"`
+	Due         int    `json:"due"`
+	DailyStreak int    `json:"daily_streak"`
+	Level       int    `json:"level"`
+	Experience  uint64 `json:"experience"`
+	Session     struct {
+		Correct uint32 `json:"correct"`
+		Wrong   uint32 `json:"wrong"`
+		Streak  uint32 `json:"streak"`
+	} `json:"session"`
+}
+
+func (server *quizServer) question() questionJSON {
+	return questionJSON{
+		FormClue: server.quiz.question.prompt.FormClue,
+		Verb:     server.quiz.question.prompt.Verb,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Error("Failed to write response", "error", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// Same question until it is answered, so asking again is harmless
+func (server *quizServer) handleQuestion(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	writeJSON(w, http.StatusOK, server.question())
+}
+
+// Answers the current question and moves on to the next one.
+// The server has no natural moment to save at like leaving the
+// quiz, so statistics are saved every few answers and when stopping.
+func (server *quizServer) handleAnswer(w http.ResponseWriter, r *http.Request) {
+	// Pages elsewhere can only send forms and plain text
+	// without asking first, never JSON declared as such
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeJSONError(w, http.StatusUnsupportedMediaType, errors.New("answers must be sent as application/json"))
+		return
+	}
+	var request answerRequestJSON
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err := decoder.Decode(&request); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid answer: %w", err))
+		return
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if request.Question != nil && *request.Question != server.question() {
+		writeJSON(w, http.StatusConflict, conflictResponseJSON{
+			Error: "the question was already answered by another client",
+			Next:  server.question(),
+		})
+		return
+	}
+	quiz := &server.quiz
+	quiz.inputField.SetValue(request.Answer)
+	quiz.submitAnswer()
+	response := answerResponseJSON{
+		Correct:       quiz.isAnswerCorrect(),
+		CorrectAnswer: quiz.question.correctAnswer,
+		Streak:        quiz.streak,
+		Record:        quiz.sessionRecord || quiz.promptRecord,
+	}
+	if cmd := quiz.runRecordBrokenHook(); cmd != nil {
+		go func() {
+			if failed, isFailure := cmd().(commandFailedMessage); isFailure {
+				slog.Error("Record hook failed", "error", failed.err)
+			}
+		}()
+	}
+	quiz.nextQuestion()
+	response.Next = server.question()
+	// The answer counts whether or not saving works, saving is
+	// attempted again with the next answer and when stopping
+	if quiz.unsavedAnswers >= serveSaveAnswers {
+		if err := quiz.saveStatistics(); err != nil {
+			slog.Error("Failed to save statistics", "error", err)
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (server *quizServer) handleStats(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	quiz := server.quiz
+	now := time.Now()
+	summary := quiz.statistics.summary()
+	experience := quiz.history.totalExperience()
+	response := statsResponseJSON{
+		Questions:   summary.questions,
+		Started:     summary.started,
+		Mature:      summary.mature,
+		Mastered:    summary.mastered,
+		Due:         quiz.statistics.dueSummary(now).DueNow,
+		DailyStreak: quiz.history.dailyStreak(now),
+		Level:       levelOf(experience).number,
+		Experience:  experience,
+	}
+	response.Session.Correct = quiz.correctAnswers
+	response.Session.Wrong = quiz.wrongAnswers
+	response.Session.Streak = quiz.streak
+	writeJSON(w, http.StatusOK, response)
+}
+
+func handleWebPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(webPage)
+}
+
+// Names other than the one listened on could be a domain of some
+// website pointed at this machine, addresses can not
+func (server *quizServer) allowsHost(requested string) bool {
+	host, _, err := net.SplitHostPort(requested)
+	if err != nil {
+		host = requested
+	}
+	return host == server.host || host == "localhost" || net.ParseIP(host) != nil
+}
+
+// Browsers send the origin of the page making the request,
+// only the page served from here may answer through it
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && parsed.Host == r.Host
+}
+
+// Without authentication, requests from pages open in the user's
+// browser are all that has to be kept out of a local server
+func (server *quizServer) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !server.allowsHost(r.Host) {
+			slog.Warn("Rejected request for another host", "host", r.Host, "remote", r.RemoteAddr)
+			writeJSONError(w, http.StatusMisdirectedRequest, fmt.Errorf("unknown host %q", r.Host))
+			return
+		}
+		if !sameOrigin(r) {
+			slog.Warn("Rejected request from another origin", "origin", r.Header.Get("Origin"), "remote", r.RemoteAddr)
+			writeJSONError(w, http.StatusForbidden, errors.New("requests from other origins are not accepted"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (server *quizServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", handleWebPage)
+	mux.HandleFunc("GET /question", server.handleQuestion)
+	mux.HandleFunc("POST /answer", server.handleAnswer)
+	mux.HandleFunc("GET /stats", server.handleStats)
+	return server.guard(mux)
+}
+
+// Other frontends reuse the deck and progress through this, it
+// has no authentication so it only listens locally by default
+func runServe(args []string) {
+	flags, options := newFlagSet("serve", "")
+	address := flags.String("addr", defaultServeAddress, "`address` to listen on, anyone who can reach it can answer questions, 0.0.0.0:8417 for a tablet on the same network")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	options.apply()
+	loadSessionSettings()
+	requireInstanceLock("serving")
+	database := read_database()
+	useDeckSettingsOrExit(database.Settings, nil)
+	statistics := database.loadStatistics()
+	history := loadHistory()
+	readNotesAndAchievements()
+	server := &quizServer{quiz: newQuizScreen(newSession(&statistics, &history))}
+	// Clients are only ever shown questions, so the clock never stops
+	server.quiz.clock.start()
+	if host, _, err := net.SplitHostPort(*address); err == nil {
+		server.host = host
+	}
+	httpServer := &http.Server{
+		Addr:              *address,
+		Handler:           server.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		sig := <-signals
+		slog.Info("Received signal, stopping server", "signal", sig)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(ctx); err != nil {
+			slog.Error("Failed to stop server gracefully", "error", err)
+		}
+	}()
+
+	slog.Info("Serving quiz", "address", *address, "build", currentBuild().String())
+	fmt.Fprintf(os.Stderr, "Serving on http://%s, open it in a browser to practice, press ctrl+c to stop\n", *address)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		logFatal("Server failed", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(serverError)
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.quiz.teardownWithoutUI()
+	slog.Info("Finished successfully")
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Progress of the running quiz, owned by the root model. Screens
+// keep a pointer to it rather than copies of its fields, so
+// an update made on one screen is never lost on another.
+type session struct {
+	statistics     *statisticsDatabase
+	history        *practiceHistory
+	wrongAnswers   uint32
+	correctAnswers uint32
+	streak         uint32
+	// Answers given since statistics were last written
+	unsavedAnswers int
+	clock          sessionClock
+	// Questions answered wrong which are asked until answered right,
+	// and the ones taken off the pile since it was last cleared
+	againPile    []againCard
+	againCleared []againCard
+	// Answers typed rather than chosen, in the order given
+	typedAnswers []typedAnswer
+}
+
+func newSession(statistics *statisticsDatabase, history *practiceHistory) *session {
+	return &session{statistics: statistics, history: history}
+}
+
+func (session *session) isLoaded() bool {
+	return session.statistics != nil
+}
+
+// Everything done after the last answer, once progress is saved
+func (session *session) finish() {
+	session.runSessionEndHook()
+	session.postLeaderboardResult()
+	session.reportHabits()
+	backUpIfDue()
+}
+
+// Settings of what is done around sessions are read before one
+// starts, so that mistakes in them are not found only at the end
+func loadSessionSettings() {
+	loaders := []struct {
+		name string
+		load func() error
+	}{
+		{"hooks", loadHooks},
+		{"leaderboard settings", loadLeaderboard},
+		{"habit settings", loadHabits},
+		{"backup settings", loadBackupSettings},
+	}
+	for _, loader := range loaders {
+		if err := loader.load(); err != nil {
+			logFatal("Failed to load "+loader.name, "error", err)
+			fmt.Fprintf(os.Stderr, "Failed to load %s: %v\n", loader.name, err)
+			exit(usageError)
+		}
+	}
+}
+
+// Both files are attempted even if the first one fails
+func (session *session) saveStatistics() error {
+	unsavedAnswers := session.unsavedAnswers
+	err := session.saveQuietly()
+	if err == nil && unsavedAnswers > 0 {
+		notify("statistics saved")
+	}
+	return err
+}
+
+// Autosaving after an answer leaves the toast about the answer be
+func (session *session) saveQuietly() error {
+	err := errors.Join(session.statistics.save(), session.history.save())
+	if err == nil {
+		session.unsavedAnswers = 0
+	}
+	return err
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package main
+
+import (
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// Processes on a pseudo console are managed by wish here
+func runInSession(s ssh.Session, name string, args []string, environment []string) error {
+	command := wish.Command(s, name, args...)
+	command.SetEnv(environment)
+	return command.Run()
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+
+	"github.com/charmbracelet/ssh"
+)
+
+// The quiz gets the PTY of the session as its controlling terminal,
+// so that it is told about resizes, and is hung up on disconnect
+func runInSession(s ssh.Session, name string, args []string, environment []string) error {
+	pty, _, _ := s.Pty()
+	command := exec.CommandContext(s.Context(), name, args...)
+	command.Env = environment
+	command.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	command.Cancel = func() error {
+		return command.Process.Signal(syscall.SIGHUP)
+	}
+	command.WaitDelay = sshSaveTimeout
+	if err := pty.Start(command); err != nil {
+		return err
+	}
+	return command.Wait()
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Settings take effect at once, choosing one cycles its values.
+// Flag of the quiz command setting the same thing is how the value
+// is remembered, theme and layout are kept by the preferences anyway.
+type settingsEntry struct {
+	title  string
+	detail func() string
+	change func()
+	flag   string
+}
+
+var settingsEntries = [...]settingsEntry{
+	{
+		title:  "Theme",
+		detail: func() string { return currentThemeName },
+		change: useNextTheme,
+	},
+	{
+		title:  "Layout",
+		detail: formatLayout,
+		change: cycleLayout,
+	},
+	{
+		title:  "Accent completion",
+		detail: func() string { return formatSwitch(accentCompletion) },
+		change: func() { accentCompletion = !accentCompletion },
+		flag:   "accent-completion",
+	},
+	{
+		title:  "Animations",
+		detail: func() string { return formatSwitch(animations) },
+		change: func() { animations = !animations },
+		flag:   "animations",
+	},
+	{
+		title:  "Time limit",
+		detail: formatTimeLimit,
+		change: cycleTimeLimit,
+		flag:   "time-limit",
+	},
+	{
+		title:  "Answer checking",
+		detail: func() string { return answerChecking.String() },
+		change: answerChecking.cycle,
+		flag:   "answer-checking",
+	},
+	{
+		title:  "Multiple choice",
+		detail: formatMultipleChoice,
+		change: func() { cycleChoice(&multipleChoice, multipleChoiceChoices[:]) },
+		flag:   "choices",
+	},
+	{
+		title:  "Distractors",
+		detail: func() string { return distractors.String() },
+		change: distractors.cycle,
+		flag:   "distractors",
+	},
+	{
+		title:  "Question order",
+		detail: func() string { return nextQuestionOrder.String() },
+		change: nextQuestionOrder.cycle,
+		flag:   "order",
+	},
+	{
+		title:  "Streak decay",
+		detail: formatStreakDecay,
+		change: func() { cycleChoice(&streakDecayHorizon, streakDecayChoices[:]) },
+		flag:   "streak-decay",
+	},
+	{
+		title:  "Dead records",
+		detail: formatDeadRecordsRetention,
+		change: func() { cycleChoice(&deadRecordsRetention, deadRecordsRetentionChoices[:]) },
+		flag:   "dead-records",
+	},
+	{
+		title:  "Session length",
+		detail: func() string { return formatAnswerCount(sessionLength) },
+		change: func() { cycleChoice(&sessionLength, sessionLengthChoices[:]) },
+		flag:   "session-length",
+	},
+	{
+		title:  "Autosave",
+		detail: formatAutosave,
+		change: func() { cycleChoice(&autosaveEvery, autosaveChoices[:]) },
+		flag:   "autosave",
+	},
+	{
+		title:  "Bell",
+		detail: func() string { return bellSignal.String() },
+		change: bellSignal.cycle,
+		flag:   "bell",
+	},
+	{
+		title:  "Flash",
+		detail: func() string { return flashSignal.String() },
+		change: flashSignal.cycle,
+		flag:   "flash",
+	},
+}
+
+func formatSwitch(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+// Paged like the other lists, there are more settings
+// than fit into the box at its smallest
+type settingsScreen struct {
+	listPosition
+}
+
+func (screen settingsScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen settingsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back):
+			return screen, popScreen
+		case key.Matches(msg, keys.Down):
+			screen.scrollDown(len(settingsEntries), listShownRows())
+			return screen, nil
+		case key.Matches(msg, keys.Up):
+			screen.scrollUp()
+			return screen, nil
+		case key.Matches(msg, keys.Submit):
+			settingsEntries[screen.selectedIndex()].change()
+			return screen, nil
+		}
+	case tea.MouseMsg:
+		// Clicking the selected setting once more changes it
+		shownRows := min(listShownRows(), len(settingsEntries)-screen.firstShownIndex)
+		if row, clicked := clickedRow(msg, listFirstRow, shownRows); clicked && row == screen.selectedRow {
+			settingsEntries[screen.selectedIndex()].change()
+			return screen, nil
+		}
+		screen.handleMouse(msg, len(settingsEntries), listShownRows())
+		return screen, nil
+	}
+	return screen, nil
+}
+
+var settingsScreenHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Down, &keys.Up}, action: "move"},
+	{bindings: []*key.Binding{&keys.Submit}, action: "change"},
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+}
+
+func (screen settingsScreen) View() string {
+	screen.fit(listShownRows())
+	lines := []string{renderListTitle("Settings", screen.listPosition, len(settingsEntries)), ""}
+	for row := 0; row < listShownRows(); row++ {
+		index := screen.firstShownIndex + row
+		if index >= len(settingsEntries) {
+			break
+		}
+		entry := settingsEntries[index]
+		lines = append(lines, renderMenuEntry(entry.title, entry.detail, row == screen.selectedRow))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return renderBox(body, renderHelpRow(settingsScreenHelp[:]))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Turns termination signals into a regular exit, so that
+// progress is saved the same way as on esc.
+// SIGHUP is what closing the terminal window sends.
+func forwardSignals(p *tea.Program) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		for sig := range signals {
+			slog.Info("Received signal, quitting", "signal", sig)
+			p.Send(quitMessage{})
+		}
+	}()
+}
+
+// Quitting is asked for with it from anywhere: keys, signals and
+// screens alike. The root model takes it through teardown whichever
+// screen is shown, so a screen can not quit without saving progress.
+type quitMessage struct{}
+
+func requestQuit() tea.Msg {
+	return quitMessage{}
+}
+
+// Statistics and history are flushed while the UI still runs, so that
+// a failure can be shown and dealt with. Answers need no flushing, each
+// is synced into the journal as it is given. The rest of the teardown,
+// in finish, runs once the terminal is given back.
+func (m model) teardown() (model, tea.Cmd) {
+	if !m.session.isLoaded() {
+		return m, tea.Quit
+	}
+	// Screens opened on top of these, like help, do not make
+	// their questions any less open, and neither does the list
+	// of deck changes shown before them on startup
+	for _, screen := range m.screens {
+		if changes, isChanges := screen.(deckChangesScreen); isChanges {
+			screen = changes.next
+		}
+		switch screen.(type) {
+		case saveFailedScreen:
+			// Asked again instead would never let the user out
+			slog.Warn("Quitting without saving progress")
+			return m, tea.Quit
+		case reconciliationScreen:
+			// Saving would accept the edited answers without being asked to
+			return m, tea.Quit
+		}
+	}
+	slog.Info("Saving progress before quitting", "profile", profileName, "path", statisticsPath)
+	if err := m.session.saveStatistics(); err != nil {
+		return m, m.push(newSaveFailedScreen(m.session, nil, err))
+	}
+	return m, tea.Quit
+}
+
+// Teardown of sessions without a UI to show a failed save in,
+// which fails the whole run instead
+func (session *session) teardownWithoutUI() {
+	slog.Info("Saving progress before quitting", "profile", profileName, "path", statisticsPath)
+	if err := session.saveStatistics(); err != nil {
+		fmt.Fprintf(os.Stderr, "Progress was not saved: %v\n", err)
+		exit(statisticsError)
+	}
+	session.finish()
+}
+
+// Last resort for when the UI loop did not finish normally.
+// Mistakes need no flushing since each one is written immediately.
+func (session *session) emergencySave() {
+	if !session.isLoaded() {
+		return
+	}
+	slog.Info("Saving progress before exiting")
+	// Failures are already logged and there is nobody left to ask
+	session.statistics.save()
+	session.history.save()
+}
+
+// Must be deferred in the goroutine running the program,
+// as that is where Update and View are called
+func recoverAndSave(p *tea.Program, session *session) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if err := p.ReleaseTerminal(); err != nil {
+		slog.Error("Failed to restore terminal", "error", err)
+	}
+	logFatal("Caught panic", "panic", r, "stack", string(debug.Stack()))
+	session.emergencySave()
+	fmt.Fprintf(os.Stderr, "Caught panic:\n\n%v\n\nProgress was saved, see %s for details\n", r, logPath)
+	exit(internalError)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"strings"
+)
+
+// Pixels more transparent than that are left out
+const sixelOpaqueAlpha = 0x8000
+
+// Scaled to the given size in pixels and dithered to the web safe
+// palette, which every sixel terminal has enough registers for
+func sixelImage(source image.Image, width int, height int) string {
+	scaled := scaleImage(source, width, height)
+	paletted := image.NewPaletted(scaled.Bounds(), palette.WebSafe)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), scaled, image.Point{})
+
+	var sequence strings.Builder
+	// Second parameter 1 keeps what is behind the left out pixels
+	sequence.WriteString("\x1bP0;1;0q")
+	fmt.Fprintf(&sequence, "\"1;1;%d;%d", width, height)
+	for i, entry := range palette.WebSafe {
+		r, g, b, _ := entry.RGBA()
+		fmt.Fprintf(&sequence, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+	// Every band is six pixels tall, drawn once per color in it
+	for top := 0; top < height; top += 6 {
+		bands := make(map[uint8][]byte)
+		var colors []uint8
+		for y := top; y < min(top+6, height); y++ {
+			for x := 0; x < width; x++ {
+				if _, _, _, alpha := scaled.At(x, y).RGBA(); alpha < sixelOpaqueAlpha {
+					continue
+				}
+				index := paletted.ColorIndexAt(x, y)
+				if bands[index] == nil {
+					bands[index] = make([]byte, width)
+					colors = append(colors, index)
+				}
+				bands[index][x] |= 1 << (y - top)
+			}
+		}
+		for i, index := range colors {
+			if i > 0 {
+				// Back to the start of the band for the next color
+				sequence.WriteByte('$')
+			}
+			fmt.Fprintf(&sequence, "#%d", index)
+			writeSixelRuns(&sequence, bands[index])
+		}
+		sequence.WriteByte('-')
+	}
+	sequence.WriteString("\x1b\\")
+	return sequence.String()
+}
+
+// Repeated sixels are written as a count, empty ones at the end are left out
+func writeSixelRuns(sequence *strings.Builder, bits []byte) {
+	end := len(bits)
+	for end > 0 && bits[end-1] == 0 {
+		end--
+	}
+	for start := 0; start < end; {
+		run := 1
+		for start+run < end && bits[start+run] == bits[start] {
+			run++
+		}
+		sixel := rune('?' + bits[start])
+		if run > 3 {
+			fmt.Fprintf(sequence, "!%d%c", run, sixel)
+		} else {
+			sequence.WriteString(strings.Repeat(string(sixel), run))
+		}
+		start += run
+	}
+}
+
+// Nearest neighbour is enough for the few hundred pixels of a cell grid
+func scaleImage(source image.Image, width int, height int) *image.NRGBA {
+	bounds := source.Bounds()
+	scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sourceY := bounds.Min.Y + y*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			sourceX := bounds.Min.X + x*bounds.Dx()/width
+			scaled.Set(x, y, color.NRGBAModel.Convert(source.At(sourceX, sourceY)))
+		}
+	}
+	return scaled
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Text to speech program, which can either speak at once
+// or write the speech to a file in its own format. Text comes
+// after "--", answers starting with a dash are no options.
+type speaker struct {
+	name string
+	// Extension of the files written
+	format string
+	speak  func(voice string, text string) []string
+	write  func(voice string, text string, file string) []string
+}
+
+var defaultSpeakers = []speaker{
+	{
+		name:   "espeak-ng",
+		format: ".wav",
+		speak:  func(voice string, text string) []string { return []string{"-v", voice, "--", text} },
+		write: func(voice string, text string, file string) []string {
+			return []string{"-v", voice, "-w", file, "--", text}
+		},
+	},
+	{
+		name:   "espeak",
+		format: ".wav",
+		speak:  func(voice string, text string) []string { return []string{"-v", voice, "--", text} },
+		write: func(voice string, text string, file string) []string {
+			return []string{"-v", voice, "-w", file, "--", text}
+		},
+	},
+	{
+		name:   "say",
+		format: ".aiff",
+		speak:  func(voice string, text string) []string { return []string{"-v", voice, "--", text} },
+		write: func(voice string, text string, file string) []string {
+			return []string{"-v", voice, "-o", file, "--", text}
+		},
+	},
+}
+
+func findSpeaker() (speaker, error) {
+	for _, speaker := range defaultSpeakers {
+		if _, err := exec.LookPath(speaker.name); err == nil {
+			return speaker, nil
+		}
+	}
+	return speaker{}, errors.New("no text to speech program found, install espeak-ng")
+}
+
+// Speech is kept by the text and the voice, so questions asked again
+// play at once. Empty when there is nowhere to keep it.
+func speechCachePath(speaker speaker, voice string, text string) string {
+	cache := cacheDirectory()
+	if cache == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(speaker.name + "\x00" + voice + "\x00" + text))
+	return filepath.Join(cache, "speech", hex.EncodeToString(hash[:16])+speaker.format)
+}
+
+// Written under a temporary name first, so an interrupted
+// speaker never leaves a cut off file in the cache
+func cacheSpeech(speaker speaker, voice string, text string, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	temp := path + ".tmp" + speaker.format
+	defer os.Remove(temp) // No-op once renamed
+	if err := exec.Command(speaker.name, speaker.write(voice, text, temp)...).Run(); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
+
+// Cached speech is played by the audio player, without a cache
+// or when caching fails the speaker speaks the text itself
+func speak(text string) tea.Cmd {
+	voice := speechVoice
+	return func() tea.Msg {
+		speaker, err := findSpeaker()
+		if err != nil {
+			return commandFailedMessage{"speech", err}
+		}
+		if path := speechCachePath(speaker, voice, text); path != "" {
+			if !fileExists(path) {
+				err = cacheSpeech(speaker, voice, text, path)
+			}
+			if err == nil {
+				slog.Debug("Playing speech", "text", text, "voice", voice, "file", path)
+				return playClip(path)()
+			}
+			slog.Warn("Failed to cache speech", "text", text, "voice", voice, "error", err)
+		}
+		slog.Debug("Speaking", "text", text, "voice", voice, "speaker", speaker.name)
+		if err := exec.Command(speaker.name, speaker.speak(voice, text)...).Run(); err != nil {
+			return commandFailedMessage{"speech", fmt.Errorf("could not speak %q: %w", text, err)}
+		}
+		return nil
+	}
+}
+
+// Forms without a clip are spoken when the deck declares a voice,
+// only once answered, as speaking the answer before would give it away
+func (screen quizScreen) canSpeak() bool {
+	return speechVoice != "" && screen.mode == validation
+}
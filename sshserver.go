@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	// Any key claims a profile nobody connected as yet, so
+	// only this machine can connect unless asked otherwise
+	defaultSSHAddress = "127.0.0.1:23234"
+	// Public key remembered for each profile, next to its statistics
+	profileKeyFile = "ssh_key.pub"
+	// Disconnecting sends the quiz SIGHUP, which saves progress before quitting
+	sshSaveTimeout = 10 * time.Second
+)
+
+// Variables of the client worth passing on to the quiz, the rest
+// (GEM2_STATS in particular) could reach files of other users
+var sshClientVariables = []string{"LANG", "LC_ALL", "LC_CTYPE", "COLORTERM", "NO_COLOR"}
+
+// The first key a user connects with becomes the only one
+// accepted for their profile, so that nobody else can practice
+// in their name. Forgetting it is done by deleting the key file.
+// Nothing is remembered in read-only mode, so any key is accepted.
+type profileKeys struct {
+	mutex sync.Mutex
+}
+
+func (keys *profileKeys) authorize(ctx ssh.Context, key ssh.PublicKey) bool {
+	user := ctx.User()
+	if err := validateProfileName(user); err != nil {
+		slog.Warn("Rejected SSH user", "user", user, "error", err)
+		return false
+	}
+	keys.mutex.Lock()
+	defer keys.mutex.Unlock()
+	directory := filepath.Join(profilesDirectory, user)
+	path := filepath.Join(directory, profileKeyFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && readOnly {
+		return true
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(directory, 0755); err != nil {
+			slog.Error("Failed to create profile directory", "path", directory, "error", err)
+			return false
+		}
+		if err := os.WriteFile(path, gossh.MarshalAuthorizedKey(key), 0644); err != nil {
+			slog.Error("Failed to remember SSH key", "path", path, "error", err)
+			return false
+		}
+		slog.Info("Remembered SSH key of new user", "user", user, "fingerprint", gossh.FingerprintSHA256(key))
+		return true
+	}
+	if err != nil {
+		slog.Error("Failed to read SSH key", "path", path, "error", err)
+		return false
+	}
+	known, _, _, _, err := gossh.ParseAuthorizedKey(bytes.TrimSpace(data))
+	if err != nil {
+		slog.Error("Failed to parse SSH key", "path", path, "error", err)
+		return false
+	}
+	if !ssh.KeysEqual(key, known) {
+		slog.Warn("Rejected SSH key", "user", user, "fingerprint", gossh.FingerprintSHA256(key))
+		return false
+	}
+	return true
+}
+
+// Environment of the quiz run for a session: the server's
+// own, minus files that would override the profile, with
+// the terminal of the client instead of the server's
+func sshEnvironment(s ssh.Session, term string) []string {
+	environment := slices.DeleteFunc(os.Environ(), func(variable string) bool {
+		name, _, _ := strings.Cut(variable, "=")
+		return name == "TERM" || name == "GEM2_STATS" || name == "GEM2_MISTAKES" || slices.Contains(sshClientVariables, name)
+	})
+	for _, variable := range s.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if slices.Contains(sshClientVariables, name) {
+			environment = append(environment, variable)
+		}
+	}
+	return append(environment, "TERM="+term)
+}
+
+// Sessions whose quizzes are still running, waited for when stopping.
+// Once stopping, new sessions are turned away rather than counted,
+// the wait for the running ones may already be over.
+type sshSessions struct {
+	mutex    sync.Mutex
+	stopping bool
+	running  sync.WaitGroup
+}
+
+func (sessions *sshSessions) start() bool {
+	sessions.mutex.Lock()
+	defer sessions.mutex.Unlock()
+	if sessions.stopping {
+		return false
+	}
+	sessions.running.Add(1)
+	return true
+}
+
+func (sessions *sshSessions) stopAndWait() {
+	sessions.mutex.Lock()
+	sessions.stopping = true
+	sessions.mutex.Unlock()
+	sessions.running.Wait()
+}
+
+// Every session is a quiz process of its own, as statistics,
+// keys and the screen are kept for the whole process
+func sshHandler(executable string, quizArgs []string, sessions *sshSessions) ssh.Handler {
+	return func(s ssh.Session) {
+		if !sessions.start() {
+			wish.Fatalln(s, "The server is stopping")
+			return
+		}
+		defer sessions.running.Done()
+		pty, _, _ := s.Pty()
+		args := append([]string{"quiz", "-profile", s.User()}, quizArgs...)
+		slog.Info("SSH session started", "user", s.User(), "address", s.RemoteAddr().String())
+		err := runInSession(s, executable, args, sshEnvironment(s, pty.Term))
+		// Hung up by the client disconnecting, after saving
+		if errors.Is(err, context.Canceled) {
+			err = nil
+		}
+		if err != nil {
+			var exitErr interface{ ExitCode() int }
+			if errors.As(err, &exitErr) {
+				slog.Warn("Quiz of SSH session failed", "user", s.User(), "error", err)
+				s.Exit(exitErr.ExitCode())
+				return
+			}
+			slog.Error("Failed to start quiz for SSH session", "user", s.User(), "error", err)
+			wish.Fatalln(s, "Failed to start the quiz, see the server log")
+			return
+		}
+		slog.Info("SSH session finished", "user", s.User())
+		s.Exit(0)
+	}
+}
+
+// The SSH user name picks the profile, so that everyone in a study
+// group keeps their own statistics while sharing one deck and server
+func runSSH(args []string) {
+	flags, options := newFlagSet("ssh", "")
+	address := flags.String("addr", defaultSSHAddress, "`address` to listen on, anyone who can reach it can claim a profile, 0.0.0.0:23234 for a study group")
+	hostKey := flags.String("host-key", "", "private host key `file`, generated when missing, defaults to ssh_host_ed25519 in the state directory")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	options.apply()
+	if options.profile != "" || options.paths.stats != "" || options.paths.mistakes != "" {
+		fmt.Fprintln(os.Stderr, "Every SSH user gets their own profile, -profile, -stats and -mistakes can not be used")
+		exit(usageError)
+	}
+	if *hostKey == "" {
+		*hostKey = filepath.Join(filepath.Dir(lockPath), "ssh_host_ed25519")
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		logFatal("Failed to find own executable", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(internalError)
+	}
+	deckPath, err := filepath.Abs(wordDatabasePath)
+	if err != nil {
+		logFatal("Failed to resolve word database path", "path", wordDatabasePath, "error", err)
+		exit(databaseError)
+	}
+	quizArgs := []string{"-deck", deckPath}
+	if readOnly {
+		quizArgs = append(quizArgs, "-readonly")
+	}
+
+	var keys profileKeys
+	var sessions sshSessions
+	server, err := wish.NewServer(
+		wish.WithAddress(*address),
+		wish.WithHostKeyPath(*hostKey),
+		wish.WithPublicKeyAuth(keys.authorize),
+		// The quiz runs on a real terminal rather than an emulated one
+		ssh.AllocatePty(),
+		wish.WithMiddleware(
+			func(ssh.Handler) ssh.Handler { return sshHandler(executable, quizArgs, &sessions) },
+			activeterm.Middleware(),
+		),
+	)
+	if err != nil {
+		logFatal("Failed to set up SSH server", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(serverError)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		sig := <-signals
+		slog.Info("Received signal, stopping server", "signal", sig)
+		// Closing ends every session, which hangs up their quizzes
+		if err := server.Close(); err != nil {
+			slog.Error("Failed to stop server", "error", err)
+		}
+	}()
+
+	slog.Info("Serving quiz over SSH", "address", *address, "build", currentBuild().String())
+	fmt.Fprintf(os.Stderr, "Serving on %s, connect with ssh -p %s <name>@<host>, press ctrl+c to stop\n", *address, portOf(*address))
+	if err := server.ListenAndServe(); !errors.Is(err, ssh.ErrServerClosed) {
+		logFatal("Server failed", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(serverError)
+	}
+	// Quizzes are still saving their progress
+	sessions.stopAndWait()
+	slog.Info("Finished successfully")
+}
+
+func portOf(address string) string {
+	if _, port, err := net.SplitHostPort(address); err == nil {
+		return port
+	}
+	return "22"
+}
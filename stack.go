@@ -0,0 +1,81 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Screens are opened on top of the one they are opened from, so
+// closing one always goes back to where it was opened. Screens ask
+// for that with messages, the root model owns the stack.
+type pushScreenMessage struct {
+	screen tea.Model
+}
+
+type popScreenMessage struct{}
+
+// Closes the top screen and opens another in its place
+type replaceScreenMessage struct {
+	screen tea.Model
+}
+
+// Closes everything but the first screen before opening another,
+// for screens which lead somewhere new rather than deeper
+type resetScreensMessage struct {
+	screen tea.Model
+}
+
+func pushScreen(screen tea.Model) tea.Cmd {
+	return func() tea.Msg { return pushScreenMessage{screen} }
+}
+
+func popScreen() tea.Msg {
+	return popScreenMessage{}
+}
+
+func replaceScreen(screen tea.Model) tea.Cmd {
+	return func() tea.Msg { return replaceScreenMessage{screen} }
+}
+
+func resetScreens(screen tea.Model) tea.Cmd {
+	return func() tea.Msg { return resetScreensMessage{screen} }
+}
+
+func (m model) top() tea.Model {
+	return m.screens[len(m.screens)-1]
+}
+
+// Stack is copied, as copies of the model share its array
+func (m *model) setTop(screen tea.Model) {
+	screens := make([]tea.Model, len(m.screens))
+	copy(screens, m.screens)
+	screens[len(screens)-1] = screen
+	m.screens = screens
+}
+
+func (m *model) push(screen tea.Model) tea.Cmd {
+	m.screens = append(m.screens[:len(m.screens):len(m.screens)], screen)
+	return screen.Init()
+}
+
+// First screen is never closed, there would be nothing to show
+func (m *model) pop() {
+	if len(m.screens) > 1 {
+		m.screens = m.screens[:len(m.screens)-1]
+	}
+}
+
+func (m model) updateStack(msg tea.Msg) (model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pushScreenMessage:
+		return m, m.push(msg.screen)
+	case popScreenMessage:
+		m.pop()
+	case replaceScreenMessage:
+		m.setTop(msg.screen)
+		return m, msg.screen.Init()
+	case resetScreensMessage:
+		m.screens = m.screens[:1:1]
+		return m, m.push(msg.screen)
+	}
+	return m, nil
+}
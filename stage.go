@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+
+	"github.com/kligunov-id/gem2/scheduler"
+)
+
+// Where a question is in its life: not yet asked, reviewed daily,
+// reviewed weekly and more rarely, at the longest review interval,
+// or taken out of the schedule by suspending it
+type maturityStage int
+
+const (
+	stageNew maturityStage = iota
+	stageLearning
+	stageYoung
+	stageMature
+	stageRetired
+	stageCount
+)
+
+var maturityStageNames = [...]string{"new", "learning", "young", "mature", "retired"}
+
+func (stage maturityStage) String() string {
+	return maturityStageNames[stage]
+}
+
+// Streak decides the review interval, so the stage follows it
+func stageOf(record questionStats) maturityStage {
+	switch {
+	case record.Suspended:
+		return stageRetired
+	case !scheduler.IsStarted(record):
+		return stageNew
+	case record.Streak >= masteredStreak:
+		return stageMature
+	case record.Streak >= matureStreak:
+		return stageYoung
+	}
+	return stageLearning
+}
+
+// Colors of the theme, so that user themes color stages too
+func (stage maturityStage) color() lipgloss.TerminalColor {
+	switch stage {
+	case stageNew:
+		return highlightColor
+	case stageLearning:
+		return accentColor
+	case stageYoung:
+		return textColor
+	case stageMature:
+		return recordColor
+	}
+	return mutedColor
+}
+
+func (stage maturityStage) render(style lipgloss.Style) string {
+	return style.Foreground(stage.color()).Render(stage.String())
+}
+
+// Doubles as the legend of the colors of the lists. Every part
+// is styled on its own, a style reset inside would end the outer one.
+func renderStageCounts(counts [stageCount]uint32) string {
+	textStyle := background.Foreground(textColor)
+	var rendered strings.Builder
+	for stage := range stageCount {
+		if stage > 0 {
+			rendered.WriteString(textStyle.Render(", "))
+		}
+		rendered.WriteString(textStyle.Bold(true).Render(fmt.Sprint(counts[stage])))
+		rendered.WriteString(textStyle.Render(" ") + stage.render(background))
+	}
+	return rendered.String()
+}
@@ -0,0 +1,251 @@
+package stats
+
+import (
+	"cmp"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/kligunov-id/gem2/deck"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+type RecordTOML struct {
+	FormClue string
+	Verb     string
+	Streak   uint32
+	Correct  uint32
+	Mistakes uint32
+	Answer   string
+	// Timestamps are optional in the file since
+	// records written by older versions lack them
+	FirstSeen     time.Time         `toml:",omitzero"`
+	LastPracticed time.Time         `toml:",omitzero"`
+	BestStreak    uint32            `toml:",omitempty"`
+	WrongAnswers  map[string]uint32 `toml:",omitempty,inline"`
+	Suspended     bool              `toml:",omitempty"`
+	// Left out of records written before response times were kept
+	ResponseSeconds float32 `toml:",omitempty"`
+}
+
+func FromTOML(data RecordTOML) Record {
+	return Record{
+		Streak:        data.Streak,
+		Correct:       data.Correct,
+		Mistakes:      data.Mistakes,
+		FirstSeen:     data.FirstSeen,
+		LastPracticed: data.LastPracticed,
+		// Records written before best streaks were tracked
+		// still know that the current streak was achieved
+		BestStreak:      max(data.BestStreak, data.Streak),
+		WrongAnswers:    data.WrongAnswers,
+		Suspended:       data.Suspended,
+		ResponseSeconds: data.ResponseSeconds,
+	}
+}
+
+func (record Record) ToTOML(prompt deck.Prompt, answer string) RecordTOML {
+	return RecordTOML{
+		FormClue:      prompt.FormClue,
+		Verb:          prompt.Verb,
+		Streak:        record.Streak,
+		Correct:       record.Correct,
+		Mistakes:      record.Mistakes,
+		Answer:        answer,
+		FirstSeen:     record.FirstSeen,
+		LastPracticed: record.LastPracticed,
+		BestStreak:    record.BestStreak,
+		WrongAnswers:  record.WrongAnswers,
+		Suspended:     record.Suspended,
+		// Milliseconds are more than a difficulty estimate needs
+		ResponseSeconds: float32(math.Round(float64(record.ResponseSeconds)*10) / 10),
+	}
+}
+
+type SessionRecordsTOML struct {
+	BestSessionStreak uint32
+}
+
+// Records are inline tables, one per line, as the decoder
+// slows down quadratically with the number of table headers
+type BlockTOML struct {
+	Prompts []RecordTOML `toml:",inline,multiline"`
+}
+
+type headerTOML struct {
+	Version         int64
+	JournalSequence uint64        `toml:",omitempty"`
+	Pinned          []deck.Prompt `toml:",inline,multiline,omitempty"`
+	Records         SessionRecordsTOML
+}
+
+type FileTOML struct {
+	Version int64
+	// Last answer of the journal the records include, files
+	// of builds without numbered journal entries have none
+	JournalSequence uint64 `toml:",omitempty"`
+	// Left out while nothing is pinned, files of older builds have none
+	Pinned     []deck.Prompt `toml:",inline,multiline,omitempty"`
+	Records    SessionRecordsTOML
+	Statistics []BlockTOML
+}
+
+// Clues and verbs repeat across thousands of records,
+// so every distinct one is kept only once
+func (file FileTOML) PromptRecords() map[deck.Prompt]RecordTOML {
+	records := make(map[deck.Prompt]RecordTOML)
+	interned := make(map[string]string)
+	intern := func(s string) string {
+		if existing, exists := interned[s]; exists {
+			return existing
+		}
+		interned[s] = s
+		return s
+	}
+	for _, block := range file.Statistics {
+		for _, data := range block.Prompts {
+			data.FormClue, data.Verb = intern(data.FormClue), intern(data.Verb)
+			records[deck.Prompt{FormClue: data.FormClue, Verb: data.Verb}] = data
+		}
+	}
+	return records
+}
+
+func (statistics *Database) Expand(file FileTOML) {
+	slog.Debug("Updating statistics with content from file")
+	statistics.BestSessionStreak = file.Records.BestSessionStreak
+	statistics.JournalSequence = file.JournalSequence
+	statistics.Pinned = file.Pinned
+	for prompt, data := range file.PromptRecords() {
+		_, exists := statistics.index[prompt]
+		if !exists {
+			statistics.DeadRecords[prompt] = data
+			continue
+		}
+		// Edited answers are most often typo fixes, so statistics
+		// are kept until the user decides otherwise
+		if data.Answer != statistics.Answer(prompt) {
+			statistics.ChangedAnswers = append(
+				statistics.ChangedAnswers,
+				AnswerChange{prompt, data.Answer},
+			)
+		}
+		statistics.UpdateStats(prompt, FromTOML(data))
+	}
+	if len(statistics.DeadRecords) > 0 {
+		slog.Info(
+			"Some questions no longer exist, ignoring statistics for them",
+			"count", len(statistics.DeadRecords),
+		)
+	}
+	if len(statistics.ChangedAnswers) > 0 {
+		slog.Warn(
+			"Some questions have their answer changed, keeping statistics for them",
+			"count", len(statistics.ChangedAnswers),
+		)
+	}
+}
+
+// Prompts are written in blocks of this many, in the order of the deck
+const blockSize = 1024
+
+// Encoded blocks stay valid until a record in them changes, so
+// saving after a few answers encodes only a block or two
+type blockCache struct {
+	encoded [][]byte
+	valid   []bool
+}
+
+func newBlockCache(prompts int) *blockCache {
+	blocks := (prompts + blockSize - 1) / blockSize
+	return &blockCache{
+		encoded: make([][]byte, blocks),
+		valid:   make([]bool, blocks),
+	}
+}
+
+func (cache *blockCache) invalidate(promptIndex int) {
+	cache.valid[promptIndex/blockSize] = false
+}
+
+func encodeBlock(records []RecordTOML) ([]byte, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	encoded, err := toml.Marshal(struct{ Statistics []BlockTOML }{[]BlockTOML{{records}}})
+	if err != nil {
+		return nil, err
+	}
+	// Blank line between blocks, as between other tables
+	return append([]byte("\n"), encoded...), nil
+}
+
+func (statistics *Database) encodeBlock(block int) ([]byte, error) {
+	var records []RecordTOML
+	end := min((block+1)*blockSize, len(statistics.prompts))
+	for i := block * blockSize; i < end; i++ {
+		record := statistics.records[i]
+		// Questions never answered are left out of the file,
+		// unless suspended before they were ever asked
+		if record.Correct == 0 && record.Mistakes == 0 && !record.Suspended {
+			continue
+		}
+		records = append(records, record.ToTOML(statistics.prompts[i], statistics.answers[i]))
+	}
+	return encodeBlock(records)
+}
+
+// Contents of the statistics file, blocks of the deck
+// followed by the records of questions no longer in it
+func (statistics *Database) Encode() ([]byte, error) {
+	encoded, err := toml.Marshal(headerTOML{
+		Version:         Version,
+		JournalSequence: statistics.JournalSequence,
+		Pinned:          statistics.Pinned,
+		Records:         SessionRecordsTOML{BestSessionStreak: statistics.BestSessionStreak},
+	})
+	if err != nil {
+		return nil, err
+	}
+	cache := statistics.blocks
+	for block := range cache.encoded {
+		if !cache.valid[block] {
+			if cache.encoded[block], err = statistics.encodeBlock(block); err != nil {
+				return nil, err
+			}
+			cache.valid[block] = true
+		}
+		encoded = append(encoded, cache.encoded[block]...)
+	}
+	deadBlock, err := encodeBlock(sortedRecords(statistics.DeadRecords))
+	if err != nil {
+		return nil, err
+	}
+	return append(encoded, deadBlock...), nil
+}
+
+func sortedRecords(records map[deck.Prompt]RecordTOML) []RecordTOML {
+	sorted := make([]RecordTOML, 0, len(records))
+	for _, data := range records {
+		sorted = append(sorted, data)
+	}
+	slices.SortFunc(sorted, func(a RecordTOML, b RecordTOML) int {
+		return cmp.Or(cmp.Compare(a.FormClue, b.FormClue), cmp.Compare(a.Verb, b.Verb))
+	})
+	return sorted
+}
+
+// Statistics file of the records alone, such as those
+// retired from statistics, which imports back like any other
+func EncodeRecords(records map[deck.Prompt]RecordTOML) ([]byte, error) {
+	encoded, err := toml.Marshal(headerTOML{Version: Version})
+	if err != nil {
+		return nil, err
+	}
+	block, err := encodeBlock(sortedRecords(records))
+	if err != nil {
+		return nil, err
+	}
+	return append(encoded, block...), nil
+}
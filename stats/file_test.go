@@ -0,0 +1,116 @@
+package stats
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+func testQuestions(count int) []deck.Question {
+	questions := make([]deck.Question, count)
+	for i := range questions {
+		verb := fmt.Sprintf("verb%d", i)
+		questions[i] = deck.Question{Prompt: deck.Prompt{FormClue: "ich", Verb: verb}, Answer: verb + "e"}
+	}
+	return questions
+}
+
+func reload(t *testing.T, questions []deck.Question, encoded []byte) Database {
+	t.Helper()
+	file, version, err := Parse(encoded)
+	if err != nil {
+		t.Fatalf("Parse of encoded statistics failed: %v", err)
+	}
+	if version != Version {
+		t.Errorf("encoded version %d, want %d", version, Version)
+	}
+	statistics := New(questions)
+	statistics.Expand(file)
+	return statistics
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	practiced := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	// More than a block, so that records span several
+	questions := testQuestions(blockSize + 10)
+	statistics := New(questions)
+	first, last := questions[0].Prompt, questions[len(questions)-1].Prompt
+	statistics.ContinueStreakAt(first, practiced)
+	statistics.ContinueStreakAt(first, practiced)
+	statistics.EndStreakAt(last, "falsch", practiced)
+	statistics.Suspend(questions[5].Prompt, true)
+	statistics.TogglePin(last)
+	statistics.RecordSessionStreak(4)
+	statistics.JournalSequence = 12
+	dead := deck.Prompt{FormClue: "du", Verb: "gehen"}
+	statistics.DeadRecords[dead] = RecordTOML{FormClue: "du", Verb: "gehen", Correct: 1, Answer: "gehst"}
+
+	encoded, err := statistics.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	loaded := reload(t, questions, encoded)
+	for prompt, record := range statistics.All() {
+		if got := loaded.Record(prompt); !reflect.DeepEqual(got, record) {
+			t.Errorf("%s: loaded %+v, want %+v", prompt, got, record)
+		}
+	}
+	if !maps.EqualFunc(loaded.DeadRecords, statistics.DeadRecords, func(a RecordTOML, b RecordTOML) bool {
+		return reflect.DeepEqual(a, b)
+	}) {
+		t.Errorf("dead records %v, want %v", loaded.DeadRecords, statistics.DeadRecords)
+	}
+	if loaded.BestSessionStreak != 4 || loaded.JournalSequence != 12 {
+		t.Errorf("best session streak %d and journal sequence %d, want 4 and 12",
+			loaded.BestSessionStreak, loaded.JournalSequence)
+	}
+	if !reflect.DeepEqual(loaded.Pinned, []deck.Prompt{last}) {
+		t.Errorf("pinned %v, want %v", loaded.Pinned, []deck.Prompt{last})
+	}
+	if len(loaded.ChangedAnswers) != 0 {
+		t.Errorf("changed answers %v, want none", loaded.ChangedAnswers)
+	}
+}
+
+// Blocks are cached between saves, a change
+// must still reach the next encoding
+func TestEncodeAfterChange(t *testing.T) {
+	questions := testQuestions(3)
+	statistics := New(questions)
+	prompt := questions[1].Prompt
+	if _, err := statistics.Encode(); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	statistics.ContinueStreak(prompt)
+	encoded, err := statistics.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if record := reload(t, questions, encoded).Record(prompt); record.Correct != 1 {
+		t.Errorf("reloaded %+v, want the answer given after the first encoding", record)
+	}
+}
+
+func TestExpandKeepsChangedAnswers(t *testing.T) {
+	questions := testQuestions(1)
+	statistics := New(questions)
+	prompt := questions[0].Prompt
+	statistics.ContinueStreak(prompt)
+	encoded, err := statistics.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	questions[0].Answer = "edited"
+	loaded := reload(t, questions, encoded)
+	if record := loaded.Record(prompt); record.Correct != 1 {
+		t.Errorf("record %+v, want statistics kept for the edited answer", record)
+	}
+	want := []AnswerChange{{prompt, "verb0e"}}
+	if !reflect.DeepEqual(loaded.ChangedAnswers, want) {
+		t.Errorf("changed answers %v, want %v", loaded.ChangedAnswers, want)
+	}
+}
@@ -0,0 +1,244 @@
+package stats
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+type MergeConflict struct {
+	Prompt      deck.Prompt
+	LocalAnswer string
+	OtherAnswer string
+}
+
+type MergeReport struct {
+	Merged      int
+	Added       int
+	DeadRecords int
+	Conflicts   []MergeConflict
+}
+
+func MergeRecords(local Record, other Record) Record {
+	// Counters are plain sums, while the streak can not be
+	// reconstructed from two histories without timestamps,
+	// so the more optimistic one is kept
+	return Record{
+		Streak:          max(local.Streak, other.Streak),
+		Correct:         local.Correct + other.Correct,
+		Mistakes:        local.Mistakes + other.Mistakes,
+		FirstSeen:       earliestTime(local.FirstSeen, other.FirstSeen),
+		LastPracticed:   latestTime(local.LastPracticed, other.LastPracticed),
+		BestStreak:      max(local.BestStreak, other.BestStreak),
+		WrongAnswers:    mergeWrongAnswers(local.WrongAnswers, other.WrongAnswers, nil),
+		Suspended:       local.Suspended || other.Suspended,
+		ResponseSeconds: mergeResponseSeconds(local.ResponseSeconds, other.ResponseSeconds),
+	}
+}
+
+// Zero means never timed and is ignored
+func mergeResponseSeconds(a float32, b float32) float32 {
+	if a == 0 || b == 0 {
+		return max(a, b)
+	}
+	return (a + b) / 2
+}
+
+// Pins of other plus those pinned in local since base, without those
+// unpinned in local since base, nil base keeps the pins of both
+func mergePins(local []deck.Prompt, other []deck.Prompt, base []deck.Prompt) []deck.Prompt {
+	var merged []deck.Prompt
+	for _, prompt := range other {
+		if slices.Contains(base, prompt) && !slices.Contains(local, prompt) {
+			continue
+		}
+		merged = append(merged, prompt)
+	}
+	for _, prompt := range local {
+		if !slices.Contains(merged, prompt) && !slices.Contains(base, prompt) {
+			merged = append(merged, prompt)
+		}
+	}
+	return merged
+}
+
+// Counts of other plus those grown in local since base,
+// nil base adds up the counts of both
+func mergeWrongAnswers(local map[string]uint32, other map[string]uint32, base map[string]uint32) map[string]uint32 {
+	if len(local) == 0 && len(other) == 0 {
+		return nil
+	}
+	merged := maps.Clone(other)
+	if merged == nil {
+		merged = map[string]uint32{}
+	}
+	for answer, count := range local {
+		if grown := grownSince(count, base[answer]); grown > 0 {
+			merged[answer] += grown
+		}
+	}
+	return merged
+}
+
+func mergeDeadRecord(local RecordTOML, other RecordTOML) RecordTOML {
+	prompt := deck.Prompt{FormClue: local.FormClue, Verb: local.Verb}
+	return MergeRecords(FromTOML(local), FromTOML(other)).ToTOML(prompt, local.Answer)
+}
+
+// Zero time means unknown and is ignored
+func earliestTime(a time.Time, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+func latestTime(a time.Time, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// Questions whose answers differ between the two are left as they are
+func (statistics *Database) Merge(file FileTOML) MergeReport {
+	slog.Info("Merging statistics from another file")
+	var report MergeReport
+	statistics.RecordSessionStreak(file.Records.BestSessionStreak)
+	statistics.Pinned = mergePins(statistics.Pinned, file.Pinned, nil)
+	for prompt, data := range file.PromptRecords() {
+		local, exists := statistics.Lookup(prompt)
+		if !exists {
+			deadRecord, isDead := statistics.DeadRecords[prompt]
+			if isDead && deadRecord.Answer != data.Answer {
+				report.Conflicts = append(
+					report.Conflicts,
+					MergeConflict{prompt, deadRecord.Answer, data.Answer},
+				)
+				continue
+			}
+			if isDead {
+				data = mergeDeadRecord(deadRecord, data)
+			}
+			statistics.DeadRecords[prompt] = data
+			report.DeadRecords++
+			continue
+		}
+		if data.Answer != statistics.Answer(prompt) {
+			report.Conflicts = append(
+				report.Conflicts,
+				MergeConflict{prompt, statistics.Answer(prompt), data.Answer},
+			)
+			continue
+		}
+		if local.Correct == 0 && local.Mistakes == 0 {
+			report.Added++
+		} else {
+			report.Merged++
+		}
+		statistics.UpdateStats(prompt, MergeRecords(local, FromTOML(data)))
+	}
+	slog.Info(
+		"Merged statistics",
+		"merged", report.Merged,
+		"added", report.Added,
+		"deadRecords", report.DeadRecords,
+		"conflicts", len(report.Conflicts),
+	)
+	return report
+}
+
+// Counters grown since base, never negative so that
+// a record reset locally does not take away from others
+func grownSince(local uint32, base uint32) uint32 {
+	if local < base {
+		return 0
+	}
+	return local - base
+}
+
+// Answers given here since base are added on top of other, for
+// records which both started from base, like two devices syncing
+// through a shared copy. Streaks are those practiced last.
+func RebaseRecord(local Record, base Record, other Record) Record {
+	streak := other.Streak
+	if local.LastPracticed.After(other.LastPracticed) {
+		streak = local.Streak
+	}
+	// Suspending or resuming here since base wins over other
+	suspended := other.Suspended
+	if local.Suspended != base.Suspended {
+		suspended = local.Suspended
+	}
+	// Moving averages already include the answers both have in common
+	responseSeconds := other.ResponseSeconds
+	if local.ResponseSeconds != base.ResponseSeconds {
+		responseSeconds = mergeResponseSeconds(local.ResponseSeconds, other.ResponseSeconds)
+	}
+	return Record{
+		Streak:          streak,
+		Correct:         other.Correct + grownSince(local.Correct, base.Correct),
+		Mistakes:        other.Mistakes + grownSince(local.Mistakes, base.Mistakes),
+		FirstSeen:       earliestTime(local.FirstSeen, other.FirstSeen),
+		LastPracticed:   latestTime(local.LastPracticed, other.LastPracticed),
+		BestStreak:      max(local.BestStreak, other.BestStreak),
+		WrongAnswers:    mergeWrongAnswers(local.WrongAnswers, other.WrongAnswers, base.WrongAnswers),
+		Suspended:       suspended,
+		ResponseSeconds: responseSeconds,
+	}
+}
+
+// Like Merge, but for a file which diverged from this database
+// since base instead of being kept separately, so that answers
+// both already have in common are not counted twice
+func (statistics *Database) Rebase(base FileTOML, other FileTOML) MergeReport {
+	slog.Info("Rebasing statistics onto another file")
+	var report MergeReport
+	statistics.RecordSessionStreak(other.Records.BestSessionStreak)
+	statistics.Pinned = mergePins(statistics.Pinned, other.Pinned, base.Pinned)
+	baseRecords := base.PromptRecords()
+	for prompt, data := range other.PromptRecords() {
+		baseRecord := FromTOML(baseRecords[prompt])
+		local, exists := statistics.Lookup(prompt)
+		if !exists {
+			deadRecord, isDead := statistics.DeadRecords[prompt]
+			if isDead && deadRecord.Answer != data.Answer {
+				report.Conflicts = append(
+					report.Conflicts,
+					MergeConflict{prompt, deadRecord.Answer, data.Answer},
+				)
+				continue
+			}
+			if isDead {
+				data = RebaseRecord(FromTOML(deadRecord), baseRecord, FromTOML(data)).ToTOML(prompt, data.Answer)
+			}
+			statistics.DeadRecords[prompt] = data
+			report.DeadRecords++
+			continue
+		}
+		if data.Answer != statistics.Answer(prompt) {
+			report.Conflicts = append(
+				report.Conflicts,
+				MergeConflict{prompt, statistics.Answer(prompt), data.Answer},
+			)
+			continue
+		}
+		if local.Correct == 0 && local.Mistakes == 0 {
+			report.Added++
+		} else {
+			report.Merged++
+		}
+		statistics.UpdateStats(prompt, RebaseRecord(local, baseRecord, FromTOML(data)))
+	}
+	slog.Info(
+		"Rebased statistics",
+		"merged", report.Merged,
+		"added", report.Added,
+		"deadRecords", report.DeadRecords,
+		"conflicts", len(report.Conflicts),
+	)
+	return report
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+func TestRebaseRecord(t *testing.T) {
+	earlier := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	base := Record{Streak: 1, Correct: 2, Mistakes: 1, LastPracticed: earlier, BestStreak: 1}
+	local := Record{Streak: 3, Correct: 4, Mistakes: 1, LastPracticed: later, BestStreak: 3}
+	other := Record{Streak: 0, Correct: 3, Mistakes: 2, LastPracticed: earlier, BestStreak: 2}
+
+	rebased := RebaseRecord(local, base, other)
+	// Two correct answers given here since base, on top of other's three
+	if rebased.Correct != 5 || rebased.Mistakes != 2 {
+		t.Errorf("counters %d/%d, want 5/2", rebased.Correct, rebased.Mistakes)
+	}
+	if rebased.Streak != 3 || !rebased.LastPracticed.Equal(later) {
+		t.Errorf("streak %d practiced %v, want the streak practiced last", rebased.Streak, rebased.LastPracticed)
+	}
+	if rebased.BestStreak != 3 {
+		t.Errorf("best streak %d, want 3", rebased.BestStreak)
+	}
+
+	// Reset here since base, which takes nothing from other
+	reset := RebaseRecord(Record{}, base, other)
+	if reset.Correct != other.Correct || reset.Mistakes != other.Mistakes {
+		t.Errorf("counters %d/%d, want other's %d/%d", reset.Correct, reset.Mistakes, other.Correct, other.Mistakes)
+	}
+}
+
+func TestRebaseRecordSuspension(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, local, other bool
+		wantSuspended      bool
+	}{
+		{"unchanged here", false, false, true, true},
+		{"suspended here", false, true, false, true},
+		{"resumed here", true, false, true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rebased := RebaseRecord(
+				Record{Suspended: test.local},
+				Record{Suspended: test.base},
+				Record{Suspended: test.other},
+			)
+			if rebased.Suspended != test.wantSuspended {
+				t.Errorf("suspended %v, want %v", rebased.Suspended, test.wantSuspended)
+			}
+		})
+	}
+}
+
+func TestRebase(t *testing.T) {
+	questions := testQuestions(3)
+	kept, added, conflicting := questions[0].Prompt, questions[1].Prompt, questions[2].Prompt
+	gone := deck.Prompt{FormClue: "du", Verb: "gehen"}
+	file := func(records ...RecordTOML) FileTOML {
+		return FileTOML{Version: Version, Statistics: []BlockTOML{{records}}}
+	}
+	base := file(RecordTOML{FormClue: "ich", Verb: "verb0", Correct: 2, Answer: "verb0e"})
+	other := file(
+		RecordTOML{FormClue: "ich", Verb: "verb0", Correct: 5, Answer: "verb0e"},
+		RecordTOML{FormClue: "ich", Verb: "verb1", Correct: 1, Answer: "verb1e"},
+		RecordTOML{FormClue: "ich", Verb: "verb2", Correct: 1, Answer: "other"},
+		RecordTOML{FormClue: "du", Verb: "gehen", Correct: 1, Answer: "gehst"},
+	)
+	other.Records.BestSessionStreak = 9
+
+	statistics := New(questions)
+	statistics.UpdateStats(kept, Record{Correct: 3})
+	report := statistics.Rebase(base, other)
+
+	if report.Merged != 1 || report.Added != 1 || report.DeadRecords != 1 || len(report.Conflicts) != 1 {
+		t.Fatalf("report %+v, want one merged, added, dead record and conflict", report)
+	}
+	if report.Conflicts[0] != (MergeConflict{conflicting, "verb2e", "other"}) {
+		t.Errorf("conflict %+v, want %s answered differently", report.Conflicts[0], conflicting)
+	}
+	// One answer given here since base, on top of other's five
+	if correct := statistics.Record(kept).Correct; correct != 6 {
+		t.Errorf("%s correct %d, want 6", kept, correct)
+	}
+	if correct := statistics.Record(added).Correct; correct != 1 {
+		t.Errorf("%s correct %d, want 1", added, correct)
+	}
+	if statistics.Record(conflicting).Correct != 0 {
+		t.Errorf("%s took statistics of a different answer", conflicting)
+	}
+	if _, exists := statistics.DeadRecords[gone]; !exists {
+		t.Errorf("%s missing from dead records", gone)
+	}
+	if statistics.BestSessionStreak != 9 {
+		t.Errorf("best session streak %d, want 9", statistics.BestSessionStreak)
+	}
+}
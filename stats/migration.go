@@ -0,0 +1,171 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Version of the statistics file format written by this build,
+// bump it together with appending a migration below
+const Version = 4
+
+// Migration at index i converts a raw document
+// of version i into a document of version i+1
+type migration func(document map[string]any) error
+
+var migrations = [Version]migration{
+	migrateV0,
+	migrateV1,
+	migrateV2,
+	migrateV3,
+}
+
+// Files written before versioning had no version field,
+// any later additions to them are optional fields
+func migrateV0(document map[string]any) error {
+	return nil
+}
+
+// Version 1 keyed statistics by "formClue+verb", which could not
+// represent prompts containing the separator, version 2 nests
+// them as Statistics.formClue.verb instead
+func migrateV1(document map[string]any) error {
+	rawStatistics, exists := document["Statistics"]
+	if !exists {
+		return nil
+	}
+	statistics, isTable := rawStatistics.(map[string]any)
+	if !isTable {
+		return fmt.Errorf("statistics is not a table")
+	}
+	nested := make(map[string]any)
+	for encodedPrompt, data := range statistics {
+		// The old decoder rejected keys with several separators,
+		// so such files could not have been loaded anyway
+		formClue, verb, found := strings.Cut(encodedPrompt, "+")
+		if !found {
+			return fmt.Errorf("invalid key %q", encodedPrompt)
+		}
+		if strings.Contains(verb, "+") {
+			slog.Warn("Ambiguous statistics key", "key", encodedPrompt, "verb", verb)
+		}
+		verbs, exists := nested[formClue].(map[string]any)
+		if !exists {
+			verbs = make(map[string]any)
+			nested[formClue] = verbs
+		}
+		verbs[verb] = data
+	}
+	document["Statistics"] = nested
+	return nil
+}
+
+// Version 3 widened counters from 16 to 32 bits, old values fit as is.
+// The bump only makes older builds refuse files they would fail to parse.
+func migrateV2(document map[string]any) error {
+	return nil
+}
+
+// Version 4 lists records as inline tables in blocks, since a table
+// per prompt made parsing huge decks take minutes. Migrating such
+// a file is slow once, the records end up in a single block.
+func migrateV3(document map[string]any) error {
+	rawStatistics, exists := document["Statistics"]
+	if !exists {
+		return nil
+	}
+	statistics, isTable := rawStatistics.(map[string]any)
+	if !isTable {
+		return fmt.Errorf("statistics is not a table")
+	}
+	var records []any
+	for formClue, rawVerbs := range statistics {
+		verbs, isTable := rawVerbs.(map[string]any)
+		if !isTable {
+			return fmt.Errorf("statistics for %q is not a table", formClue)
+		}
+		for verb, rawData := range verbs {
+			data, isTable := rawData.(map[string]any)
+			if !isTable {
+				return fmt.Errorf("statistics for %q + %q is not a table", formClue, verb)
+			}
+			data["FormClue"] = formClue
+			data["Verb"] = verb
+			records = append(records, data)
+		}
+	}
+	document["Statistics"] = []any{map[string]any{"Prompts": records}}
+	return nil
+}
+
+// Files of newer builds are not damaged, they are not to be
+// replaced by an older backup, which would roll progress back
+var ErrNewerVersion = errors.New("statistics were written by a newer build")
+
+func documentVersion(document map[string]any) (int64, error) {
+	rawVersion, exists := document["Version"]
+	if !exists {
+		return 0, nil
+	}
+	version, isInteger := rawVersion.(int64)
+	if !isInteger || version < 0 {
+		return 0, fmt.Errorf("invalid version %v", rawVersion)
+	}
+	return version, nil
+}
+
+// Brings a document of any older version to the current one,
+// returns the version the document originally had
+func migrate(document map[string]any) (int64, error) {
+	version, err := documentVersion(document)
+	if err != nil {
+		return 0, err
+	}
+	if version > Version {
+		return version, fmt.Errorf(
+			"%w: file has version %d, but this build only supports up to %d",
+			ErrNewerVersion,
+			version,
+			Version,
+		)
+	}
+	for v := version; v < Version; v++ {
+		slog.Info("Migrating statistics", "from", v, "to", v+1)
+		if err := migrations[v](document); err != nil {
+			return version, fmt.Errorf("migration from version %d failed: %w", v, err)
+		}
+		document["Version"] = v + 1
+	}
+	return version, nil
+}
+
+// Returns the parsed file along with the version it originally had.
+// Current files are decoded directly, going through a generic
+// document only when they need migrating.
+func Parse(bytes []byte) (FileTOML, int64, error) {
+	var file FileTOML
+	if err := toml.Unmarshal(bytes, &file); err == nil && file.Version == Version {
+		return file, Version, nil
+	}
+	file = FileTOML{}
+	var document map[string]any
+	if err := toml.Unmarshal(bytes, &document); err != nil {
+		return file, 0, err
+	}
+	version, err := migrate(document)
+	if err != nil {
+		return file, version, err
+	}
+	migrated, err := toml.Marshal(document)
+	if err != nil {
+		return file, version, fmt.Errorf("re-encoding migrated statistics: %w", err)
+	}
+	if err := toml.Unmarshal(migrated, &file); err != nil {
+		return file, version, err
+	}
+	return file, version, nil
+}
@@ -0,0 +1,128 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+func TestParseMigratesOldVersions(t *testing.T) {
+	practiced := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		file    string
+		version int64
+	}{
+		{
+			name: "unversioned",
+			file: `
+[Records]
+BestSessionStreak = 7
+
+[Statistics."ich+sein"]
+Streak = 2
+Correct = 3
+Mistakes = 1
+Answer = "bin"
+LastPracticed = 2024-03-01T12:00:00Z
+`,
+			version: 0,
+		},
+		{
+			name: "keyed by clue and verb",
+			file: `
+Version = 1
+
+[Records]
+BestSessionStreak = 7
+
+[Statistics."ich+sein"]
+Streak = 2
+Correct = 3
+Mistakes = 1
+Answer = "bin"
+LastPracticed = 2024-03-01T12:00:00Z
+`,
+			version: 1,
+		},
+		{
+			name: "nested tables",
+			file: `
+Version = 3
+
+[Records]
+BestSessionStreak = 7
+
+[Statistics.ich.sein]
+Streak = 2
+Correct = 3
+Mistakes = 1
+Answer = "bin"
+LastPracticed = 2024-03-01T12:00:00Z
+`,
+			version: 3,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, version, err := Parse([]byte(test.file))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if version != test.version {
+				t.Errorf("original version %d, want %d", version, test.version)
+			}
+			if file.Version != Version {
+				t.Errorf("migrated to version %d, want %d", file.Version, Version)
+			}
+			if file.Records.BestSessionStreak != 7 {
+				t.Errorf("best session streak %d, want 7", file.Records.BestSessionStreak)
+			}
+			records := file.PromptRecords()
+			record, exists := records[deck.Prompt{FormClue: "ich", Verb: "sein"}]
+			if len(records) != 1 || !exists {
+				t.Fatalf("records %v, want only ich + sein", records)
+			}
+			if record.Streak != 2 || record.Correct != 3 || record.Mistakes != 1 || record.Answer != "bin" {
+				t.Errorf("record %+v lost counters or answer", record)
+			}
+			if !record.LastPracticed.Equal(practiced) {
+				t.Errorf("last practiced %v, want %v", record.LastPracticed, practiced)
+			}
+		})
+	}
+}
+
+func TestParseRejectsNewerVersion(t *testing.T) {
+	_, version, err := Parse([]byte("Version = 99\n"))
+	if !errors.Is(err, ErrNewerVersion) {
+		t.Fatalf("error %v, want ErrNewerVersion", err)
+	}
+	if version != 99 {
+		t.Errorf("version %d, want 99", version)
+	}
+}
+
+func TestMigrateRejectsMalformedDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		document map[string]any
+	}{
+		{"negative version", map[string]any{"Version": int64(-1)}},
+		{"version not a number", map[string]any{"Version": "4"}},
+		{"key without separator", map[string]any{
+			"Version":    int64(1),
+			"Statistics": map[string]any{"ichsein": map[string]any{}},
+		}},
+		{"statistics not a table", map[string]any{"Version": int64(3), "Statistics": "none"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := migrate(test.document); err == nil {
+				t.Errorf("migrated %v, want an error", test.document)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+// Package stats keeps how every question of a deck was answered so far
+// and reads and writes that as the statistics file.
+package stats
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/kligunov-id/gem2/deck"
+)
+
+type Record struct {
+	Streak        uint32
+	Correct       uint32
+	Mistakes      uint32
+	FirstSeen     time.Time
+	LastPracticed time.Time
+	BestStreak    uint32
+	// Distinct wrong answers and how many times each was given
+	WrongAnswers map[string]uint32
+	// Suspended questions are never picked, but keep their statistics
+	Suspended bool
+	// Moving average of the seconds taken to answer, 0 until timed once
+	ResponseSeconds float32
+}
+
+func (record *Record) MarkPracticed(now time.Time) {
+	// Sub-second precision only bloats the statistics file
+	now = now.Truncate(time.Second)
+	if record.FirstSeen.IsZero() {
+		record.FirstSeen = now
+	}
+	record.LastPracticed = now
+}
+
+// Questions answered correctly many times in a row are asked less often
+func (record Record) Weight() float32 {
+	if record.Suspended {
+		return 0
+	}
+	return 1 / (1 + float32(record.Streak))
+}
+
+// Records are kept in slices indexed in the order of the deck,
+// so huge decks need no per-prompt map besides the index itself
+type Database struct {
+	prompts []deck.Prompt
+	records []Record
+	answers []string
+	index   map[deck.Prompt]int
+	// Sum of the weights of all questions
+	totalWeight float32
+	// Encoded blocks of records, see Encode
+	blocks *blockCache
+	// These are fields present in file
+	// yet not existing in word database
+	DeadRecords       map[deck.Prompt]RecordTOML
+	BestSessionStreak uint32
+	// Questions which kept their statistics although
+	// the answer in the word database was edited
+	ChangedAnswers []AnswerChange
+	// Questions the next session starts with, in the order they were pinned
+	Pinned []deck.Prompt
+	// Number of the last answer journaled, see FileTOML
+	JournalSequence uint64
+}
+
+type AnswerChange struct {
+	Prompt    deck.Prompt
+	OldAnswer string
+}
+
+// Every question of the deck, none of them answered yet.
+// A prompt repeated in the deck keeps its last answer.
+func New(questions []deck.Question) Database {
+	statistics := Database{
+		prompts:     make([]deck.Prompt, 0, len(questions)),
+		answers:     make([]string, 0, len(questions)),
+		index:       make(map[deck.Prompt]int, len(questions)),
+		DeadRecords: map[deck.Prompt]RecordTOML{},
+	}
+	for _, question := range questions {
+		if i, exists := statistics.index[question.Prompt]; exists {
+			statistics.answers[i] = question.Answer
+			continue
+		}
+		statistics.index[question.Prompt] = len(statistics.prompts)
+		statistics.prompts = append(statistics.prompts, question.Prompt)
+		statistics.answers = append(statistics.answers, question.Answer)
+	}
+	statistics.records = make([]Record, len(statistics.prompts))
+	statistics.totalWeight = float32(len(statistics.prompts))
+	statistics.blocks = newBlockCache(len(statistics.prompts))
+	return statistics
+}
+
+func (statistics Database) TotalWeight() float32 {
+	return statistics.totalWeight
+}
+
+func (statistics Database) Len() int {
+	return len(statistics.prompts)
+}
+
+// Prompts in the order of the deck, the slice must not be modified
+func (statistics Database) Prompts() []deck.Prompt {
+	return statistics.prompts
+}
+
+func (statistics Database) Lookup(prompt deck.Prompt) (Record, bool) {
+	i, exists := statistics.index[prompt]
+	if !exists {
+		return Record{}, false
+	}
+	return statistics.records[i], true
+}
+
+// Empty record for prompts which are not in the deck
+func (statistics Database) Record(prompt deck.Prompt) Record {
+	record, _ := statistics.Lookup(prompt)
+	return record
+}
+
+func (statistics Database) Answer(prompt deck.Prompt) string {
+	i, exists := statistics.index[prompt]
+	if !exists {
+		return ""
+	}
+	return statistics.answers[i]
+}
+
+func (statistics Database) All() iter.Seq2[deck.Prompt, Record] {
+	return func(yield func(deck.Prompt, Record) bool) {
+		for i, prompt := range statistics.prompts {
+			if !yield(prompt, statistics.records[i]) {
+				return
+			}
+		}
+	}
+}
+
+func (statistics Database) Answers() iter.Seq2[deck.Prompt, string] {
+	return func(yield func(deck.Prompt, string) bool) {
+		for i, prompt := range statistics.prompts {
+			if !yield(prompt, statistics.answers[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Prompts which are not in the deck are ignored
+func (statistics *Database) UpdateStats(prompt deck.Prompt, record Record) {
+	i, exists := statistics.index[prompt]
+	if !exists {
+		return
+	}
+	statistics.totalWeight -= statistics.records[i].Weight()
+	statistics.records[i] = record
+	statistics.totalWeight += record.Weight()
+	statistics.blocks.invalidate(i)
+}
+
+func (statistics *Database) ResetStats(prompt deck.Prompt) {
+	statistics.UpdateStats(prompt, Record{})
+}
+
+func (statistics *Database) EndStreak(prompt deck.Prompt, answer string) {
+	statistics.EndStreakAt(prompt, answer, time.Now())
+}
+
+// Like EndStreak, for an answer given at another time
+func (statistics *Database) EndStreakAt(prompt deck.Prompt, answer string, at time.Time) {
+	record := statistics.Record(prompt)
+	record.Streak = 0
+	record.Mistakes++
+	// Copied, as records handed out earlier share the map
+	record.WrongAnswers = maps.Clone(record.WrongAnswers)
+	if record.WrongAnswers == nil {
+		record.WrongAnswers = map[string]uint32{}
+	}
+	record.WrongAnswers[answer]++
+	record.MarkPracticed(at)
+	statistics.UpdateStats(prompt, record)
+}
+
+// Only remembers the wrong answer, for mistakes counted before
+// wrong answers were kept, like ones imported from old logs
+func (statistics *Database) CountWrongAnswer(prompt deck.Prompt, answer string) {
+	record := statistics.Record(prompt)
+	record.WrongAnswers = maps.Clone(record.WrongAnswers)
+	if record.WrongAnswers == nil {
+		record.WrongAnswers = map[string]uint32{}
+	}
+	record.WrongAnswers[answer]++
+	statistics.UpdateStats(prompt, record)
+}
+
+// Questions known before practice started skip the way up to the
+// streak, ones never answered count as a single correct answer
+func (statistics *Database) MarkKnown(prompt deck.Prompt, streak uint32) {
+	record := statistics.Record(prompt)
+	record.Streak = max(record.Streak, streak)
+	if record.Correct == 0 && record.Mistakes == 0 {
+		record.Correct = 1
+	}
+	record.BestStreak = max(record.BestStreak, streak)
+	record.MarkPracticed(time.Now())
+	statistics.UpdateStats(prompt, record)
+}
+
+func (statistics *Database) Suspend(prompt deck.Prompt, suspended bool) {
+	record := statistics.Record(prompt)
+	record.Suspended = suspended
+	statistics.UpdateStats(prompt, record)
+}
+
+func (statistics Database) IsPinned(prompt deck.Prompt) bool {
+	return slices.Contains(statistics.Pinned, prompt)
+}
+
+// Reports whether the question is pinned now
+func (statistics *Database) TogglePin(prompt deck.Prompt) bool {
+	if statistics.IsPinned(prompt) {
+		statistics.Unpin(prompt)
+		return false
+	}
+	statistics.Pinned = append(statistics.Pinned, prompt)
+	return true
+}
+
+// Slice may be shared with a queue of pinned questions, so a new one is made
+func (statistics *Database) Unpin(prompt deck.Prompt) {
+	statistics.Pinned = slices.DeleteFunc(slices.Clone(statistics.Pinned), func(pinned deck.Prompt) bool {
+		return pinned == prompt
+	})
+}
+
+// Streak starts over without counting a mistake, so the
+// question is due at once and comes up as often as new ones
+func (statistics *Database) Boost(prompt deck.Prompt) {
+	record := statistics.Record(prompt)
+	record.Streak = 0
+	statistics.UpdateStats(prompt, record)
+}
+
+// Weight of the latest answer in the moving average of response times,
+// recent answers tell more about how hard the question is now
+const responseSmoothing = 0.3
+
+func (statistics *Database) RecordResponseTime(prompt deck.Prompt, taken time.Duration) {
+	record := statistics.Record(prompt)
+	seconds := float32(taken.Seconds())
+	if record.ResponseSeconds == 0 {
+		record.ResponseSeconds = seconds
+	} else {
+		record.ResponseSeconds += responseSmoothing * (seconds - record.ResponseSeconds)
+	}
+	statistics.UpdateStats(prompt, record)
+}
+
+// Reports whether the streak beat a previous
+// non-zero best streak for this prompt
+func (statistics *Database) ContinueStreak(prompt deck.Prompt) bool {
+	return statistics.ContinueStreakAt(prompt, time.Now())
+}
+
+// Like ContinueStreak, for an answer given at another time
+func (statistics *Database) ContinueStreakAt(prompt deck.Prompt, at time.Time) bool {
+	record := statistics.Record(prompt)
+	record.Streak++
+	record.Correct++
+	record.MarkPracticed(at)
+	isRecord := record.BestStreak > 0 && record.Streak > record.BestStreak
+	record.BestStreak = max(record.BestStreak, record.Streak)
+	statistics.UpdateStats(prompt, record)
+	return isRecord
+}
+
+// Reports whether the session streak beat a previous
+// non-zero best session streak
+func (statistics *Database) RecordSessionStreak(streak uint32) bool {
+	if streak <= statistics.BestSessionStreak {
+		return false
+	}
+	isRecord := statistics.BestSessionStreak > 0
+	statistics.BestSessionStreak = streak
+	return isRecord
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+const statusBarHeight = 1
+
+func screenMode(screen tea.Model) string {
+	switch screen := screen.(type) {
+	case homeScreen:
+		return "home"
+	case quizScreen:
+		if len(screen.replayQueue) > 0 {
+			return "replay"
+		}
+		return "quiz"
+	case *quizScreen:
+		return screenMode(*screen)
+	case menuScreen:
+		return "menu"
+	case settingsScreen:
+		return "settings"
+	case againSummaryScreen:
+		return "again"
+	case statisticsScreen:
+		return "statistics"
+	case dashboardScreen:
+		return "dashboard"
+	case sessionSummaryScreen:
+		return "session summary"
+	case heatmapScreen:
+		return "calendar"
+	case mistakesScreen:
+		return "mistakes"
+	case confusionScreen:
+		return "confusions"
+	case conjugationScreen:
+		return "conjugation"
+	case verbListScreen:
+		return "verbs"
+	case browserScreen:
+		return "browse"
+	case bulkScreen:
+		return "bulk"
+	case triageScreen:
+		return "triage"
+	case upNextScreen:
+		return "up next"
+	case noteScreen:
+		return "note"
+	case achievementsScreen:
+		return "achievements"
+	case leaderboardScreen:
+		return "leaderboard"
+	case duelSetupScreen, duelScreen, duelResultScreen:
+		return "duel"
+	case reconciliationScreen:
+		return "changed answers"
+	case deckChangesScreen:
+		return "deck changed"
+	case saveFailedScreen:
+		return "save failed"
+	case keyHelpScreen:
+		return "keys"
+	case quitConfirmScreen:
+		return "quit"
+	case loadingScreen:
+		return "loading"
+	}
+	return ""
+}
+
+func saveIndicator(unsavedAnswers int) string {
+	switch {
+	case readOnly:
+		return "read-only"
+	case unsavedAnswers > 0:
+		return fmt.Sprintf("%d unsaved", unsavedAnswers)
+	}
+	return "saved"
+}
+
+// Shown below the box on every screen
+func renderStatusBar(screen tea.Model, session *session) string {
+	style := background.Foreground(mutedColor)
+	left := style.Render(filepath.Base(wordDatabasePath) + symbols.helpSeparator + screenMode(screen))
+	if toastText != "" {
+		left = background.Foreground(highlightColor).Italic(true).Render(toastText)
+	}
+	right := saveIndicator(session.unsavedAnswers)
+	if session.isLoaded() {
+		right = renderAgainPile(session) + fmt.Sprintf("%d due", session.statistics.dueSummary(time.Now()).DueNow) + symbols.helpSeparator + right
+	}
+	right = style.Render(right)
+	// Aligned with the border of the box
+	width := layoutWidth()
+	spacing := max(width-lipgloss.Width(left)-lipgloss.Width(right), 1)
+	return lipgloss.NewStyle().MaxWidth(width).Render(left + style.Render(fmt.Sprintf("%*s", spacing, "")) + right)
+}
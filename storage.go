@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	toml "github.com/pelletier/go-toml/v2"
+
+	"github.com/kligunov-id/gem2/stats"
+)
+
+// Where statistics, history and preferences are kept between runs.
+// Every method may fail, whether that is fatal is up to the caller,
+// and read-only mode is too, stores write whenever they are asked to.
+type storage interface {
+	// Statistics of the questions of the deck, empty when none are kept yet
+	loadStatistics(database wordDatabase) (statisticsDatabase, error)
+	saveStatistics(statistics *statisticsDatabase) error
+	// Empty history when none is kept yet
+	loadHistory() (practiceHistory, error)
+	saveHistory(history practiceHistory) error
+	// Defaults when none are kept yet
+	loadPreferences() (uiPreferences, error)
+	savePreferences(preferences uiPreferences) error
+	// Kept at once, unlike statistics saved every so often, and
+	// replayed by loadStatistics if they were not saved after all
+	recordAnswer(entry journalEntry) error
+}
+
+// Store every session loads from and saves to, a deployment
+// keeping its data elsewhere replaces it before the session starts
+var store storage = fileStorage{}
+
+// TOML files in the state directory, statistics with rotated backups
+// and a journal of every answer to rebuild them from when all are lost
+type fileStorage struct{}
+
+func (fileStorage) loadStatistics(database wordDatabase) (statisticsDatabase, error) {
+	statistics := database.emptyStatistics()
+	slog.Debug("Trying to read statistics file", "path", statisticsPath)
+	// Backups are only consulted when the file itself is missing,
+	// unreadable or corrupted, not when it is of a newer build
+	foundAny := false
+	for i := 0; i <= statisticsBackups; i++ {
+		path := statisticsPath
+		if i > 0 {
+			path = backupPath(statisticsPath, i)
+		}
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				foundAny = true
+				slog.Error("Failed to read statistics file", "path", path, "error", err)
+			}
+			continue
+		}
+		foundAny = true
+		statisticsTOML, version, err := stats.Parse(bytes)
+		if errors.Is(err, stats.ErrNewerVersion) {
+			return statistics, fmt.Errorf("%s: %w, update gem2 to use it", path, err)
+		}
+		if err != nil {
+			slog.Error("Failed to parse statistics file", "path", path, "error", err)
+			continue
+		}
+		if i > 0 {
+			slog.Warn("Recovered statistics from backup", "path", path)
+		}
+		if version < stats.Version {
+			if err := backupBeforeMigration(statisticsPath, bytes, version); err != nil {
+				return statistics, err
+			}
+		}
+		statistics.Expand(statisticsTOML)
+		return statistics, statistics.replayUnsavedAnswers()
+	}
+	if foundAny {
+		// Starting from scratch, or from the journal, would overwrite
+		// the files that might still be repaired
+		err := fmt.Errorf("statistics file %s and all its backups are unusable", statisticsPath)
+		if fileExists(journalPath()) {
+			err = fmt.Errorf("%w, move them away to rebuild the statistics from %s", err, journalPath())
+		}
+		return statistics, err
+	}
+	if rebuilt, err := statistics.rebuildFromJournal(); rebuilt || err != nil {
+		return statistics, err
+	}
+	slog.Info("Statistics file not found", "path", statisticsPath)
+	return statistics, nil
+}
+
+func (fileStorage) saveStatistics(statistics *statisticsDatabase) error {
+	bytes, err := statistics.Encode()
+	if err != nil {
+		logFatal("Unachievable TOML encoding error", "error", err)
+		exit(internalError)
+	}
+	if err := writeFileAtomic(statisticsPath, bytes, statisticsBackups); err != nil {
+		slog.Error("Could not write statistics", "path", statisticsPath, "error", err)
+		return fmt.Errorf("could not write statistics: %w", err)
+	}
+	slog.Info("Statistics saved", "path", statisticsPath)
+	return nil
+}
+
+func (fileStorage) loadHistory() (practiceHistory, error) {
+	history := practiceHistory{map[string]dayRecord{}}
+	slog.Debug("Trying to read history file", "path", historyPath)
+	bytes, err := os.ReadFile(historyPath)
+	if err != nil {
+		// Missing or unreadable history only means starting a new one
+		if errors.Is(err, fs.ErrNotExist) {
+			slog.Info("History file not found", "path", historyPath)
+		} else {
+			slog.Error("Failed to read history file", "path", historyPath, "error", err)
+		}
+		return history, nil
+	}
+	return parseHistory(bytes)
+}
+
+func (fileStorage) saveHistory(history practiceHistory) error {
+	bytes, err := toml.Marshal(history.pack())
+	if err != nil {
+		logFatal("Unachievable TOML encoding error", "error", err)
+		exit(internalError)
+	}
+	if err := writeFileAtomic(historyPath, bytes, 0); err != nil {
+		slog.Error("Could not write history", "path", historyPath, "error", err)
+		return fmt.Errorf("could not write history: %w", err)
+	}
+	slog.Info("History saved", "path", historyPath)
+	return nil
+}
+
+// Without a state directory there is nowhere to keep them,
+// which is not an error, they are only a convenience
+func (fileStorage) loadPreferences() (uiPreferences, error) {
+	preferences := defaultPreferences
+	path := preferencesPath()
+	if path == "" {
+		return preferences, nil
+	}
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return preferences, nil
+		}
+		return defaultPreferences, fmt.Errorf("could not read UI preferences %s: %w", path, err)
+	}
+	if err := toml.Unmarshal(bytes, &preferences); err != nil {
+		return defaultPreferences, fmt.Errorf("could not parse UI preferences %s: %w", path, err)
+	}
+	return preferences, nil
+}
+
+func (fileStorage) savePreferences(preferences uiPreferences) error {
+	path := preferencesPath()
+	if path == "" {
+		return nil
+	}
+	bytes, err := toml.Marshal(preferences)
+	if err != nil {
+		logFatal("Unachievable TOML encoding error", "error", err)
+		exit(internalError)
+	}
+	if err := writeFileAtomic(path, bytes, 0); err != nil {
+		return fmt.Errorf("could not write UI preferences %s: %w", path, err)
+	}
+	slog.Debug("UI preferences saved", "path", path)
+	return nil
+}
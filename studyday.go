@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Hour the study day starts at, answers given before it count
+// towards the day before, so a session past midnight is not split
+var dayStartHour int
+
+// Days are counted in it, for a user practicing on machines
+// set to different time zones to still get the same days
+var dayLocation = time.Local
+
+// When the study day starts and where, given on the
+// command line or in the environment like the paths
+type dayOptions struct {
+	start    string
+	timezone string
+}
+
+func (options *dayOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&options.start, "day-starts", os.Getenv("GEM2_DAY_STARTS"), "`hour` from 0 to 23 the study day starts at, also GEM2_DAY_STARTS, midnight by default")
+	flags.StringVar(&options.timezone, "timezone", os.Getenv("GEM2_TIMEZONE"), "time `zone` days are counted in, like Europe/Berlin, also GEM2_TIMEZONE, the local one by default")
+}
+
+func (options dayOptions) apply() error {
+	if options.start != "" {
+		hour, err := strconv.Atoi(options.start)
+		if err != nil || hour < 0 || hour > 23 {
+			return fmt.Errorf("invalid day start %q, expected an hour from 0 to 23", options.start)
+		}
+		dayStartHour = hour
+	}
+	if options.timezone != "" {
+		location, err := time.LoadLocation(options.timezone)
+		if err != nil {
+			return fmt.Errorf("unknown time zone %q: %w", options.timezone, err)
+		}
+		dayLocation = location
+	}
+	return nil
+}
+
+// Start of the study day the time belongs to, which
+// is its own study day, so passing it again is harmless
+func studyDay(t time.Time) time.Time {
+	shifted := t.In(dayLocation).Add(-time.Duration(dayStartHour) * time.Hour)
+	return time.Date(shifted.Year(), shifted.Month(), shifted.Day(), dayStartHour, 0, 0, 0, dayLocation)
+}
+
+func endOfStudyDay(t time.Time) time.Time {
+	return studyDay(t).AddDate(0, 0, 1)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	lipgloss "github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Everything outside of ASCII the screens draw,
+// so that limited fonts and locales can do without
+type symbolSet struct {
+	// Counters of question statistics are told
+	// apart by these, not only by their colors
+	correct       string
+	mistake       string
+	streak        string
+	empty         string
+	heatmapCell   string
+	sparkline     []rune
+	helpSeparator string
+	times         string
+	arrow         string
+	border        lipgloss.Border
+	asciiOnlyHelp bool
+}
+
+var unicodeSymbols = symbolSet{
+	correct:       "✓",
+	mistake:       "✗",
+	streak:        "→",
+	empty:         "·",
+	heatmapCell:   "■",
+	sparkline:     []rune("▁▂▃▄▅▆▇█"),
+	helpSeparator: " • ",
+	times:         "×",
+	arrow:         "→",
+	border:        lipgloss.RoundedBorder(),
+}
+
+var asciiSymbols = symbolSet{
+	correct:       "+",
+	mistake:       "x",
+	streak:        ">",
+	empty:         ".",
+	heatmapCell:   "#",
+	sparkline:     []rune("_.-:=+*#"),
+	helpSeparator: " | ",
+	times:         "x",
+	arrow:         "->",
+	border: lipgloss.Border{
+		Top:         "-",
+		Bottom:      "-",
+		Left:        "|",
+		Right:       "|",
+		TopLeft:     "+",
+		TopRight:    "+",
+		BottomLeft:  "+",
+		BottomRight: "+",
+	},
+	// Arrow keys are still shown by their letter bindings
+	asciiOnlyHelp: true,
+}
+
+var symbols = unicodeSymbols
+
+func isASCII(text string) bool {
+	for _, r := range text {
+		if r > 127 {
+			return false
+		}
+	}
+	return true
+}
+
+// Without colors the intensity of a heatmap
+// cell is told by its symbol instead
+func heatmapCellSymbol(level int) string {
+	if lipgloss.ColorProfile() != termenv.Ascii {
+		return symbols.heatmapCell
+	}
+	// Lowest levels look too much like an empty day
+	index := len(symbols.sparkline) - len(heatmapColors) + level
+	if index < 0 {
+		index = level * len(symbols.sparkline) / len(heatmapColors)
+	}
+	return string(symbols.sparkline[index])
+}
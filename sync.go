@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kligunov-id/gem2/stats"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Git remote the progress of every device is pushed to
+type syncSettings struct {
+	Remote string `toml:"remote"`
+	Branch string `toml:"branch"`
+}
+
+const (
+	defaultSyncBranch = "main"
+	// Another device pushing in between is retried, a few times at most
+	syncAttempts = 3
+	// Commit this device last synced with, missing before the first sync
+	// even when the clone already has what other devices pushed
+	syncedRevision = "refs/gem2/synced"
+)
+
+// Names of the files in the repository, the same
+// on every device wherever they are kept locally
+const (
+	syncedStatistics  = "statistics.toml"
+	syncedHistory     = "history.toml"
+	syncedPreferences = "ui.toml"
+)
+
+func syncSettingsPath() string {
+	return filepath.Join(configDirectory(), "sync.toml")
+}
+
+func parseSyncSettings(data []byte) (syncSettings, error) {
+	settings := syncSettings{Branch: defaultSyncBranch}
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		return syncSettings{}, err
+	}
+	if settings.Remote == "" {
+		return syncSettings{}, errors.New("remote is not set")
+	}
+	return settings, nil
+}
+
+func loadSyncSettings() (syncSettings, error) {
+	path := syncSettingsPath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return syncSettings{}, fmt.Errorf("%s not found, it has to name the git remote, e.g. remote = \"git@example.com:me/gem2-progress.git\"", path)
+	}
+	if err != nil {
+		return syncSettings{}, err
+	}
+	settings, err := parseSyncSettings(data)
+	if err != nil {
+		return syncSettings{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// Working copy next to the statistics, so every profile syncs its own
+func syncDirectory() string {
+	return filepath.Join(filepath.Dir(statisticsPath), "sync")
+}
+
+type syncRepository struct {
+	directory string
+	branch    string
+}
+
+// Output of git, with what it said on errors
+// included, as that is where git explains them
+func (repository syncRepository) git(args ...string) ([]byte, error) {
+	command := exec.Command("git", append([]string{"-C", repository.directory}, args...)...)
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+func (repository syncRepository) remoteBranch() string {
+	return "origin/" + repository.branch
+}
+
+// Commits are made by gem2 on behalf of the device
+// when git does not know who the user is
+func cloneSyncRepository(remote string, repository syncRepository) error {
+	if fileExists(filepath.Join(repository.directory, ".git")) {
+		return nil
+	}
+	slog.Info("Cloning sync repository", "remote", remote, "path", repository.directory)
+	if err := os.MkdirAll(repository.directory, 0755); err != nil {
+		return err
+	}
+	if _, err := repository.git("clone", "--quiet", remote, "."); err != nil {
+		return err
+	}
+	if _, err := repository.git("config", "user.email"); err != nil {
+		hostname, _ := os.Hostname()
+		if _, err := repository.git("config", "user.name", "gem2"); err != nil {
+			return err
+		}
+		if _, err := repository.git("config", "user.email", "gem2@"+hostname); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Nil when the revision or the file does not exist, e.g. before the first sync
+func (repository syncRepository) committedFile(revision string, name string) []byte {
+	data, err := repository.git("show", revision+":"+name)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+type syncedFiles struct {
+	statistics  []byte
+	history     []byte
+	preferences []byte
+}
+
+func (repository syncRepository) committedFiles(revision string) syncedFiles {
+	return syncedFiles{
+		statistics:  repository.committedFile(revision, syncedStatistics),
+		history:     repository.committedFile(revision, syncedHistory),
+		preferences: repository.committedFile(revision, syncedPreferences),
+	}
+}
+
+// Day totals grown here since base are added on top of other
+func (history practiceHistory) rebase(base practiceHistory, other practiceHistory) {
+	for key, remote := range other.days {
+		local, since := history.days[key], base.days[key]
+		history.days[key] = dayRecord{
+			correct:    remote.correct + grownSince(local.correct, since.correct),
+			mistakes:   remote.mistakes + grownSince(local.mistakes, since.mistakes),
+			seconds:    remote.seconds + grownSince(local.seconds, since.seconds),
+			experience: remote.experience + grownSince(local.experience, since.experience),
+		}
+	}
+}
+
+func grownSince(local uint32, base uint32) uint32 {
+	return local - min(local, base)
+}
+
+func parseSyncedStatistics(data []byte) (stats.FileTOML, error) {
+	if data == nil {
+		return stats.FileTOML{}, nil
+	}
+	file, _, err := stats.Parse(data)
+	return file, err
+}
+
+func parseSyncedHistory(data []byte) (practiceHistory, error) {
+	if data == nil {
+		return practiceHistory{map[string]dayRecord{}}, nil
+	}
+	return parseHistory(data)
+}
+
+// Local files get the changes of other devices pushed since base,
+// which is what this device last synced with. Preferences are not
+// merged, the ones changed here win over the ones changed elsewhere.
+func mergeSyncedFiles(base syncedFiles, remote syncedFiles) (stats.MergeReport, error) {
+	baseStatistics, err := parseSyncedStatistics(base.statistics)
+	if err != nil {
+		return stats.MergeReport{}, fmt.Errorf("statistics of the last sync: %w", err)
+	}
+	remoteStatistics, err := parseSyncedStatistics(remote.statistics)
+	if err != nil {
+		return stats.MergeReport{}, fmt.Errorf("statistics of the remote: %w", err)
+	}
+	baseHistory, err := parseSyncedHistory(base.history)
+	if err != nil {
+		return stats.MergeReport{}, fmt.Errorf("history of the last sync: %w", err)
+	}
+	remoteHistory, err := parseSyncedHistory(remote.history)
+	if err != nil {
+		return stats.MergeReport{}, fmt.Errorf("history of the remote: %w", err)
+	}
+
+	database := read_database()
+	statistics := database.loadStatistics()
+	report := statistics.Rebase(baseStatistics, remoteStatistics)
+	history := loadHistory()
+	history.rebase(baseHistory, remoteHistory)
+	if err := errors.Join(statistics.save(), history.save()); err != nil {
+		return report, err
+	}
+
+	if path := preferencesPath(); path != "" && remote.preferences != nil {
+		local, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) || (err == nil && bytes.Equal(local, base.preferences)) {
+			if err := writeFileAtomic(path, remote.preferences, 0); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// Files are read back from where they were just saved
+func (repository syncRepository) copyLocalFiles() error {
+	copies := map[string]string{
+		syncedStatistics: statisticsPath,
+		syncedHistory:    historyPath,
+	}
+	if path := preferencesPath(); path != "" {
+		copies[syncedPreferences] = path
+	}
+	for name, path := range copies {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(repository.directory, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reports whether the push went through, it is rejected
+// when another device pushed since the fetch
+func (repository syncRepository) syncOnce() (bool, error) {
+	base := repository.committedFiles(syncedRevision)
+	if _, err := repository.git("fetch", "--quiet", "origin"); err != nil {
+		return false, err
+	}
+	remote := repository.committedFiles(repository.remoteBranch())
+	remoteRevision, err := repository.git("rev-parse", "--verify", "--quiet", repository.remoteBranch())
+	if err == nil {
+		if _, err := repository.git("checkout", "--quiet", "-B", repository.branch, repository.remoteBranch()); err != nil {
+			return false, err
+		}
+		if _, err := repository.git("reset", "--quiet", "--hard", repository.remoteBranch()); err != nil {
+			return false, err
+		}
+	} else if _, err := repository.git("symbolic-ref", "HEAD", "refs/heads/"+repository.branch); err != nil {
+		// Nothing was pushed yet, the first commit starts the branch
+		return false, err
+	}
+
+	report, err := mergeSyncedFiles(base, remote)
+	if err != nil {
+		return false, err
+	}
+	// Local files have the fetched changes now, so should the push be
+	// rejected or fail, the next sync must only add what was pushed
+	// after them rather than all of it again
+	if remoteRevision != nil {
+		if _, err := repository.git("update-ref", syncedRevision, strings.TrimSpace(string(remoteRevision))); err != nil {
+			return false, err
+		}
+	}
+	if len(report.Conflicts) > 0 {
+		printMergeReport(report)
+	}
+	if err := repository.copyLocalFiles(); err != nil {
+		return false, err
+	}
+	if _, err := repository.git("add", "--all"); err != nil {
+		return false, err
+	}
+	if _, err := repository.git("diff", "--cached", "--quiet"); err == nil {
+		slog.Info("Nothing new to push")
+		return true, repository.markSynced()
+	}
+	hostname, _ := os.Hostname()
+	if _, err := repository.git("commit", "--quiet", "--message", "Progress from "+hostname); err != nil {
+		return false, err
+	}
+	if _, err := repository.git("push", "--quiet", "origin", "HEAD:"+repository.branch); err != nil {
+		slog.Warn("Push was rejected", "error", err)
+		return false, nil
+	}
+	return true, repository.markSynced()
+}
+
+// Nothing to mark when neither side has anything yet
+func (repository syncRepository) markSynced() error {
+	if _, err := repository.git("rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return nil
+	}
+	_, err := repository.git("update-ref", syncedRevision, "HEAD")
+	return err
+}
+
+// Each device keeps a clone of the remote, pulling merges what other
+// devices pushed into the local files, pushing shares the result
+func runSync(args []string) {
+	flags, options := newFlagSet("sync", "")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	options.apply()
+	if readOnly {
+		fmt.Fprintln(os.Stderr, "Syncing writes statistics, it can not be done in read-only mode")
+		exit(usageError)
+	}
+	settings, err := loadSyncSettings()
+	if err != nil {
+		logFatal("Failed to load sync settings", "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(usageError)
+	}
+	requireInstanceLock("syncing")
+	repository := syncRepository{directory: syncDirectory(), branch: settings.Branch}
+	if err := cloneSyncRepository(settings.Remote, repository); err != nil {
+		logFatal("Failed to clone sync repository", "remote", settings.Remote, "error", err)
+		fmt.Fprintln(os.Stderr, err)
+		exit(syncError)
+	}
+	for attempt := 1; attempt <= syncAttempts; attempt++ {
+		pushed, err := repository.syncOnce()
+		if err != nil {
+			logFatal("Failed to sync", "error", err)
+			fmt.Fprintln(os.Stderr, err)
+			exit(syncError)
+		}
+		if pushed {
+			slog.Info("Synced", "remote", settings.Remote)
+			fmt.Println("Progress is in sync with", settings.Remote)
+			return
+		}
+	}
+	logFatal("Another device kept pushing, giving up", "attempts", syncAttempts)
+	fmt.Fprintln(os.Stderr, "Another device kept pushing at the same time, try again later")
+	exit(syncError)
+}
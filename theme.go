@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Colors are anything lipgloss.Color accepts: an ANSI
+// number or a hex code. Empty background keeps the
+// background of the terminal. Light is the palette used
+// on terminals with a light background, if there is one.
+type theme struct {
+	Background string
+	Text       string
+	Highlight  string
+	Muted      string
+	Accent     string
+	Wrong      string
+	Record     string
+	Heatmap    []string
+	Light      *theme
+}
+
+const defaultThemeName = "default"
+
+var builtinThemes = map[string]theme{
+	defaultThemeName: {
+		// Not using ANSI here since first 16 ones could be redefined
+		Background: "#000000",
+		Text:       "65",
+		Highlight:  "157",
+		Muted:      "101",
+		Accent:     "95",
+		Wrong:      "217",
+		Record:     "208",
+		Heatmap:    []string{"22", "28", "34", "40"},
+		Light: &theme{
+			Background: "#ffffff",
+			Text:       "65",
+			Highlight:  "22",
+			Muted:      "137",
+			Accent:     "95",
+			Wrong:      "160",
+			Record:     "166",
+			Heatmap:    []string{"151", "114", "71", "28"},
+		},
+	},
+	"transparent": {
+		Text:      "65",
+		Highlight: "157",
+		Muted:     "101",
+		Accent:    "95",
+		Wrong:     "217",
+		Record:    "208",
+		Heatmap:   []string{"22", "28", "34", "40"},
+		Light: &theme{
+			Text:      "65",
+			Highlight: "22",
+			Muted:     "137",
+			Accent:    "95",
+			Wrong:     "160",
+			Record:    "166",
+			Heatmap:   []string{"151", "114", "71", "28"},
+		},
+	},
+	"ember": {
+		Background: "#1c1c1c",
+		Text:       "180",
+		Highlight:  "223",
+		Muted:      "138",
+		Accent:     "167",
+		Wrong:      "203",
+		Record:     "214",
+		Heatmap:    []string{"94", "130", "166", "208"},
+		Light: &theme{
+			Background: "#fff8e7",
+			Text:       "94",
+			Highlight:  "52",
+			Muted:      "137",
+			Accent:     "124",
+			Wrong:      "160",
+			Record:     "166",
+			Heatmap:    []string{"223", "216", "209", "166"},
+		},
+	},
+}
+
+var (
+	themes           = builtinThemes
+	currentThemeName = defaultThemeName
+	// Detected once at startup, as asking the
+	// terminal is not possible while the UI runs
+	darkBackground = true
+)
+
+func themesDirectory() string {
+	return filepath.Join(configDirectory(), "themes")
+}
+
+// Missing colors are taken from the default theme, so a palette
+// file may only change a few of them. Missing colors of the light
+// palette are taken from the rest of the file, except for the
+// background, so that it is never dark under light colors.
+func parseTheme(bytes []byte) (theme, error) {
+	parsed := builtinThemes[defaultThemeName]
+	parsed.Heatmap = nil
+	parsed.Light = nil
+	if err := toml.Unmarshal(bytes, &parsed); err != nil {
+		return theme{}, err
+	}
+	if parsed.Heatmap == nil {
+		parsed.Heatmap = builtinThemes[defaultThemeName].Heatmap
+	}
+	if parsed.Light != nil {
+		light := parsed
+		light.Background = ""
+		light.Light = nil
+		// Decoding into an existing value keeps what the table leaves out
+		withLight := struct{ Light *theme }{Light: &light}
+		if err := toml.Unmarshal(bytes, &withLight); err != nil {
+			return theme{}, err
+		}
+		parsed.Light = &light
+	}
+	if len(parsed.Heatmap) == 0 || (parsed.Light != nil && len(parsed.Light.Heatmap) == 0) {
+		return theme{}, errors.New("heatmap needs at least one color")
+	}
+	return parsed, nil
+}
+
+// Palette for the background of the terminal,
+// themes without a light one look the same on both
+func (theme theme) forBackground(dark bool) theme {
+	if dark || theme.Light == nil {
+		return theme
+	}
+	return *theme.Light
+}
+
+// User palettes are named after their files and
+// may replace built-in themes of the same name
+func loadUserThemes() {
+	themes = make(map[string]theme, len(builtinThemes))
+	for name, builtin := range builtinThemes {
+		themes[name] = builtin
+	}
+	directory := themesDirectory()
+	paths, err := filepath.Glob(filepath.Join(directory, "*.toml"))
+	if err != nil || len(paths) == 0 {
+		return
+	}
+	for _, path := range paths {
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				slog.Error("Failed to read theme", "path", path, "error", err)
+			}
+			continue
+		}
+		parsed, err := parseTheme(bytes)
+		if err != nil {
+			slog.Error("Failed to parse theme", "path", path, "error", err)
+			continue
+		}
+		themes[strings.TrimSuffix(filepath.Base(path), ".toml")] = parsed
+	}
+}
+
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func colorOrNone(color string) lipgloss.TerminalColor {
+	if color == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(color)
+}
+
+func applyTheme(theme theme) {
+	theme = theme.forBackground(darkBackground)
+	backgroundColor = colorOrNone(theme.Background)
+	textColor = colorOrNone(theme.Text)
+	highlightColor = colorOrNone(theme.Highlight)
+	mutedColor = colorOrNone(theme.Muted)
+	accentColor = colorOrNone(theme.Accent)
+	wrongColor = colorOrNone(theme.Wrong)
+	recordColor = colorOrNone(theme.Record)
+	heatmapColors = make([]lipgloss.TerminalColor, len(theme.Heatmap))
+	for i, color := range theme.Heatmap {
+		heatmapColors[i] = colorOrNone(color)
+	}
+	buildStyles()
+}
+
+func useTheme(name string) error {
+	theme, exists := themes[name]
+	if !exists {
+		return fmt.Errorf("unknown theme %q, available: %s", name, strings.Join(themeNames(), ", "))
+	}
+	currentThemeName = name
+	applyTheme(theme)
+	slog.Debug("Using theme", "theme", name)
+	return nil
+}
+
+// Cycles through themes in alphabetical order
+func useNextTheme() {
+	names := themeNames()
+	next := names[0]
+	for i, name := range names {
+		if name == currentThemeName && i+1 < len(names) {
+			next = names[i+1]
+		}
+	}
+	useTheme(next)
+}
+
+func init() {
+	applyTheme(builtinThemes[defaultThemeName])
+}
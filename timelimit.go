@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Questions not answered within it are marked wrong, 0 means no limit
+var timeLimit time.Duration
+
+// Offered by the settings screen, any other limit can be given as a flag
+var timeLimitChoices = [...]time.Duration{0, 10 * time.Second, 20 * time.Second, 30 * time.Second, time.Minute}
+
+// Question it is for is told apart by when it was shown,
+// ticks for questions answered meanwhile are dropped
+type questionTimerMessage struct {
+	shown time.Duration
+}
+
+// Ticks every second for the countdown, and once more when time is up
+func (screen quizScreen) questionTimer() tea.Cmd {
+	if timeLimit == 0 {
+		return nil
+	}
+	shown := screen.questionShown
+	next := min(time.Second, screen.timeLeft())
+	if next == 0 {
+		// Ran out under another screen, checked again once in a while
+		next = time.Second
+	}
+	return tea.Tick(next, func(time.Time) tea.Msg { return questionTimerMessage{shown} })
+}
+
+func formatTimeLimit() string {
+	if timeLimit == 0 {
+		return "off"
+	}
+	return timeLimit.String()
+}
+
+func cycleTimeLimit() {
+	for i, choice := range timeLimitChoices {
+		if choice == timeLimit {
+			timeLimit = timeLimitChoices[(i+1)%len(timeLimitChoices)]
+			return
+		}
+	}
+	timeLimit = 0
+}
+
+func (screen quizScreen) timeLeft() time.Duration {
+	return max(timeLimit-screen.questionTime(), 0)
+}
+
+// Time keeps running while another screen is open on top of the quiz,
+// so opening the menu does not stop the clock, unless that screen
+// stops the session clock
+func (m model) updateQuestionTimer(msg questionTimerMessage) (model, tea.Cmd) {
+	if quiz, isQuiz := m.top().(quizScreen); isQuiz {
+		screen, cmd := quiz.Update(msg)
+		m.setTop(screen)
+		return m, cmd
+	}
+	for _, screen := range m.screens {
+		if quiz, isQuiz := screen.(quizScreen); isQuiz && quiz.isTimed(msg) {
+			return m, quiz.questionTimer()
+		}
+	}
+	return m, nil
+}
+
+func (screen quizScreen) isTimed(msg questionTimerMessage) bool {
+	return timeLimit > 0 && screen.mode == input && screen.questionShown == msg.shown
+}
+
+func (screen quizScreen) timerUpdate(msg questionTimerMessage) (tea.Model, tea.Cmd) {
+	if !screen.isTimed(msg) {
+		return screen, nil
+	}
+	if screen.timeLeft() > 0 {
+		return screen, screen.questionTimer()
+	}
+	screen.timedOut = true
+	return screen.answer()
+}
+
+func (screen quizScreen) renderTimeLeft() string {
+	if timeLimit == 0 || screen.mode != input {
+		return ""
+	}
+	return fmt.Sprintf(", %ds left", int(screen.timeLeft().Round(time.Second).Seconds()))
+}
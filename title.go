@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Keeps the score visible in the tab or
+// window list while the pane is not focused
+func windowTitle(correct uint32, wrong uint32) string {
+	title := "gem2: " + filepath.Base(wordDatabasePath)
+	if correct+wrong == 0 {
+		return title
+	}
+	return fmt.Sprintf("%s, %d/%d correct", title, correct, correct+wrong)
+}
+
+func (screen quizScreen) updateWindowTitle() tea.Cmd {
+	return tea.SetWindowTitle(windowTitle(screen.correctAnswers, screen.wrongAnswers))
+}
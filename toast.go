@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const toastDuration = 2 * time.Second
+
+// Short notice of something done in the background, shown in
+// place of the left side of the status bar until it expires
+var (
+	toastText string
+	// A newer toast replaces the shown one and outlives its timer
+	toastCount int
+	// Latest toast which already has its timer running
+	toastTimed int
+)
+
+type toastExpiredMessage struct {
+	toast int
+}
+
+// Callable from anywhere, the timer is started after the update
+func notify(text string) {
+	toastCount++
+	toastText = text
+}
+
+func expireToastLater() tea.Cmd {
+	if toastTimed == toastCount {
+		return nil
+	}
+	toastTimed = toastCount
+	toast := toastCount
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastExpiredMessage{toast} })
+}
+
+func expireToast(msg toastExpiredMessage) {
+	if msg.toast == toastCount {
+		toastText = ""
+	}
+}
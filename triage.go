@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+
+	"github.com/kligunov-id/gem2/scheduler"
+)
+
+// Questions of a new deck shown with their answers one after another,
+// the ones already known skip straight to a mastered streak
+type triageScreen struct {
+	quiz *quizScreen
+	// Unstarted questions, in the order they would be introduced in
+	queue []prompt
+	// Whether each question before the current one was marked known
+	known []bool
+}
+
+func newTriageScreen(quiz *quizScreen) triageScreen {
+	order := introductionOrder
+	if order == nil {
+		order = quiz.statistics.Prompts()
+	}
+	var queue []prompt
+	for _, prompt := range order {
+		if !scheduler.IsStarted(quiz.statistics.Record(prompt)) {
+			queue = append(queue, prompt)
+		}
+	}
+	return triageScreen{quiz: quiz, queue: queue}
+}
+
+func (screen triageScreen) isDone() bool {
+	return len(screen.known) == len(screen.queue)
+}
+
+func (screen triageScreen) counts() (known int, learn int) {
+	for _, marked := range screen.known {
+		if marked {
+			known++
+		}
+	}
+	return known, len(screen.known) - known
+}
+
+// Slice is shared between copies of the screen,
+// so a new one is made rather than appended to
+func (screen triageScreen) mark(known bool) triageScreen {
+	prompt := screen.queue[len(screen.known)]
+	if known {
+		screen.statistics().MarkKnown(prompt, masteredStreak)
+	}
+	screen.known = append(screen.known[:len(screen.known):len(screen.known)], known)
+	screen.quiz.unsavedAnswers++
+	return screen
+}
+
+// Questions marked known are unstarted again, as every one shown was
+func (screen triageScreen) undo() triageScreen {
+	last := len(screen.known) - 1
+	if screen.known[last] {
+		screen.statistics().ResetStats(screen.queue[last])
+	}
+	screen.known = screen.known[:last]
+	screen.quiz.unsavedAnswers++
+	return screen
+}
+
+func (screen triageScreen) statistics() *statisticsDatabase {
+	return screen.quiz.statistics
+}
+
+// Menu and screens above it exit without saving,
+// so the marks are saved on the way out
+func (screen triageScreen) leave() (tea.Model, tea.Cmd) {
+	known, learn := screen.counts()
+	slog.Info("Triaged new questions", "known", known, "learn", learn)
+	if err := screen.quiz.saveStatistics(); err != nil {
+		return screen, replaceScreen(newSaveFailedScreen(screen.quiz.session, nil, err))
+	}
+	return screen, popScreen
+}
+
+func (screen triageScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen triageScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Menu):
+			return screen.leave()
+		case key.Matches(msg, keys.Submit) && screen.isDone():
+			return screen.leave()
+		case key.Matches(msg, keys.Back) && len(screen.known) > 0:
+			return screen.undo(), nil
+		case key.Matches(msg, keys.Known) && !screen.isDone():
+			return screen.mark(true), nil
+		case key.Matches(msg, keys.Learn) && !screen.isDone():
+			return screen.mark(false), nil
+		}
+	}
+	return screen, nil
+}
+
+var triageHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Known}, action: "know it"},
+	{bindings: []*key.Binding{&keys.Learn}, action: "learn it"},
+	{bindings: []*key.Binding{&keys.Back}, action: "undo"},
+	{bindings: []*key.Binding{&keys.Menu}, action: "done"},
+}
+
+var triageDoneHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Submit, &keys.Menu}, action: "done"},
+	{bindings: []*key.Binding{&keys.Back}, action: "undo"},
+}
+
+func (screen triageScreen) renderCard(prompt prompt) string {
+	return renderLabelledRows(
+		questionStyle.Width(questionBlockWidth()),
+		[]string{"Form Clue:", "Verb:", "Verb Form:"},
+		[]string{prompt.FormClue, prompt.Verb, screen.statistics().Answer(prompt)},
+	)
+}
+
+func (screen triageScreen) View() string {
+	known, learn := screen.counts()
+	tally := questionStatsAlignStyle.Render(questionStatsStyle.Render(
+		fmt.Sprintf("%d known%s%d to learn", known, symbols.helpSeparator, learn),
+	))
+	if screen.isDone() {
+		message := "every new question is triaged"
+		if len(screen.queue) == 0 {
+			message = "there are no new questions"
+		}
+		body := lipgloss.JoinVertical(
+			lipgloss.Left,
+			renderMenuTitle("Triage"),
+			"",
+			questionStatsAlignStyle.Render(questionStatsStyle.Render(message)),
+			tally,
+		)
+		return renderBox(body, renderHelpRow(triageDoneHelp[:]))
+	}
+	position := questionStatsStyle.Render(fmt.Sprintf("%d/%d", len(screen.known)+1, len(screen.queue)))
+	body := lipgloss.JoinVertical(
+		lipgloss.Left,
+		statsTitleStyle.Width(boxWidth-lipgloss.Width(position)).Render("Triage")+position,
+		"",
+		screen.renderCard(screen.queue[len(screen.known)]),
+		"",
+		tally,
+	)
+	return renderBox(body, renderHelpRow(triageHelp[:]))
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Keystrokes of one typed answer, or of several added up
+type typingStats struct {
+	answers int
+	// Letters of the submitted answers
+	characters int
+	keystrokes int
+	// Keystrokes deleting what was typed
+	corrections int
+	// Letters of the submitted answers differing from the correct ones
+	errors int
+	// From the first keystroke to submitting, reading the question is not typing
+	duration time.Duration
+}
+
+type typedAnswer struct {
+	prompt prompt
+	typingStats
+}
+
+// Answers compared at the start and the end of a session to tell fatigue
+const typingWindow = 10
+
+func (stats *typingStats) add(other typingStats) {
+	stats.answers += other.answers
+	stats.characters += other.characters
+	stats.keystrokes += other.keystrokes
+	stats.corrections += other.corrections
+	stats.errors += other.errors
+	stats.duration += other.duration
+}
+
+func (stats typingStats) perMinute() float64 {
+	if stats.duration <= 0 {
+		return 0
+	}
+	return float64(stats.characters) / stats.duration.Minutes()
+}
+
+func (stats typingStats) String() string {
+	return fmt.Sprintf(
+		"%s chars/min, %s letters wrong, %s keystrokes corrected",
+		bold(fmt.Sprintf("%.0f", stats.perMinute())),
+		bold(formatPercentage(uint64(stats.errors), uint64(stats.characters))),
+		bold(formatPercentage(uint64(stats.corrections), uint64(stats.keystrokes))),
+	)
+}
+
+func sumTyping(answers []typedAnswer) typingStats {
+	var total typingStats
+	for _, answer := range answers {
+		total.add(answer.typingStats)
+	}
+	return total
+}
+
+// Cursor movement and the like are not typing
+func (screen *quizScreen) countKeystroke(msg tea.KeyMsg) {
+	fieldKeys := screen.inputField.KeyMap
+	switch {
+	case msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace:
+	case key.Matches(
+		msg,
+		fieldKeys.DeleteCharacterBackward,
+		fieldKeys.DeleteCharacterForward,
+		fieldKeys.DeleteWordBackward,
+		fieldKeys.DeleteWordForward,
+		fieldKeys.DeleteBeforeCursor,
+		fieldKeys.DeleteAfterCursor,
+	):
+		screen.typing.corrections++
+	default:
+		return
+	}
+	if screen.typing.keystrokes == 0 {
+		screen.typingStarted = screen.clock.elapsed()
+	}
+	screen.typing.keystrokes++
+}
+
+// Answers chosen from multiple choice, or not typed at all, are left out
+func (screen *quizScreen) recordTyping() {
+	if screen.typing.keystrokes == 0 {
+		return
+	}
+	typed := screen.inputField.Value()
+	answer := typedAnswer{screen.question.prompt, screen.typing}
+	answer.answers = 1
+	answer.characters = utf8.RuneCountInString(typed)
+	answer.errors = editDistance(typed, screen.question.correctAnswer)
+	answer.duration = screen.clock.elapsed() - screen.typingStarted
+	screen.typedAnswers = append(screen.typedAnswers, answer)
+}
+
+// How the session went so far, opened from the menu
+type sessionSummaryScreen struct {
+	session *session
+}
+
+func (screen sessionSummaryScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen sessionSummaryScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Back) {
+			return screen, popScreen
+		}
+	}
+	return screen, nil
+}
+
+var sessionSummaryHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+// Slowest typed questions first, each question once
+func (session *session) typingByPrompt() []typedAnswer {
+	var byPrompt []typedAnswer
+	index := map[prompt]int{}
+	for _, answer := range session.typedAnswers {
+		i, seen := index[answer.prompt]
+		if !seen {
+			i = len(byPrompt)
+			index[answer.prompt] = i
+			byPrompt = append(byPrompt, typedAnswer{prompt: answer.prompt})
+		}
+		byPrompt[i].add(answer.typingStats)
+	}
+	slices.SortStableFunc(byPrompt, func(a typedAnswer, b typedAnswer) int {
+		return cmp.Compare(a.perMinute(), b.perMinute())
+	})
+	return byPrompt
+}
+
+func (screen sessionSummaryScreen) renderTyping() []string {
+	typed := screen.session.typedAnswers
+	if len(typed) == 0 {
+		return []string{"Typing: no answers typed yet"}
+	}
+	lines := []string{"Typing: " + sumTyping(typed).String()}
+	if len(typed) < 2*typingWindow {
+		return lines
+	}
+	first := sumTyping(typed[:typingWindow])
+	last := sumTyping(typed[len(typed)-typingWindow:])
+	lines = append(
+		lines,
+		fmt.Sprintf("First %d: %s", typingWindow, first),
+		fmt.Sprintf("Last %d: %s", typingWindow, last),
+	)
+	// A fifth slower or twice the errors is more than a bad question
+	if last.perMinute() < 0.8*first.perMinute() || last.errors > 2*max(first.errors, 1) {
+		lines = append(lines, "Slowing down, maybe time for a break")
+	}
+	return lines
+}
+
+func (screen sessionSummaryScreen) View() string {
+	session := screen.session
+	textStyle := background.Foreground(textColor).Width(boxWidth)
+	lines := []string{statsTitleStyle.Render("Session summary"), ""}
+	answered := session.correctAnswers + session.wrongAnswers
+	summary := []string{fmt.Sprintf(
+		"Answers: %s, %s correct, in %s",
+		bold(fmt.Sprint(answered)),
+		bold(formatPercentage(uint64(session.correctAnswers), uint64(answered))),
+		bold(formatStudyTime(session.clock.elapsed())),
+	)}
+	for _, line := range append(summary, screen.renderTyping()...) {
+		lines = append(lines, textStyle.Render(line))
+	}
+	lines = append(lines, "")
+	for _, answer := range session.typingByPrompt() {
+		if len(lines) == listShownRows()+2 {
+			break
+		}
+		detail := questionStatsStyle.Render(fmt.Sprintf(
+			"%.0f/min, %s wrong",
+			answer.perMinute(),
+			formatPercentage(uint64(answer.errors), uint64(answer.characters)),
+		))
+		lines = append(lines, promptStatsEntryStyle.Width(boxWidth-lipgloss.Width(detail)).Render(answer.prompt.String())+detail)
+	}
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), renderHelpRow(sessionSummaryHelp[:]))
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+
+	"github.com/kligunov-id/gem2/scheduler"
+)
+
+// Question the quiz is going to ask, or may ask, and why
+type upcomingQuestion struct {
+	prompt prompt
+	reason string
+}
+
+// Questions in the order nextQuestion looks for them, the weighted
+// pick can only be told by how likely each question is to come up
+func (screen quizScreen) upcoming(count int) []upcomingQuestion {
+	var questions []upcomingQuestion
+	add := func(prompt prompt, reason string) {
+		if len(questions) < count {
+			questions = append(questions, upcomingQuestion{prompt, reason})
+		}
+	}
+	for _, prompt := range screen.replayQueue {
+		add(prompt, "replay")
+	}
+	for _, prompt := range screen.pinnedQueue {
+		add(prompt, "pinned")
+	}
+	for _, card := range screen.againPile {
+		if wait := int(againGap) - int(screen.answers()-card.missedAt); wait > 0 {
+			add(card.prompt, fmt.Sprintf("again in %d", wait))
+		} else {
+			add(card.prompt, "again")
+		}
+	}
+	for _, prompt := range screen.warmUpQueue {
+		add(prompt, "warm-up")
+	}
+	if nextQuestionOrder == orderLadder {
+		for _, prompt := range screen.ladder {
+			add(prompt, "ladder")
+		}
+	}
+	now := time.Now()
+	if nextQuestionOrder == orderDueFirst {
+		for _, prompt := range scheduler.Overdue(screen.statistics.Database, now, count) {
+			add(prompt, "due "+formatTimeAgo(scheduler.DueAt(screen.statistics.Record(prompt)), now))
+		}
+	}
+	if prompt, found := screen.statistics.nextIntroduction(); found {
+		// Any new question picked becomes this one
+		var chance float32
+		for _, record := range screen.statistics.All() {
+			if !scheduler.IsStarted(record) {
+				chance += scheduler.Chance(screen.statistics.Database, record)
+			}
+		}
+		add(prompt, fmt.Sprintf("new, %.1f%% chance", 100*chance))
+	}
+	for _, prompt := range scheduler.ByChance(screen.statistics.Database) {
+		if len(questions) == count {
+			break
+		}
+		record := screen.statistics.Record(prompt)
+		// Picks of new questions go to the next one of the frequency list
+		if record.Suspended || introductionOrder != nil && !scheduler.IsStarted(record) {
+			continue
+		}
+		add(prompt, fmt.Sprintf("%.1f%% chance", 100*scheduler.Chance(screen.statistics.Database, record)))
+	}
+	return questions
+}
+
+// Answers stay hidden, the quiz is about to ask these
+type upNextScreen struct {
+	questions []upcomingQuestion
+}
+
+func newUpNextScreen(quiz quizScreen) upNextScreen {
+	return upNextScreen{quiz.upcoming(listShownRows())}
+}
+
+func (screen upNextScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (screen upNextScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Queue, keys.Back) {
+			return screen, popScreen
+		}
+	}
+	return screen, nil
+}
+
+var upNextHelp = [...]helpEntry{
+	{bindings: []*key.Binding{&keys.Queue, &keys.Back}, action: "back"},
+	{bindings: []*key.Binding{&keys.Quit}, action: "exit"},
+}
+
+func (screen upNextScreen) View() string {
+	lines := []string{renderMenuTitle("Up next"), ""}
+	for _, question := range screen.questions {
+		reason := questionStatsStyle.Render(question.reason)
+		lines = append(lines, promptStatsEntryStyle.Width(boxWidth-lipgloss.Width(reason)).Render(question.prompt.String())+reason)
+	}
+	return renderBox(lipgloss.JoinVertical(lipgloss.Left, lines...), renderHelpRow(upNextHelp[:]))
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/kligunov-id/gem2/deck"
+	"github.com/kligunov-id/gem2/stats"
+)
+
+// Problems make validation fail, while warnings
+// are about data the program copes with on its own
+type validationReport struct {
+	problems []string
+	warnings []string
+}
+
+func (report *validationReport) problem(format string, args ...any) {
+	report.problems = append(report.problems, fmt.Sprintf(format, args...))
+}
+
+func (report *validationReport) warning(format string, args ...any) {
+	report.warnings = append(report.warnings, fmt.Sprintf(format, args...))
+}
+
+func (report *validationReport) checkDatabase(database wordDatabase) {
+	seenVerbs := make(map[string]bool)
+	for _, verb := range database.Verbs {
+		if verb == "" {
+			report.problem("%s has a row without a verb", wordDatabasePath)
+			continue
+		}
+		if seenVerbs[verb] {
+			report.problem("%s lists verb %q more than once", wordDatabasePath, verb)
+		}
+		seenVerbs[verb] = true
+	}
+	seenClues := make(map[string]bool)
+	for _, clue := range database.FormClues {
+		if seenClues[clue] {
+			report.problem("%s has more than one %q column", wordDatabasePath, clue)
+		}
+		seenClues[clue] = true
+	}
+	missing := 0
+	for verbIndex := range database.Verbs {
+		for clueIndex := range database.FormClues {
+			forms := database.Forms[verbIndex]
+			if len(forms) <= clueIndex || forms[clueIndex] == "" {
+				missing++
+			}
+		}
+	}
+	if missing > 0 {
+		report.warning("%s has %d empty forms, they are not asked", wordDatabasePath, missing)
+	}
+	report.checkMedia("audio", database.mediaFiles(database.Clip))
+	report.checkMedia("image", database.mediaFiles(database.Image))
+}
+
+// URLs are not fetched, only local files are checked
+func (report *validationReport) checkDeckSettings(settings deck.Settings) {
+	if settings.AnswerChecking != "" {
+		var strictness answerStrictness
+		if err := strictness.Set(settings.AnswerChecking); err != nil {
+			report.problem("%s declares answer checking: %v", wordDatabasePath, err)
+		}
+	}
+	language := strings.ToLower(settings.Language)
+	if _, known := collationLanguages[language]; language != "" && !known {
+		report.warning("%s declares language %q, which has no sorting, accents or frequency list", wordDatabasePath, settings.Language)
+	}
+}
+
+func (report *validationReport) checkMedia(kind string, files map[prompt]string) {
+	checked := make(map[string]bool)
+	missing := 0
+	for _, file := range files {
+		if checked[file] || !isLocalFile(file) {
+			continue
+		}
+		checked[file] = true
+		if _, err := os.Stat(file); err != nil {
+			missing++
+		}
+	}
+	if missing > 0 {
+		report.warning("%s refers to %d %s files which can not be read", wordDatabasePath, missing, kind)
+	}
+}
+
+// Reading is done directly rather than through loadStatistics,
+// which would fall back to backups and hide the broken file
+func (report *validationReport) checkStatistics(database wordDatabase) {
+	bytes, err := os.ReadFile(statisticsPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		report.problem("%s can not be read: %v", statisticsPath, err)
+		return
+	}
+	statisticsTOML, version, err := stats.Parse(bytes)
+	if err != nil {
+		report.problem("%s can not be parsed: %v", statisticsPath, err)
+		return
+	}
+	if version < stats.Version {
+		report.warning("%s is version %d, it is migrated to %d on next start", statisticsPath, version, stats.Version)
+	}
+	statistics := database.emptyStatistics()
+	statistics.Expand(statisticsTOML)
+	if len(statistics.DeadRecords) > 0 {
+		report.warning("%s has %d records for questions missing from %s", statisticsPath, len(statistics.DeadRecords), wordDatabasePath)
+	}
+	if len(statistics.ChangedAnswers) > 0 {
+		report.warning("%s has %d records for questions with changed answers", statisticsPath, len(statistics.ChangedAnswers))
+	}
+}
+
+func (report *validationReport) checkFile(path string, parse func([]byte) error) {
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		report.problem("%s can not be read: %v", path, err)
+		return
+	}
+	if err := parse(bytes); err != nil {
+		report.problem("%s can not be parsed: %v", path, err)
+	}
+}
+
+func runValidate(args []string) {
+	flags, options := newFlagSet("validate", "")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	// Validation never writes, not even migration backups
+	options.readsOnly = true
+	options.apply()
+	var report validationReport
+	database := read_database()
+	report.checkDatabase(database)
+	report.checkDeckSettings(database.Settings)
+	report.checkStatistics(database)
+	report.checkFile(mistakesPath, func(bytes []byte) error {
+		_, err := parseMistakes(bytes)
+		return err
+	})
+	report.checkFile(notesPath, func(bytes []byte) error {
+		_, err := parseNotes(bytes)
+		return err
+	})
+	report.checkFile(achievementsPath, func(bytes []byte) error {
+		_, err := parseAchievements(bytes)
+		return err
+	})
+	report.checkFile(historyPath, func(bytes []byte) error {
+		_, err := parseHistory(bytes)
+		return err
+	})
+	report.checkFile(hooksPath(), func(bytes []byte) error {
+		_, err := parseHooks(bytes)
+		return err
+	})
+	report.checkFile(leaderboardPath(), func(bytes []byte) error {
+		_, err := parseLeaderboard(bytes)
+		return err
+	})
+	report.checkFile(syncSettingsPath(), func(bytes []byte) error {
+		_, err := parseSyncSettings(bytes)
+		return err
+	})
+	report.checkFile(habitsPath(), func(bytes []byte) error {
+		_, err := parseHabits(bytes)
+		return err
+	})
+	report.checkFile(backupSettingsPath(), func(bytes []byte) error {
+		_, err := parseBackupSettings(bytes)
+		return err
+	})
+	report.checkFile(keyMapPath(), func(bytes []byte) error {
+		_, err := parseKeyMap(bytes)
+		return err
+	})
+	for _, warning := range report.warnings {
+		fmt.Printf("warning: %s\n", warning)
+	}
+	for _, problem := range report.problems {
+		fmt.Printf("problem: %s\n", problem)
+	}
+	if len(report.problems) > 0 {
+		exit(validationError)
+	}
+	fmt.Println("Everything is fine")
+}
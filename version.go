@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// Set by release builds with
+// -ldflags "-X main.version=v1.0.0 -X main.commit=... -X main.buildDate=..."
+var (
+	version   = ""
+	commit    = ""
+	buildDate = ""
+)
+
+type buildInfo struct {
+	version  string
+	commit   string
+	date     string
+	modified bool
+}
+
+// Values missing from ldflags are taken from what the go
+// tool records itself, which covers go install and go build
+func currentBuild() buildInfo {
+	build := buildInfo{version: version, commit: commit, date: buildDate}
+	info, available := debug.ReadBuildInfo()
+	if !available {
+		return build
+	}
+	if build.version == "" && info.Main.Version != "(devel)" {
+		build.version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if build.commit == "" {
+				build.commit = setting.Value
+			}
+		case "vcs.time":
+			if build.date == "" {
+				build.date = setting.Value
+			}
+		case "vcs.modified":
+			build.modified = setting.Value == "true"
+		}
+	}
+	return build
+}
+
+// Pseudo-versions of untagged commits say nothing
+// the commit does not, and are too long for the menu
+func (build buildInfo) shortVersion() string {
+	if build.version == "" || strings.HasPrefix(build.version, "v0.0.0-") {
+		return "dev"
+	}
+	return build.version
+}
+
+func (build buildInfo) String() string {
+	description := "gem2 " + build.shortVersion()
+	if build.commit != "" {
+		description += fmt.Sprintf(", commit %.12s", build.commit)
+		if build.modified {
+			description += " with local changes"
+		}
+	}
+	if build.date != "" {
+		description += ", built " + build.date
+	}
+	return description
+}
+
+func runVersion(args []string) {
+	flags, _ := newFlagSet("version", "")
+	parseFlags(flags, args)
+	expectArguments(flags, 0)
+	fmt.Println(currentBuild())
+}
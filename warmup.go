@@ -0,0 +1,41 @@
+package main
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+)
+
+// Number of easy questions a session starts with, 0 starts with the usual ones
+var warmUpLength = 2
+
+// Mastered questions are all equally easy and come in random order,
+// the longest streaks below that make up for too few of them
+func (statistics statisticsDatabase) warmUpPrompts(count int) []prompt {
+	var known []prompt
+	for prompt, record := range statistics.All() {
+		if record.Streak > 0 && !record.Suspended {
+			known = append(known, prompt)
+		}
+	}
+	rand.Shuffle(len(known), func(i, j int) {
+		known[i], known[j] = known[j], known[i]
+	})
+	easiness := func(prompt prompt) uint32 {
+		return min(statistics.Record(prompt).Streak, masteredStreak)
+	}
+	slices.SortStableFunc(known, func(a prompt, b prompt) int {
+		return cmp.Compare(easiness(b), easiness(a))
+	})
+	return known[:min(count, len(known))]
+}
+
+// Replaces the first question, so the session opens
+// with the questions pinned for it and the warm-up
+func (screen *quizScreen) startWarmUp() {
+	screen.pinnedQueue = screen.statistics.pinnedPrompts()
+	screen.warmUpQueue = screen.statistics.warmUpPrompts(warmUpLength)
+	if len(screen.pinnedQueue) > 0 || len(screen.warmUpQueue) > 0 {
+		screen.nextQuestion()
+	}
+}
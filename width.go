@@ -0,0 +1,86 @@
+package main
+
+import (
+	"unicode/utf8"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Everything is measured in terminal cells rather than runes,
+// as CJK characters take two of them and combining marks none
+
+// Cells left for the question next to the widest prompt label
+func questionBlockWidth() int {
+	return boxWidth - lipgloss.Width(promptStyle.Render("Verb Form: "))
+}
+
+// Cursor takes one more cell after the text
+func inputFieldWidth() int {
+	return questionBlockWidth() - 1
+}
+
+// Letters an answer can have, the field scrolls once it is wider than the box
+var answerLimit = 80
+
+// Answers of the deck longer than the limit can still be typed
+func (question question) charLimit() int {
+	return max(answerLimit, utf8.RuneCountInString(question.correctAnswer))
+}
+
+// Labels stay next to the first line of their values,
+// which wrap within the question block when too long
+func renderLabelledRows(valueStyle lipgloss.Style, labels []string, values []string) string {
+	rows := make([]string, len(labels))
+	for i, label := range labels {
+		value := valueStyle.Render(values[i])
+		label = promptStyle.
+			Width(boxWidth - questionBlockWidth()).
+			Height(lipgloss.Height(value)).
+			PaddingRight(1).
+			AlignHorizontal(lipgloss.Right).
+			Render(label)
+		rows[i] = lipgloss.JoinHorizontal(lipgloss.Top, label, value)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// Accents typed as separate combining marks are put onto their letters,
+// so that the cursor never stops between a letter and its accent and
+// the answer matches the deck whichever way either of them is encoded
+func (screen *quizScreen) composeCombiningMarks() {
+	value := screen.inputField.Value()
+	if norm.NFC.IsNormalString(value) {
+		return
+	}
+	beforeCursor := []rune(value)[:screen.inputField.Position()]
+	screen.inputField.SetValue(norm.NFC.String(value))
+	screen.inputField.SetCursor(len([]rune(norm.NFC.String(string(beforeCursor)))))
+}
+
+func sameText(a string, b string) bool {
+	return norm.NFC.String(a) == norm.NFC.String(b)
+}
+
+// Range of characters around the selected one which fit into
+// the width with a cell between each two, grown on both sides
+func characterWindow(characters []rune, selected int, width int) (first int, end int) {
+	// Counting the gap after every character, the last one included
+	cells := func(character rune) int { return lipgloss.Width(string(character)) + 1 }
+	first, end = selected, selected+1
+	used := cells(characters[selected])
+	for grown := true; grown; {
+		grown = false
+		if end < len(characters) && used+cells(characters[end]) <= width+1 {
+			used += cells(characters[end])
+			end++
+			grown = true
+		}
+		if first > 0 && used+cells(characters[first-1]) <= width+1 {
+			first--
+			used += cells(characters[first])
+			grown = true
+		}
+	}
+	return first, end
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+	"github.com/kligunov-id/gem2/scheduler"
+)
+
+type worksheetFormat int
+
+const (
+	textWorksheet worksheetFormat = iota
+	markdownWorksheet
+	pdfWorksheet
+)
+
+const (
+	defaultWorksheetQuestions = 20
+	// Room to write the answer in on paper
+	worksheetBlank = "____________________"
+)
+
+func worksheetFormatFromPath(path string) (worksheetFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt":
+		return textWorksheet, nil
+	case ".md", ".markdown":
+		return markdownWorksheet, nil
+	case ".pdf":
+		return pdfWorksheet, nil
+	}
+	return 0, fmt.Errorf("unknown worksheet format of %q, use .txt, .md or .pdf", path)
+}
+
+// Next to the worksheet, so it can be printed separately: quiz.pdf gets quiz-key.pdf
+func answerKeyPath(path string) string {
+	extension := filepath.Ext(path)
+	return strings.TrimSuffix(path, extension) + "-key" + extension
+}
+
+// Last answered wrong first, then the most often missed and the least
+// known ones, questions never answered have no weakness to show
+func compareWeakness(a questionStats, b questionStats) int {
+	compareBool := func(a bool, b bool) int {
+		switch {
+		case a == b:
+			return 0
+		case a:
+			return -1
+		}
+		return 1
+	}
+	return cmp.Or(
+		compareBool(scheduler.IsStarted(a), scheduler.IsStarted(b)),
+		compareBool(scheduler.IsWeak(a), scheduler.IsWeak(b)),
+		cmp.Compare(b.Mistakes, a.Mistakes),
+		cmp.Compare(a.Streak, b.Streak),
+	)
+}
+
+// Shuffled first, so that equally weak questions come in random order too
+func selectWorksheetPrompts(statistics statisticsDatabase, count int, weakest bool) []prompt {
+	prompts := slices.Clone(statistics.Prompts())
+	rand.Shuffle(len(prompts), func(i, j int) {
+		prompts[i], prompts[j] = prompts[j], prompts[i]
+	})
+	if weakest {
+		slices.SortStableFunc(prompts, func(a prompt, b prompt) int {
+			return compareWeakness(statistics.Record(a), statistics.Record(b))
+		})
+	}
+	return prompts[:min(count, len(prompts))]
+}
+
+type worksheet struct {
+	title   string
+	prompts []prompt
+	answers []string
+}
+
+// Lines of either the questions with blanks or the answer key
+func (sheet worksheet) lines(key bool) []string {
+	lines := make([]string, len(sheet.prompts))
+	width := len(fmt.Sprint(len(sheet.prompts)))
+	for i, prompt := range sheet.prompts {
+		answer := worksheetBlank
+		if key {
+			answer = sheet.answers[i]
+		}
+		lines[i] = fmt.Sprintf("%*d. %s: %s", width, i+1, prompt, answer)
+	}
+	return lines
+}
+
+func (sheet worksheet) heading(key bool) string {
+	if key {
+		return "Answer key: " + sheet.title
+	}
+	return "Worksheet: " + sheet.title
+}
+
+func (sheet worksheet) renderText(key bool) []byte {
+	var text strings.Builder
+	heading := sheet.heading(key)
+	fmt.Fprintf(&text, "%s\n%s\n\n", heading, strings.Repeat("=", lipgloss.Width(heading)))
+	if !key {
+		fmt.Fprintf(&text, "Name: %s\n\n", worksheetBlank)
+	}
+	for _, line := range sheet.lines(key) {
+		fmt.Fprintln(&text, line)
+	}
+	return []byte(text.String())
+}
+
+// Blanks are escaped, they would be a horizontal rule or emphasis otherwise
+func (sheet worksheet) renderMarkdown(key bool) []byte {
+	var text strings.Builder
+	fmt.Fprintf(&text, "# %s\n\n", sheet.heading(key))
+	if !key {
+		fmt.Fprintf(&text, "Name: %s\n\n", strings.ReplaceAll(worksheetBlank, "_", `\_`))
+	}
+	for i, prompt := range sheet.prompts {
+		answer := strings.ReplaceAll(worksheetBlank, "_", `\_`)
+		if key {
+			answer = "**" + sheet.answers[i] + "**"
+		}
+		fmt.Fprintf(&text, "%d. %s: %s\n", i+1, prompt, answer)
+	}
+	return []byte(text.String())
+}
+
+func (sheet worksheet) render(format worksheetFormat, key bool) ([]byte, error) {
+	switch format {
+	case markdownWorksheet:
+		return sheet.renderMarkdown(key), nil
+	case pdfWorksheet:
+		var document bytes.Buffer
+		lines := sheet.lines(key)
+		if !key {
+			lines = append([]string{"Name: " + worksheetBlank, ""}, lines...)
+		}
+		if err := writePDF(&document, sheet.heading(key), lines); err != nil {
+			return nil, err
+		}
+		return document.Bytes(), nil
+	}
+	return sheet.renderText(key), nil
+}
+
+func writeWorksheet(path string, sheet worksheet) error {
+	format, err := worksheetFormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	for _, key := range []bool{false, true} {
+		data, err := sheet.render(format, key)
+		if err != nil {
+			return err
+		}
+		file := path
+		if key {
+			file = answerKeyPath(path)
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Questions to answer on paper, with the answers in a file of their
+// own. Nothing is recorded, the statistics only pick the weakest ones.
+func runWorksheet(args []string) {
+	flags, options := newFlagSet("worksheet", "file")
+	count := flags.Int("count", defaultWorksheetQuestions, "number of `questions` on the worksheet")
+	weakest := flags.Bool("weakest", false, "pick the questions answered worst so far instead of random ones")
+	parseFlags(flags, args)
+	expectArguments(flags, 1)
+	options.readsOnly = true
+	options.apply()
+	if *count <= 0 {
+		fmt.Fprintln(os.Stderr, "Count must be positive")
+		exit(usageError)
+	}
+	path := flags.Arg(0)
+	if _, err := worksheetFormatFromPath(path); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(usageError)
+	}
+	database := read_database()
+	statistics := database.loadStatistics()
+	prompts := selectWorksheetPrompts(statistics, *count, *weakest)
+	sheet := worksheet{
+		title:   fmt.Sprintf("%s, %s", filepath.Base(wordDatabasePath), time.Now().Format(historyDateLayout)),
+		prompts: prompts,
+		answers: make([]string, len(prompts)),
+	}
+	for i, prompt := range prompts {
+		sheet.answers[i] = statistics.Answer(prompt)
+	}
+	if err := writeWorksheet(path, sheet); err != nil {
+		logFatal("Failed to write worksheet", "error", err)
+		fmt.Fprintf(os.Stderr, "Failed to write worksheet: %v\n", err)
+		exit(exportError)
+	}
+	slog.Info("Wrote worksheet", "questions", len(prompts), "weakest", *weakest, "path", path)
+	fmt.Printf("Wrote %d questions to %s and the answers to %s\n", len(prompts), path, answerKeyPath(path))
+}
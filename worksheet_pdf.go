@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A4 in points, with the standard Helvetica every PDF reader has,
+// so nothing needs to be embedded
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 56
+	pdfHeadingSize  = 16
+	pdfFontSize     = 12
+	pdfLineSpacing  = 20
+	pdfHeadingSpace = 32
+)
+
+// Standard fonts only cover Windows-1252, other
+// characters are printed as question marks
+func pdfString(text string) string {
+	var escaped strings.Builder
+	escaped.WriteByte('(')
+	for _, character := range text {
+		encoded, ok := charmap.Windows1252.EncodeRune(character)
+		if !ok {
+			encoded = '?'
+		}
+		switch encoded {
+		case '(', ')', '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteByte(encoded)
+		default:
+			if encoded < 0x20 || encoded >= 0x7f {
+				fmt.Fprintf(&escaped, "\\%03o", encoded)
+			} else {
+				escaped.WriteByte(encoded)
+			}
+		}
+	}
+	escaped.WriteByte(')')
+	return escaped.String()
+}
+
+// Content streams of the pages, the heading on the first one only
+func pdfPages(heading string, lines []string) []string {
+	var pages []string
+	var page strings.Builder
+	y := pdfPageHeight - pdfMargin - pdfHeadingSize
+	fmt.Fprintf(&page, "BT /F2 %d Tf %d %d Td %s Tj ET\n", pdfHeadingSize, pdfMargin, y, pdfString(heading))
+	y -= pdfHeadingSpace
+	for _, line := range lines {
+		if y < pdfMargin {
+			pages = append(pages, page.String())
+			page.Reset()
+			y = pdfPageHeight - pdfMargin - pdfFontSize
+		}
+		if line != "" {
+			fmt.Fprintf(&page, "BT /F1 %d Tf %d %d Td %s Tj ET\n", pdfFontSize, pdfMargin, y, pdfString(line))
+		}
+		y -= pdfLineSpacing
+	}
+	return append(pages, page.String())
+}
+
+// Smallest document readers accept: catalog, page tree, two fonts, then
+// a page and its content stream for each page, and the offsets of them all
+func writePDF(w io.Writer, heading string, lines []string) error {
+	var document bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, document.Len())
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	pages := pdfPages(heading, lines)
+	const firstPage = 5
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	document.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, firstPage+2*i+1,
+		))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(document.Bytes())
+	return err
+}